@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/backup"
+	"github.com/lyleclassen/lite-llm/internal/docker"
+	"github.com/lyleclassen/lite-llm/internal/system"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up and restore models and chat data",
+	Long: `Back up the Ollama model store, Open WebUI's data and lite-llm's own files.
+
+Backups are kept in backup.dir (default ~/.lite-llm/backups) as compressed,
+checksummed archives with an index. Model blobs are stored once in a shared
+blob store there, so backups of the same models don't duplicate them.
+
+The model store and Open WebUI data are read from the stack's Docker volumes,
+which usually requires root; set ollama.models_dir and webui.data_dir to use
+other directories.`,
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a backup",
+	Long: `Create a backup of the selected components:
+
+  manifests  Ollama model manifests (models can be re-downloaded from them)
+  blobs      the model weights, so models restore without downloading
+  webui      Open WebUI's data: users, chats, settings and uploads
+  lite-llm   lite-llm's config, metrics history, usage records and stack project
+
+Open WebUI is paused while its data is copied.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBackupCreate()
+	},
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List backups",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBackupList()
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore [name|archive.tar.gz]",
+	Short: "Verify and restore a backup",
+	Long: `Restore a backup by name, or from an archive path (with its index and blob
+store next to it). Every file and blob is checked against the backup's
+checksums before anything is written.
+
+Open WebUI's data directory is replaced, and the container is stopped while it
+is. Models whose blobs are neither in the backup nor already installed are
+skipped and listed for re-download.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBackupRestore(args[0])
+	},
+}
+
+var (
+	backupDir         string
+	backupName        string
+	backupComponents  string
+	backupDedup       bool
+	restoreComponents string
+	restoreVerifyOnly bool
+)
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+
+	backupCmd.PersistentFlags().StringVar(&backupDir, "dir", "", "Backup directory (default: backup.dir from config)")
+	backupCreateCmd.Flags().StringVar(&backupName, "name", "", "Backup name (default: the current time)")
+	backupCreateCmd.Flags().StringVar(&backupComponents, "components", "manifests,webui,lite-llm",
+		"Components to back up: manifests, blobs, webui, lite-llm or all")
+	backupCreateCmd.Flags().BoolVar(&backupDedup, "dedup", true, "Keep blobs in the shared blob store instead of the archive")
+	backupRestoreCmd.Flags().StringVar(&restoreComponents, "components", "", "Components to restore (default: all in the backup)")
+	backupRestoreCmd.Flags().BoolVar(&restoreVerifyOnly, "verify-only", false, "Check the backup's integrity without restoring it")
+}
+
+func backupRepository() (*backup.Repository, error) {
+	dir := backupDir
+	if dir == "" {
+		dir = viper.GetString("backup.dir")
+	}
+	dir, err := expandHome(dir)
+	if err != nil {
+		return nil, err
+	}
+	return backup.NewRepository(dir), nil
+}
+
+// backupSources resolves the directories the components read or write.
+func backupSources(components []string) (backup.Sources, error) {
+	var sources backup.Sources
+	for _, component := range components {
+		var err error
+		switch component {
+		case backup.ComponentManifests, backup.ComponentBlobs:
+			if sources.ModelsDir == "" {
+				sources.ModelsDir, err = resolveModelsDir()
+			}
+		case backup.ComponentWebUI:
+			sources.WebUIDir, err = resolveWebUIDir()
+		case backup.ComponentLiteLLM:
+			sources.LiteLLMPaths, err = liteLLMStatePaths()
+		}
+		if err != nil {
+			return sources, err
+		}
+	}
+	return sources, nil
+}
+
+// liteLLMStatePaths are the files lite-llm itself keeps: its config, the
+// metrics history, usage records, API keys and the stack's project
+// directory.
+func liteLLMStatePaths() ([]string, error) {
+	var paths []string
+	if config := viper.ConfigFileUsed(); config != "" {
+		paths = append(paths, config)
+	}
+	if history := viper.GetString("metrics.history_file"); history != "" {
+		paths = append(paths, history)
+	}
+	dir, err := usageDir()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := keysPath()
+	if err != nil {
+		return nil, err
+	}
+	project, err := projectDir(viper.GetString("stack.name"))
+	if err != nil {
+		return nil, err
+	}
+	return append(paths, dir, keys, project), nil
+}
+
+// webUIContainer finds the stack's Open WebUI container, if Docker is
+// reachable and it exists.
+func webUIContainer(ctx context.Context, client *docker.Client) *docker.Container {
+	container, err := client.FindService(ctx, viper.GetString("stack.name"), "webui")
+	if err != nil {
+		logrus.Debugf("Open WebUI container not found: %v", err)
+		return nil
+	}
+	return container
+}
+
+func runBackupCreate() error {
+	components, err := backup.ParseComponents(backupComponents)
+	if err != nil {
+		return err
+	}
+	repo, err := backupRepository()
+	if err != nil {
+		return err
+	}
+	sources, err := backupSources(components)
+	if err != nil {
+		return err
+	}
+
+	client := docker.NewClient(viper.GetString("docker.socket"))
+	index, err := repo.Create(backup.CreateOptions{
+		Name:       backupName,
+		Components: components,
+		Sources:    sources,
+		Dedup:      backupDedup,
+		Quiesce: func(component string) (func(), error) {
+			if component != backup.ComponentWebUI {
+				return nil, nil
+			}
+			// Pausing keeps Open WebUI's database consistent while it is copied.
+			ctx := context.Background()
+			container := webUIContainer(ctx, client)
+			if container == nil || container.State != "running" {
+				return nil, nil
+			}
+			if err := client.PauseContainer(ctx, container.ID); err != nil {
+				return nil, fmt.Errorf("failed to pause %s: %w", container.Name(), err)
+			}
+			return func() {
+				if err := client.UnpauseContainer(ctx, container.ID); err != nil {
+					logrus.Errorf("Failed to unpause %s: %v", container.Name(), err)
+				}
+			}, nil
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+
+	if structuredOutput() {
+		return printStructured(index)
+	}
+	logrus.Infof("Backup %s created: %s (%s)", index.Name, repo.ArchivePath(index.Name), system.FormatBytes(index.ArchiveSize))
+	return nil
+}
+
+func runBackupList() error {
+	repo, err := backupRepository()
+	if err != nil {
+		return err
+	}
+	backups, err := repo.List()
+	if err != nil {
+		return err
+	}
+	storeSize, err := repo.BlobStoreSize()
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		if backups == nil {
+			backups = []backup.Index{}
+		}
+		return printStructured(map[string]interface{}{
+			"dir":             repo.Dir,
+			"backups":         backups,
+			"blob_store_size": storeSize,
+		})
+	}
+
+	if len(backups) == 0 {
+		logrus.Infof("No backups in %s", repo.Dir)
+		logrus.Info("Create one with 'lite-llm backup create'")
+		return nil
+	}
+
+	logrus.Infof("Backups in %s:", repo.Dir)
+	for _, b := range backups {
+		line := fmt.Sprintf("  - %s  %s  %s  [%s]", b.Name, b.CreatedAt.Local().Format("2006-01-02 15:04"),
+			system.FormatBytes(b.ArchiveSize), strings.Join(b.Components, ", "))
+		if len(b.Models) > 0 {
+			line += fmt.Sprintf("  %d model(s)", len(b.Models))
+		}
+		if len(b.Blobs) > 0 {
+			line += fmt.Sprintf(", %s of blobs", system.FormatBytes(b.BlobSize()))
+		}
+		logrus.Info(line)
+	}
+	if storeSize > 0 {
+		logrus.Infof("Shared blob store: %s", system.FormatBytes(storeSize))
+	}
+	return nil
+}
+
+func runBackupRestore(ref string) error {
+	repo, err := backupRepository()
+	if err != nil {
+		return err
+	}
+	name := ref
+	if strings.HasSuffix(ref, ".tar.gz") {
+		repo = backup.NewRepository(filepath.Dir(ref))
+		name = strings.TrimSuffix(filepath.Base(ref), ".tar.gz")
+	}
+
+	var components []string
+	if restoreComponents != "" {
+		if components, err = backup.ParseComponents(restoreComponents); err != nil {
+			return err
+		}
+	} else if index, err := repo.Index(name); err == nil {
+		components = index.Components
+	}
+
+	var targets backup.Sources
+	if !restoreVerifyOnly {
+		if targets, err = backupSources(components); err != nil {
+			return err
+		}
+	}
+
+	client := docker.NewClient(viper.GetString("docker.socket"))
+	started := time.Now()
+	result, err := repo.Restore(name, backup.RestoreOptions{
+		Components: components,
+		Targets:    targets,
+		VerifyOnly: restoreVerifyOnly,
+		Quiesce: func(component string) (func(), error) {
+			if component != backup.ComponentWebUI {
+				return nil, nil
+			}
+			// Open WebUI must reopen its database after it is replaced.
+			ctx := context.Background()
+			container := webUIContainer(ctx, client)
+			if container == nil || container.State != "running" {
+				return nil, nil
+			}
+			logrus.Infof("Stopping %s", container.Name())
+			if err := client.StopContainer(ctx, container.ID, 30); err != nil {
+				return nil, fmt.Errorf("failed to stop %s: %w", container.Name(), err)
+			}
+			return func() {
+				logrus.Infof("Starting %s", container.Name())
+				if err := client.StartContainer(ctx, container.ID); err != nil {
+					logrus.Errorf("Failed to start %s: %v", container.Name(), err)
+				}
+			}, nil
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+
+	if structuredOutput() {
+		return printStructured(result)
+	}
+	if restoreVerifyOnly {
+		logrus.Infof("Backup %s is intact (%d files, %d blobs verified in %s)", name,
+			len(result.Index.Files), len(result.Index.Blobs), time.Since(started).Round(time.Second))
+		return nil
+	}
+	logrus.Infof("Restored backup %s", name)
+	if len(result.Skipped) > 0 {
+		logrus.Warnf("%d model(s) were skipped because their blobs are not installed; download them again:", len(result.Skipped))
+		for _, model := range result.Skipped {
+			logrus.Warnf("  lite-llm models download %s", model)
+		}
+	}
+	return nil
+}
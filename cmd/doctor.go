@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/lyleclassen/lite-llm/internal/doctor"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose common deployment problems",
+	Long: `Run a catalogue of checks against the host and the running services:
+Docker, GPU device access, ROCm and HSA override compatibility, Ollama GPU
+offload, port conflicts and disk space. Each failing check explains the
+problem and prints a command to fix it.
+
+Use --json to produce a report suitable for attaching to bug reports.
+Exits with status 1 if any check fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return runDoctor()
+	},
+}
+
+var doctorJSON bool
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Print the report as JSON (same as --output json)")
+}
+
+func runDoctor() error {
+	if doctorJSON {
+		outputFormat = outputJSON
+	}
+
+	cfg := doctor.Config{
+		OllamaURL:       "http://localhost:11434",
+		OllamaPort:      viper.GetInt("ollama.port"),
+		WebUIPort:       viper.GetInt("webui.port"),
+		ServePort:       8080,
+		GPUType:         viper.GetString("gpu.type"),
+		OverrideVersion: viper.GetString("gpu.override_version"),
+		ModelsDir:       viper.GetString("models.dir"),
+		DockerSocket:    viper.GetString("docker.socket"),
+	}
+
+	report := doctor.Run(context.Background(), cfg)
+
+	if structuredOutput() {
+		if err := printStructured(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(report)
+	}
+
+	if report.Failed() {
+		return &ExitError{Code: 1, Message: "one or more checks failed"}
+	}
+	return nil
+}
+
+func printDoctorReport(report *doctor.Report) {
+	logrus.Info("=== Lite LLM Doctor ===")
+	logrus.Info("")
+
+	for _, r := range report.Results {
+		switch r.Status {
+		case doctor.StatusPass:
+			logrus.Infof("✓ %s: %s", r.Title, r.Message)
+		case doctor.StatusSkip:
+			logrus.Infof("- %s: %s", r.Title, r.Message)
+		case doctor.StatusWarn:
+			logrus.Warnf("! %s: %s", r.Title, r.Message)
+		case doctor.StatusFail:
+			logrus.Errorf("✗ %s: %s", r.Title, r.Message)
+		}
+
+		if r.Status == doctor.StatusWarn || r.Status == doctor.StatusFail {
+			if r.Explanation != "" {
+				logrus.Infof("    Why: %s", r.Explanation)
+			}
+			if r.Remediation != "" {
+				logrus.Infof("    Fix: %s", r.Remediation)
+			}
+		}
+	}
+
+	logrus.Info("")
+	logrus.Infof("%d passed, %d warnings, %d failed, %d skipped",
+		report.Summary[doctor.StatusPass],
+		report.Summary[doctor.StatusWarn],
+		report.Summary[doctor.StatusFail],
+		report.Summary[doctor.StatusSkip])
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/auth"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage API keys for the web server",
+	Long: `Manage the API keys 'lite-llm serve' accepts. API clients send a key as
+"Authorization: Bearer <key>"; browsers sign in with one.
+
+Scopes: chat (models, chat and the dashboard) and admin (all of that, plus
+request and container logs). A running server picks up changes immediately.`,
+}
+
+var keysCreateCmd = &cobra.Command{
+	Use:     "create [name]",
+	Short:   "Create an API key",
+	Example: `  lite-llm keys create alice --scopes chat --expires 90d`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKeysCreate(args[0])
+	},
+}
+
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List API keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKeysList()
+	},
+}
+
+var keysRevokeCmd = &cobra.Command{
+	Use:   "revoke [id-or-name]",
+	Short: "Revoke an API key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKeysRevoke(args[0])
+	},
+}
+
+var (
+	keyScopes  string
+	keyExpires string
+)
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+	keysCmd.AddCommand(keysCreateCmd)
+	keysCmd.AddCommand(keysListCmd)
+	keysCmd.AddCommand(keysRevokeCmd)
+
+	keysCreateCmd.Flags().StringVar(&keyScopes, "scopes", auth.ScopeChat, "Comma-separated scopes: "+strings.Join(auth.Scopes, ", "))
+	keysCreateCmd.Flags().StringVar(&keyExpires, "expires", "", "Expire the key after this long, e.g. 90d (default: never)")
+}
+
+// keysPath is where API keys are stored.
+func keysPath() (string, error) {
+	return expandHome(viper.GetString("auth.keys_file"))
+}
+
+func openKeyStore() (*auth.KeyStore, error) {
+	path, err := keysPath()
+	if err != nil {
+		return nil, err
+	}
+	return auth.OpenKeyStore(path)
+}
+
+// KeyEntry is the stable schema for a key in `keys` output. The token is
+// only present when the key is created.
+type KeyEntry struct {
+	ID        string     `json:"id" yaml:"id"`
+	Name      string     `json:"name" yaml:"name"`
+	Scopes    []string   `json:"scopes" yaml:"scopes"`
+	CreatedAt time.Time  `json:"created_at" yaml:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	Status    string     `json:"status" yaml:"status"`
+	Token     string     `json:"token,omitempty" yaml:"token,omitempty"`
+}
+
+func keyEntry(key *auth.Key) KeyEntry {
+	return KeyEntry{
+		ID:        key.ID,
+		Name:      key.Name,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt,
+		ExpiresAt: key.ExpiresAt,
+		Status:    key.Status(time.Now()),
+	}
+}
+
+func runKeysCreate(name string) error {
+	scopes, err := auth.ParseScopes(keyScopes)
+	if err != nil {
+		return err
+	}
+	var ttl time.Duration
+	if keyExpires != "" {
+		if ttl, err = parseAge(keyExpires); err != nil {
+			return err
+		}
+	}
+
+	store, err := openKeyStore()
+	if err != nil {
+		return err
+	}
+	key, token, err := store.Create(name, scopes, ttl)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		entry := keyEntry(key)
+		entry.Token = token
+		return printStructured(entry)
+	}
+	logrus.Infof("Created key %s (%s) with scopes: %s", key.Name, key.ID, strings.Join(key.Scopes, ", "))
+	if key.ExpiresAt != nil {
+		logrus.Infof("Expires: %s", key.ExpiresAt.Local().Format("2006-01-02 15:04"))
+	}
+	logrus.Info("")
+	logrus.Infof("  %s", token)
+	logrus.Info("")
+	logrus.Info("This is the only time the key is shown; store it somewhere safe.")
+	return nil
+}
+
+func runKeysList() error {
+	store, err := openKeyStore()
+	if err != nil {
+		return err
+	}
+	keys, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]KeyEntry, 0, len(keys))
+	for i := range keys {
+		entries = append(entries, keyEntry(&keys[i]))
+	}
+	if structuredOutput() {
+		return printStructured(map[string][]KeyEntry{"keys": entries})
+	}
+
+	if len(entries) == 0 {
+		logrus.Infof("No API keys in %s", store.Path())
+		logrus.Info("Create one with 'lite-llm keys create <name>'")
+		return nil
+	}
+	logrus.Infof("%-12s %-20s %-26s %-16s %-16s %s", "ID", "NAME", "SCOPES", "CREATED", "EXPIRES", "STATUS")
+	for _, entry := range entries {
+		expires := "never"
+		if entry.ExpiresAt != nil {
+			expires = entry.ExpiresAt.Local().Format("2006-01-02 15:04")
+		}
+		logrus.Infof("%-12s %-20s %-26s %-16s %-16s %s", entry.ID, entry.Name, strings.Join(entry.Scopes, ","),
+			entry.CreatedAt.Local().Format("2006-01-02 15:04"), expires, entry.Status)
+	}
+	return nil
+}
+
+func runKeysRevoke(idOrName string) error {
+	store, err := openKeyStore()
+	if err != nil {
+		return err
+	}
+	key, err := store.Revoke(idOrName)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printStructured(keyEntry(key))
+	}
+	logrus.Infof("Revoked key %s (%s)", key.Name, key.ID)
+	return nil
+}
+
+// bootstrapAdminKey creates an admin key the first time the server runs
+// with authentication, so that someone can sign in at all. Its token goes
+// to a file only the user can read, next to the keys file, rather than to
+// logs that may be collected elsewhere.
+func bootstrapAdminKey(store *auth.KeyStore) error {
+	empty, err := store.Empty()
+	if err != nil || !empty {
+		return err
+	}
+	key, token, err := store.Create("admin", []string{auth.ScopeAdmin}, 0)
+	if err != nil {
+		return fmt.Errorf("failed to create the admin key: %w", err)
+	}
+
+	path := filepath.Join(filepath.Dir(store.Path()), "admin.key")
+	if err := writeTokenFile(path, token); err != nil {
+		// Nobody could use the key, so don't leave it behind.
+		if _, revokeErr := store.Revoke(key.ID); revokeErr != nil {
+			logrus.Warnf("Failed to revoke the unusable admin key: %v", revokeErr)
+		}
+		return fmt.Errorf("failed to save the admin key: %w", err)
+	}
+	logrus.Warnf("No API keys existed, so an admin key was created; its token is in %s", path)
+	logrus.Warn("Sign in with it, create keys for others with 'lite-llm keys create', then delete the file.")
+	return nil
+}
+
+// writeTokenFile writes token to a new file readable only by the user.
+func writeTokenFile(path, token string) error {
+	// A leftover file may have looser permissions than a new one gets.
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(token + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"syscall"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/docker"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [ollama|webui]",
+	Short: "Show container logs",
+	Long: `Print the logs of a stack container (default: ollama) through the Docker
+Engine API. stdout goes to stdout and stderr to stderr.
+
+Examples:
+  lite-llm logs -f                      # follow Ollama
+  lite-llm logs webui --since 10m
+  lite-llm logs ollama --grep 'ERROR|rocm'`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service := "ollama"
+		if len(args) > 0 {
+			service = args[0]
+		}
+		return runLogs(cmd, service)
+	},
+}
+
+var (
+	logsFollow     bool
+	logsSince      string
+	logsTail       int
+	logsGrep       string
+	logsTimestamps bool
+	logsStack      string
+)
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep streaming new log lines")
+	logsCmd.Flags().StringVar(&logsSince, "since", "", "Only show lines since a duration ago (10m) or an RFC 3339 timestamp")
+	logsCmd.Flags().IntVarP(&logsTail, "tail", "n", 100, "Lines to show from the end of the log (-1 for all; default all with --since)")
+	logsCmd.Flags().StringVar(&logsGrep, "grep", "", "Only show lines matching a regular expression")
+	logsCmd.Flags().BoolVarP(&logsTimestamps, "timestamps", "t", false, "Prefix lines with their timestamp")
+	logsCmd.Flags().StringVar(&logsStack, "stack", "", "Stack the container belongs to (default: stack.name from config)")
+}
+
+func runLogs(cmd *cobra.Command, service string) error {
+	opts := docker.LogsOptions{Follow: logsFollow, Tail: logsTail}
+	if logsSince != "" {
+		since, err := docker.ParseSince(logsSince)
+		if err != nil {
+			return err
+		}
+		opts.Since = since
+		if !cmd.Flags().Changed("tail") {
+			opts.Tail = -1
+		}
+	}
+
+	var grep *regexp.Regexp
+	if logsGrep != "" {
+		re, err := regexp.Compile(logsGrep)
+		if err != nil {
+			return fmt.Errorf("invalid --grep: %w", err)
+		}
+		grep = re
+	}
+
+	stack := logsStack
+	if stack == "" {
+		stack = viper.GetString("stack.name")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := docker.NewClient(viper.GetString("docker.socket"))
+	container, err := client.FindService(ctx, stack, service)
+	if err != nil {
+		return err
+	}
+
+	return client.ContainerLogs(ctx, container.ID, opts, func(line docker.LogLine) error {
+		if grep != nil && !grep.MatchString(line.Text) {
+			return nil
+		}
+		if structuredOutput() {
+			if err := printStructured(line); err != nil {
+				return err
+			}
+			if outputFormat == outputYAML {
+				fmt.Println("---")
+			}
+			return nil
+		}
+
+		out := os.Stdout
+		if line.Stream == "stderr" {
+			out = os.Stderr
+		}
+		if logsTimestamps && !line.Time.IsZero() {
+			fmt.Fprintf(out, "%s %s\n", line.Time.Local().Format(time.RFC3339), line.Text)
+		} else {
+			fmt.Fprintln(out, line.Text)
+		}
+		return nil
+	})
+}
@@ -3,10 +3,13 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/lyleclassen/lite-llm/internal/system"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var modelsCmd = &cobra.Command{
@@ -57,6 +60,25 @@ func init() {
 	modelsCmd.AddCommand(recommendedCmd)
 }
 
+// ModelEntry is the stable schema for a model in structured output.
+type ModelEntry struct {
+	Name       string    `json:"name" yaml:"name"`
+	SizeBytes  int64     `json:"size_bytes" yaml:"size_bytes"`
+	ModifiedAt time.Time `json:"modified_at" yaml:"modified_at"`
+}
+
+func modelEntries(models []ollama.Model) []ModelEntry {
+	entries := make([]ModelEntry, 0, len(models))
+	for _, model := range models {
+		entries = append(entries, ModelEntry{
+			Name:       model.Name,
+			SizeBytes:  model.Size,
+			ModifiedAt: model.Modified,
+		})
+	}
+	return entries
+}
+
 func runListModels() error {
 	client := ollama.NewClient("http://localhost:11434")
 	
@@ -65,6 +87,10 @@ func runListModels() error {
 		return fmt.Errorf("failed to list models: %w", err)
 	}
 
+	if structuredOutput() {
+		return printStructured(map[string][]ModelEntry{"models": modelEntries(models)})
+	}
+
 	if len(models) == 0 {
 		logrus.Info("No models installed")
 		logrus.Info("Use 'lite-llm models recommended' to download recommended models")
@@ -115,19 +141,78 @@ func runRemoveModel(modelName string) error {
 	return nil
 }
 
+// recommendedModel is a model with its approximate download size.
+type recommendedModel struct {
+	Name   string
+	SizeGB float64
+}
+
+// cpuModels are small quantized models suited to CPU inference, smallest
+// first. Q4_K_M keeps quality close to the full model at a quarter of the
+// memory bandwidth per token, which is what limits CPU speed.
+var cpuModels = []recommendedModel{
+	{"qwen2.5:0.5b-instruct-q4_K_M", 0.4},
+	{"llama3.2:1b-instruct-q4_K_M", 0.8},
+	{"gemma2:2b-instruct-q4_K_M", 1.7},
+	{"llama3.2:3b-instruct-q4_K_M", 2.0},
+	{"phi3.5:3.8b-mini-instruct-q4_K_M", 2.4},
+	{"mistral:7b-instruct-q4_K_M", 4.4},
+}
+
+// recommendedModels picks models for the host. GPU deployments get the 8GB
+// VRAM set; CPU-only deployments get up to three small models that together
+// fit in a quarter of system RAM, leaving room for the KV cache and the OS.
+func recommendedModels(info *system.SystemInfo, gpuType string) []string {
+	if gpuType != "cpu" {
+		// Recommended models for 8GB VRAM GPUs
+		return []string{
+			"llama3.1:8b-instruct-q4_K_M", // ~4.4GB
+			"mistral:7b-instruct-q4_K_M",  // ~4.4GB
+			"gemma2:2b-instruct-q4_K_M",   // ~1.7GB
+		}
+	}
+
+	budget := float64(info.SystemMemory) / 1024 / 4
+	var fits []string
+	var used float64
+	for i := len(cpuModels) - 1; i >= 0 && len(fits) < 3; i-- {
+		if m := cpuModels[i]; used+m.SizeGB <= budget {
+			fits = append(fits, m.Name)
+			used += m.SizeGB
+		}
+	}
+	if len(fits) == 0 {
+		fits = append(fits, cpuModels[0].Name)
+	}
+	return fits
+}
+
 func runDownloadRecommended() error {
-	// Recommended models for RX 570/580 (8GB VRAM)
-	recommendedModels := []string{
-		"llama3.1:8b-instruct-q4_K_M",  // ~4.4GB
-		"mistral:7b-instruct-q4_K_M",   // ~4.4GB  
-		"gemma2:2b-instruct-q4_K_M",    // ~1.7GB
+	// gpu.type set in the config file wins over detection; the default
+	// only stands when detection finds a GPU.
+	gpuType := viper.GetString("gpu.type")
+	configured := viper.InConfig("gpu.type")
+
+	info, err := system.NewChecker().GetSystemInfo()
+	if err != nil {
+		logrus.Warnf("System detection failed, using gpu.type %s: %v", gpuType, err)
+		info = &system.SystemInfo{}
+	} else if info.GPUType == "unknown" && !configured {
+		gpuType = "cpu"
 	}
+	models := recommendedModels(info, gpuType)
 
 	client := ollama.NewClient("http://localhost:11434")
 
-	logrus.Info("Downloading recommended models for AMD RX 570/580...")
+	if gpuType == "cpu" && info.SystemMemory > 0 {
+		logrus.Infof("Downloading CPU-sized models for %d MB of system memory...", info.SystemMemory)
+	} else if gpuType == "cpu" {
+		logrus.Info("Downloading the smallest CPU model, as system memory is unknown...")
+	} else {
+		logrus.Info("Downloading recommended models for 8GB VRAM GPUs...")
+	}
 	
-	for _, model := range recommendedModels {
+	for _, model := range models {
 		logrus.Infof("Downloading %s...", model)
 		
 		err := client.PullModel(context.Background(), model, func(progress ollama.PullProgress) {
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/lyleclassen/lite-llm/internal/system"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFile       string
+	importName       string
+	importVerifyOnly bool
+)
+
+var modelsExportCmd = &cobra.Command{
+	Use:   "export [model-name...]",
+	Short: "Bundle models into a tar file for offline transfer",
+	Long: `Write models' manifests and blobs from the Ollama model store into a single
+tar file that can be carried to a machine without internet access and
+installed there with 'lite-llm models import-bundle'.
+
+The model store is read directly (ollama.models_dir, or the stack's
+ollama_data volume), which usually needs root.`,
+	Example: `  lite-llm models export llama3.1:8b -o llama3.1.tar
+  lite-llm models export mistral:7b gemma2:2b -o models.tar`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelsExport(args, exportFile)
+	},
+}
+
+var modelsImportCmd = &cobra.Command{
+	Use:   "import-bundle [file]",
+	Short: "Install models from a bundle made by 'models export'",
+	Long: `Verify every blob in a bundle against its digest, then upload the blobs
+Ollama doesn't already have and create the models through Ollama's API.
+Nothing is installed if any part of the bundle is missing or corrupt.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelsImport(args[0])
+	},
+}
+
+func init() {
+	modelsCmd.AddCommand(modelsExportCmd)
+	modelsCmd.AddCommand(modelsImportCmd)
+
+	modelsExportCmd.Flags().StringVarP(&exportFile, "out", "o", "", "Bundle file to write")
+	modelsExportCmd.MarkFlagRequired("out")
+	modelsImportCmd.Flags().StringVar(&importName, "name", "", "Install the model under this name (bundles with one model only)")
+	modelsImportCmd.Flags().BoolVar(&importVerifyOnly, "verify-only", false, "Check the bundle's integrity without installing it")
+}
+
+// ImportReport is the stable schema for `models import-bundle` output.
+type ImportReport struct {
+	Models        []string `json:"models" yaml:"models"`
+	BlobsUploaded int      `json:"blobs_uploaded" yaml:"blobs_uploaded"`
+	BlobsPresent  int      `json:"blobs_present" yaml:"blobs_present"`
+	BytesUploaded int64    `json:"bytes_uploaded" yaml:"bytes_uploaded"`
+	VerifyOnly    bool     `json:"verify_only,omitempty" yaml:"verify_only,omitempty"`
+}
+
+func runModelsExport(args []string, file string) error {
+	names := make([]ollama.ModelName, len(args))
+	for i, arg := range args {
+		name, err := ollama.ParseModelName(arg)
+		if err != nil {
+			return err
+		}
+		names[i] = name
+	}
+
+	dir, err := resolveModelsDir()
+	if err != nil {
+		return err
+	}
+	store := ollama.NewStore(dir)
+
+	tmp := file + ".partial"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	index, err := store.ExportBundle(f, names, func(blob ollama.Layer) {
+		if !structuredOutput() {
+			logrus.Infof("Adding %s (%s)", blob.Digest, system.FormatBytes(blob.Size))
+		}
+	})
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to export models: %w", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if structuredOutput() {
+		return printStructured(index)
+	}
+	logrus.Infof("Exported %d model(s), %s, to %s", len(index.Models), system.FormatBytes(index.Size()), file)
+	logrus.Infof("Install it on the other machine with: lite-llm models import-bundle %s", file)
+	return nil
+}
+
+func runModelsImport(file string) error {
+	if !structuredOutput() {
+		logrus.Infof("Verifying %s", file)
+	}
+	bundle, err := ollama.OpenBundle(file)
+	if err != nil {
+		return fmt.Errorf("bundle failed verification: %w", err)
+	}
+
+	report := ImportReport{VerifyOnly: importVerifyOnly}
+	for _, model := range bundle.Index.Models {
+		report.Models = append(report.Models, model.Name)
+	}
+	if importName != "" && len(report.Models) != 1 {
+		return fmt.Errorf("--name needs a bundle with one model; this one has %d", len(report.Models))
+	}
+
+	// Build every create request up front so a model Ollama can't recreate
+	// fails the import before anything is uploaded.
+	creates := make([]*ollama.CreateRequest, len(report.Models))
+	for i, name := range report.Models {
+		as := name
+		if importName != "" {
+			as = importName
+		}
+		if creates[i], err = bundle.CreateRequest(name, as); err != nil {
+			return err
+		}
+	}
+	if importVerifyOnly {
+		if structuredOutput() {
+			return printStructured(report)
+		}
+		logrus.Infof("Bundle is intact: %d model(s), %d blobs, %s", len(report.Models), len(bundle.Index.Blobs), system.FormatBytes(bundle.Index.Size()))
+		return nil
+	}
+
+	ctx := context.Background()
+	client := ollama.NewClient(localOllamaURL())
+	if err := client.Health(ctx); err != nil {
+		return fmt.Errorf("Ollama is not reachable at %s: %w", localOllamaURL(), err)
+	}
+
+	err = bundle.WalkBlobs(func(blob ollama.Layer, r io.Reader) error {
+		present, err := client.HasBlob(ctx, blob.Digest)
+		if err != nil {
+			return err
+		}
+		if present {
+			report.BlobsPresent++
+			return nil
+		}
+		if !structuredOutput() {
+			logrus.Infof("Uploading %s (%s)", blob.Digest, system.FormatBytes(blob.Size))
+		}
+		if err := client.PushBlob(ctx, blob.Digest, blob.Size, r); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", blob.Digest, err)
+		}
+		report.BlobsUploaded++
+		report.BytesUploaded += blob.Size
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, create := range creates {
+		if err := client.CreateModel(ctx, *create); err != nil {
+			return fmt.Errorf("failed to create %s: %w", create.Model, err)
+		}
+		report.Models[i] = create.Model
+		if !structuredOutput() {
+			logrus.Infof("Installed %s", create.Model)
+		}
+	}
+
+	if structuredOutput() {
+		return printStructured(report)
+	}
+	logrus.Infof("Imported %d model(s): uploaded %d blobs (%s), %d already present",
+		len(report.Models), report.BlobsUploaded, system.FormatBytes(report.BytesUploaded), report.BlobsPresent)
+	return nil
+}
@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/lyleclassen/lite-llm/internal/system"
+	"github.com/lyleclassen/lite-llm/internal/usage"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var modelsDuCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Show disk usage of installed models",
+	Long: `Show how much disk each model uses, reading Ollama's manifests and blobs
+directly (ollama.models_dir, or the stack's ollama_data volume; usually needs
+root). Models built from the same base share blobs: UNIQUE is what removing
+the model would free, SHARED is used by other models too.
+
+Orphaned blobs are referenced by no model, e.g. left behind by interrupted
+downloads.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelsDu()
+	},
+}
+
+var modelsPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove models that haven't been used recently",
+	Long: `Remove models not used for --unused-for, keeping the --keep most recently
+used ones. A model's last use is recorded by 'lite-llm serve' (requests it
+handles, and models Ollama has loaded); models it never saw count from when
+they were downloaded. Loaded models are never removed.
+
+--orphans also deletes blobs no model references.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelsPrune()
+	},
+}
+
+var (
+	pruneUnusedFor string
+	pruneKeep      int
+	pruneOrphans   bool
+	pruneDryRun    bool
+)
+
+func init() {
+	modelsCmd.AddCommand(modelsDuCmd)
+	modelsCmd.AddCommand(modelsPruneCmd)
+
+	modelsPruneCmd.Flags().StringVar(&pruneUnusedFor, "unused-for", "", "Remove models unused for this long, e.g. 30d or 12h")
+	modelsPruneCmd.Flags().IntVar(&pruneKeep, "keep", 0, "Always keep this many of the most recently used models")
+	modelsPruneCmd.Flags().BoolVar(&pruneOrphans, "orphans", false, "Delete blobs no model references")
+	modelsPruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be removed without removing it")
+}
+
+// localOllamaURL is the API of the stack's Ollama on this host.
+func localOllamaURL() string {
+	return fmt.Sprintf("http://localhost:%d", viper.GetInt("ollama.port"))
+}
+
+func usageDir() (string, error) {
+	return expandHome(viper.GetString("usage.dir"))
+}
+
+// lastUsedPath is where `serve` records when each model was last used.
+func lastUsedPath() string {
+	dir, err := usageDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "last-used.json")
+}
+
+// parseAge parses a duration that may also be given in days or weeks,
+// e.g. "30d" or "2w".
+func parseAge(v string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(v, suffix); ok {
+			if days, err := strconv.ParseFloat(n, 64); err == nil && days >= 0 {
+				return time.Duration(days * float64(unit)), nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q: expected e.g. 30d, 2w or 12h", v)
+	}
+	return d, nil
+}
+
+// ModelDiskUsage is the stable schema for a model in `models du` output.
+type ModelDiskUsage struct {
+	Name         string     `json:"name" yaml:"name"`
+	SizeBytes    int64      `json:"size_bytes" yaml:"size_bytes"`
+	UniqueBytes  int64      `json:"unique_bytes" yaml:"unique_bytes"`
+	SharedBytes  int64      `json:"shared_bytes" yaml:"shared_bytes"`
+	SharedWith   []string   `json:"shared_with,omitempty" yaml:"shared_with,omitempty"`
+	MissingBlobs int        `json:"missing_blobs,omitempty" yaml:"missing_blobs,omitempty"`
+	LastUsed     *time.Time `json:"last_used,omitempty" yaml:"last_used,omitempty"`
+}
+
+// OrphanBlob is a blob no model references.
+type OrphanBlob struct {
+	Digest     string    `json:"digest" yaml:"digest"`
+	SizeBytes  int64     `json:"size_bytes" yaml:"size_bytes"`
+	ModifiedAt time.Time `json:"modified_at" yaml:"modified_at"`
+}
+
+// DiskUsageReport is the stable schema of `models du` output.
+type DiskUsageReport struct {
+	ModelsDir   string           `json:"models_dir" yaml:"models_dir"`
+	Models      []ModelDiskUsage `json:"models" yaml:"models"`
+	BlobBytes   int64            `json:"blob_bytes" yaml:"blob_bytes"`
+	Orphans     []OrphanBlob     `json:"orphans" yaml:"orphans"`
+	OrphanBytes int64            `json:"orphan_bytes" yaml:"orphan_bytes"`
+}
+
+// diskUsage attributes the blobs in a model store to the models using them.
+func diskUsage(store *ollama.Store, lastUsed *usage.LastUsed) (*DiskUsageReport, error) {
+	models, err := store.Models()
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := store.Blobs()
+	if err != nil {
+		return nil, err
+	}
+
+	onDisk := make(map[string]bool, len(blobs))
+	for _, blob := range blobs {
+		onDisk[blob.Digest] = true
+	}
+	users := map[string][]string{}
+	for _, model := range models {
+		for _, blob := range model.Manifest.Blobs() {
+			users[blob.Digest] = append(users[blob.Digest], model.Name.String())
+		}
+	}
+
+	report := &DiskUsageReport{ModelsDir: store.Dir, Models: []ModelDiskUsage{}, Orphans: []OrphanBlob{}}
+	for _, model := range models {
+		name := model.Name.String()
+		entry := ModelDiskUsage{Name: name}
+		shared := map[string]bool{}
+		for _, blob := range model.Manifest.Blobs() {
+			if !onDisk[blob.Digest] {
+				entry.MissingBlobs++
+				continue
+			}
+			entry.SizeBytes += blob.Size
+			if len(users[blob.Digest]) == 1 {
+				entry.UniqueBytes += blob.Size
+				continue
+			}
+			entry.SharedBytes += blob.Size
+			for _, other := range users[blob.Digest] {
+				if other != name {
+					shared[other] = true
+				}
+			}
+		}
+		for other := range shared {
+			entry.SharedWith = append(entry.SharedWith, other)
+		}
+		sort.Strings(entry.SharedWith)
+		if t := lastUsed.Get(name); !t.IsZero() {
+			entry.LastUsed = &t
+		}
+		report.Models = append(report.Models, entry)
+	}
+	sort.SliceStable(report.Models, func(i, j int) bool {
+		return report.Models[i].UniqueBytes > report.Models[j].UniqueBytes
+	})
+
+	for _, blob := range blobs {
+		report.BlobBytes += blob.Size
+		if len(users[blob.Digest]) == 0 {
+			report.Orphans = append(report.Orphans, OrphanBlob{Digest: blob.Digest, SizeBytes: blob.Size, ModifiedAt: blob.ModifiedAt})
+			report.OrphanBytes += blob.Size
+		}
+	}
+	return report, nil
+}
+
+func loadLastUsed() *usage.LastUsed {
+	lastUsed := usage.NewLastUsed(lastUsedPath())
+	if err := lastUsed.Load(); err != nil {
+		logrus.Warnf("Failed to load model usage: %v", err)
+	}
+	return lastUsed
+}
+
+func runModelsDu() error {
+	dir, err := resolveModelsDir()
+	if err != nil {
+		return err
+	}
+	report, err := diskUsage(ollama.NewStore(dir), loadLastUsed())
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printStructured(report)
+	}
+
+	logrus.Infof("Model store: %s", report.ModelsDir)
+	if len(report.Models) == 0 {
+		logrus.Info("No models installed")
+	} else {
+		logrus.Infof("%-40s %10s %10s %10s  %s", "MODEL", "SIZE", "UNIQUE", "SHARED", "LAST USED")
+	}
+	for _, m := range report.Models {
+		last := "never"
+		if m.LastUsed != nil {
+			last = formatUptime(time.Since(*m.LastUsed)) + " ago"
+		}
+		logrus.Infof("%-40s %10s %10s %10s  %s", m.Name, system.FormatBytes(m.SizeBytes),
+			system.FormatBytes(m.UniqueBytes), system.FormatBytes(m.SharedBytes), last)
+		if len(m.SharedWith) > 0 {
+			logrus.Infof("  shares blobs with %s", strings.Join(m.SharedWith, ", "))
+		}
+		if m.MissingBlobs > 0 {
+			logrus.Warnf("  %d blob(s) missing; download the model again", m.MissingBlobs)
+		}
+	}
+
+	logrus.Info("")
+	logrus.Infof("Blobs on disk: %s", system.FormatBytes(report.BlobBytes))
+	if len(report.Orphans) > 0 {
+		logrus.Warnf("%d orphaned blob(s) use %s; remove them with 'lite-llm models prune --orphans'",
+			len(report.Orphans), system.FormatBytes(report.OrphanBytes))
+	}
+	return nil
+}
+
+// PruneReport is the stable schema of `models prune` output.
+type PruneReport struct {
+	DryRun         bool         `json:"dry_run" yaml:"dry_run"`
+	Removed        []ModelEntry `json:"removed" yaml:"removed"`
+	OrphansRemoved []OrphanBlob `json:"orphans_removed" yaml:"orphans_removed"`
+	FreedBytes     int64        `json:"freed_bytes" yaml:"freed_bytes"`
+}
+
+// orphanGracePeriod protects blobs of downloads in progress, which are
+// written before the manifest referencing them.
+const orphanGracePeriod = time.Hour
+
+func runModelsPrune() error {
+	if pruneUnusedFor == "" && !pruneOrphans {
+		return fmt.Errorf("nothing to prune: pass --unused-for and/or --orphans")
+	}
+	report := PruneReport{DryRun: pruneDryRun, Removed: []ModelEntry{}, OrphansRemoved: []OrphanBlob{}}
+	action := "Removed"
+	if pruneDryRun {
+		action = "Would remove"
+	}
+
+	if pruneUnusedFor != "" {
+		age, err := parseAge(pruneUnusedFor)
+		if err != nil {
+			return err
+		}
+		removed, err := pruneUnusedModels(time.Now().Add(-age), action)
+		if err != nil {
+			return err
+		}
+		for _, model := range removed {
+			report.Removed = append(report.Removed, model)
+			report.FreedBytes += model.SizeBytes
+		}
+	}
+
+	if pruneOrphans {
+		dir, err := resolveModelsDir()
+		if err != nil {
+			return err
+		}
+		store := ollama.NewStore(dir)
+		du, err := diskUsage(store, usage.NewLastUsed(lastUsedPath()))
+		if err != nil {
+			return err
+		}
+		for _, orphan := range du.Orphans {
+			if time.Since(orphan.ModifiedAt) < orphanGracePeriod {
+				continue
+			}
+			if !pruneDryRun {
+				path, err := store.BlobPath(orphan.Digest)
+				if err != nil {
+					return err
+				}
+				if err := os.Remove(path); err != nil {
+					return fmt.Errorf("failed to remove blob %s: %w", orphan.Digest, err)
+				}
+			}
+			if !structuredOutput() {
+				logrus.Infof("%s orphaned blob %s (%s)", action, orphan.Digest, system.FormatBytes(orphan.SizeBytes))
+			}
+			report.OrphansRemoved = append(report.OrphansRemoved, orphan)
+			report.FreedBytes += orphan.SizeBytes
+		}
+	}
+
+	if structuredOutput() {
+		return printStructured(report)
+	}
+	if len(report.Removed) == 0 && len(report.OrphansRemoved) == 0 {
+		logrus.Info("Nothing to prune")
+		return nil
+	}
+	if pruneDryRun {
+		logrus.Infof("Would free up to %s (run without --dry-run to remove)", system.FormatBytes(report.FreedBytes))
+	} else {
+		logrus.Infof("Freed up to %s", system.FormatBytes(report.FreedBytes))
+	}
+	return nil
+}
+
+// pruneUnusedModels deletes models last used before cutoff through the
+// Ollama API, which also removes blobs no other model needs.
+func pruneUnusedModels(cutoff time.Time, action string) ([]ModelEntry, error) {
+	ctx := context.Background()
+	client := ollama.NewClient(localOllamaURL())
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	running, err := client.ListRunning(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loaded models: %w", err)
+	}
+	loaded := map[string]bool{}
+	for _, model := range running {
+		loaded[usage.NormalizeModel(model.Name)] = true
+	}
+
+	lastUsed := loadLastUsed()
+	last := func(model ollama.Model) time.Time {
+		if t := lastUsed.Get(model.Name); t.After(model.Modified) {
+			return t
+		}
+		return model.Modified
+	}
+	sort.SliceStable(models, func(i, j int) bool { return last(models[i]).After(last(models[j])) })
+
+	var removed []ModelEntry
+	for i, model := range models {
+		if i < pruneKeep || !last(model).Before(cutoff) || loaded[usage.NormalizeModel(model.Name)] {
+			continue
+		}
+		if !pruneDryRun {
+			if err := client.DeleteModel(ctx, model.Name); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", model.Name, err)
+			}
+		}
+		if !structuredOutput() {
+			logrus.Infof("%s %s (%s, last used %s ago)", action, model.Name,
+				system.FormatBytes(model.Size), formatUptime(time.Since(last(model))))
+		}
+		removed = append(removed, ModelEntry{Name: model.Name, SizeBytes: model.Size, ModifiedAt: model.Modified})
+	}
+	return removed, nil
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lyleclassen/lite-llm/internal/library"
+	"github.com/lyleclassen/lite-llm/internal/system"
+	"github.com/lyleclassen/lite-llm/internal/tui"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var searchModelsCmd = &cobra.Command{
+	Use:   "search [query]",
+	Short: "Search the Ollama model library",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSearchModels(args[0])
+	},
+}
+
+var modelTagsCmd = &cobra.Command{
+	Use:   "tags [model-name]",
+	Short: "List a library model's tags and whether they fit this GPU",
+	Long: `List the tags of a model in the Ollama library with their download size and
+quantization, and whether each fits in the detected VRAM (or system RAM for
+CPU-only hosts), leaving room for the context.
+
+In a terminal, a tag can then be picked to download.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runModelTags(args[0])
+	},
+}
+
+func init() {
+	modelsCmd.AddCommand(searchModelsCmd)
+	modelsCmd.AddCommand(modelTagsCmd)
+}
+
+func libraryClient() *library.Client {
+	return library.NewClient(viper.GetString("library.url"), viper.GetString("library.registry_url"))
+}
+
+// ModelTagEntry is the stable schema for a tag in `models tags` output.
+type ModelTagEntry struct {
+	library.Tag `yaml:",inline"`
+	Fit         string `json:"fit,omitempty" yaml:"fit,omitempty"`
+}
+
+func runSearchModels(query string) error {
+	models, err := libraryClient().Search(context.Background(), query)
+	if err != nil {
+		return fmt.Errorf("failed to search models: %w", err)
+	}
+
+	if structuredOutput() {
+		if models == nil {
+			models = []library.Model{}
+		}
+		return printStructured(map[string][]library.Model{"models": models})
+	}
+
+	if len(models) == 0 {
+		logrus.Infof("No models match %q", query)
+		return nil
+	}
+	for _, model := range models {
+		line := model.Name
+		if len(model.Sizes) > 0 {
+			line += " (" + strings.Join(model.Sizes, ", ") + ")"
+		}
+		if model.Pulls != "" {
+			line += fmt.Sprintf(" - %s pulls", model.Pulls)
+		}
+		logrus.Info(line)
+		if model.Description != "" {
+			logrus.Infof("  %s", model.Description)
+		}
+	}
+	logrus.Info("")
+	logrus.Info("List a model's tags with: lite-llm models tags <name>")
+	return nil
+}
+
+// memoryBudget is the memory models are loaded into: VRAM, or system RAM
+// on CPU-only hosts. It returns 0 if unknown.
+func memoryBudget() (mb int, kind string) {
+	info, err := system.NewChecker().GetSystemInfo()
+	if err != nil {
+		return 0, ""
+	}
+	if viper.GetString("gpu.type") != "cpu" && info.GPUMemory > 0 {
+		return info.GPUMemory, "VRAM"
+	}
+	return info.SystemMemory, "RAM"
+}
+
+// modelFit says whether a model of size bytes fits in mb of memory. The
+// context cache and runtime need roughly a fifth on top of the weights;
+// "partial" models run with some layers on the CPU.
+func modelFit(size int64, mb int) string {
+	if size == 0 || mb <= 0 {
+		return ""
+	}
+	available := int64(mb) << 20
+	switch {
+	case size*6/5 <= available:
+		return "fits"
+	case size <= available:
+		return "tight"
+	default:
+		return "partial"
+	}
+}
+
+func runModelTags(name string) error {
+	tags, err := libraryClient().Tags(context.Background(), name)
+	if err != nil {
+		return fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	mb, kind := memoryBudget()
+	entries := make([]ModelTagEntry, len(tags))
+	for i, tag := range tags {
+		entries[i] = ModelTagEntry{Tag: tag, Fit: modelFit(tag.SizeBytes, mb)}
+	}
+
+	if structuredOutput() {
+		return printStructured(map[string]interface{}{"tags": entries, "memory_mb": mb, "memory_kind": kind})
+	}
+
+	fitHeader := "FIT"
+	if mb > 0 {
+		fitHeader = fmt.Sprintf("FITS %s %s", system.FormatMemoryMB(mb), kind)
+	}
+	logrus.Infof("%4s  %-44s %10s  %-8s %-7s %s", "#", "TAG", "SIZE", "QUANT", "PARAMS", fitHeader)
+	for i, entry := range entries {
+		size := "?"
+		if entry.SizeBytes > 0 {
+			size = system.FormatBytes(entry.SizeBytes)
+		}
+		line := fmt.Sprintf("%4d  %-44s %10s  %-8s %-7s %s", i+1, entry.Name, size, entry.Quantization, entry.Parameters, entry.Fit)
+		if entry.Fit == "partial" {
+			logrus.Warn(line)
+		} else {
+			logrus.Info(line)
+		}
+	}
+
+	if !tui.IsTerminal() {
+		logrus.Info("")
+		logrus.Info("Download one with: lite-llm models download <tag>")
+		return nil
+	}
+
+	fmt.Print("\nDownload which tag? (number or name, Enter to skip): ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return nil
+	}
+	choice := answer
+	if n, err := strconv.Atoi(answer); err == nil {
+		if n < 1 || n > len(entries) {
+			return fmt.Errorf("no tag number %d", n)
+		}
+		choice = entries[n-1].Name
+	}
+	return runDownloadModel(choice)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/monitor"
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var monitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "Run the metrics sampler and alert engine",
+	Long: `Run a daemon that samples system and GPU metrics and evaluates the alert
+rules from the "alerts" section of the config file, sending notifications
+when a rule starts firing or resolves.
+
+The same engine also runs inside 'lite-llm serve' when rules are configured.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMonitor()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+
+	monitorCmd.Flags().StringVar(&ollamaURL, "ollama-url", "", "Ollama API URL for the ollama.health probe (default: http://localhost:<ollama.port>)")
+}
+
+func runMonitor() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	history, samplerDone := startSampler(ctx)
+
+	if ollamaURL == "" {
+		ollamaURL = localOllamaURL()
+	}
+	engine, err := startAlerting(ctx, history, ollamaURL)
+	if err != nil {
+		return err
+	}
+	if engine == nil {
+		return fmt.Errorf("no alert rules configured (see 'alerts.rules' in the config file)")
+	}
+
+	logrus.Infof("Monitoring with %d alert rule(s), press Ctrl+C to stop", len(engine.Rules()))
+	for _, rule := range engine.Rules() {
+		logrus.Infof("  - %s: %s", rule.Name, rule.Expr)
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logrus.Info("Stopping monitor...")
+	cancel()
+	<-samplerDone
+
+	return nil
+}
+
+// startSampler starts background metrics sampling configured by the
+// "metrics" config section. The returned channel is closed once sampling
+// has stopped and the history has been persisted.
+func startSampler(ctx context.Context) (*monitor.History, <-chan struct{}) {
+	sampleInterval := viper.GetDuration("metrics.interval")
+	if sampleInterval <= 0 {
+		sampleInterval = 5 * time.Second
+	}
+	capacity := int(viper.GetDuration("metrics.retention") / sampleInterval)
+
+	history := monitor.NewHistory(capacity)
+	sampler := monitor.NewSampler(history, sampleInterval, viper.GetString("metrics.history_file"))
+
+	done := make(chan struct{})
+	go func() {
+		sampler.Run(ctx)
+		close(done)
+	}()
+
+	return history, done
+}
+
+// startAlerting starts the alert engine against history if any rules are
+// configured, probing ollama.health at ollamaURL. It returns a nil engine
+// when alerting is not configured.
+func startAlerting(ctx context.Context, history *monitor.History, ollamaURL string) (*monitor.AlertEngine, error) {
+	var cfg monitor.AlertConfig
+	if err := viper.UnmarshalKey("alerts", &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse alerts config: %w", err)
+	}
+
+	if len(cfg.Rules) == 0 {
+		return nil, nil
+	}
+
+	engine, err := monitor.NewAlertEngineFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid alerts config: %w", err)
+	}
+
+	ollamaClient := ollama.NewClient(ollamaURL)
+	engine.AddStateProbe("ollama.health", func(ctx context.Context) string {
+		probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := ollamaClient.Health(probeCtx); err != nil {
+			return "down"
+		}
+		return "up"
+	})
+
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	go engine.Run(ctx, history, interval)
+
+	return engine, nil
+}
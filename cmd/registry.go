@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/lyleclassen/lite-llm/internal/registry"
+	"github.com/lyleclassen/lite-llm/internal/system"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Run a caching mirror of the Ollama model registry",
+	Long: `Run a pull-through cache of the registry Ollama pulls models from, so
+that each model is downloaded from the internet once and every other host
+pulls it from the local network.`,
+}
+
+var registryServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the registry mirror",
+	Long: `Serve the manifests and blobs Ollama pulls, fetching them from the upstream
+registry on first use and caching them in registry.dir.
+
+Hosts pull through the mirror by naming it in the model:
+
+  ollama pull --insecure mirror.lan:5000/library/llama3.1:8b
+
+(--insecure because the mirror speaks plain HTTP; put it behind a TLS proxy
+to drop it).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRegistryServe()
+	},
+}
+
+var registryWarmCmd = &cobra.Command{
+	Use:     "warm [model-name...]",
+	Short:   "Prefetch models into the registry mirror",
+	Example: `  lite-llm registry warm llama3.1:8b mistral:7b`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRegistryWarm(args)
+	},
+}
+
+var (
+	registryDir      string
+	registryUpstream string
+	registryPort     int
+	registryHost     string
+)
+
+func init() {
+	rootCmd.AddCommand(registryCmd)
+	registryCmd.AddCommand(registryServeCmd)
+	registryCmd.AddCommand(registryWarmCmd)
+
+	registryCmd.PersistentFlags().StringVar(&registryDir, "dir", "", "Cache directory (default: registry.dir from config)")
+	registryCmd.PersistentFlags().StringVar(&registryUpstream, "upstream", "", "Registry to mirror (default: registry.upstream from config)")
+	registryServeCmd.Flags().IntVarP(&registryPort, "port", "p", 5000, "Port to serve on")
+	registryServeCmd.Flags().StringVar(&registryHost, "host", "0.0.0.0", "Host to bind to")
+}
+
+func registryMirror() (*registry.Mirror, error) {
+	dir := registryDir
+	if dir == "" {
+		dir = viper.GetString("registry.dir")
+	}
+	dir, err := expandHome(dir)
+	if err != nil {
+		return nil, err
+	}
+	upstream := registryUpstream
+	if upstream == "" {
+		upstream = viper.GetString("registry.upstream")
+	}
+	return registry.NewMirror(dir, upstream)
+}
+
+func runRegistryServe() error {
+	mirror, err := registryMirror()
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", registryHost, registryPort),
+		Handler: mirror.SetupRoutes(),
+	}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.Fatalf("Failed to start registry mirror: %v", err)
+		}
+	}()
+
+	logrus.Infof("Registry mirror listening on %s:%d, caching in %s", registryHost, registryPort, mirror.Store().Dir)
+	logrus.Infof("Pull through it with: ollama pull --insecure <this-host>:%d/library/<model>", registryPort)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logrus.Info("Shutting down registry mirror...")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(ctx)
+}
+
+// WarmEntry is the stable schema for a model in `registry warm` output.
+type WarmEntry struct {
+	Model        string `json:"model" yaml:"model"`
+	SizeBytes    int64  `json:"size_bytes" yaml:"size_bytes"`
+	FetchedBytes int64  `json:"fetched_bytes" yaml:"fetched_bytes"`
+}
+
+func runRegistryWarm(args []string) error {
+	mirror, err := registryMirror()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var entries []WarmEntry
+	for _, arg := range args {
+		name, err := ollama.ParseModelName(arg)
+		if err != nil {
+			return err
+		}
+		entry := WarmEntry{Model: name.String()}
+		manifest, err := mirror.Warm(ctx, name, func(blob ollama.Layer, cached bool) {
+			if cached {
+				return
+			}
+			entry.FetchedBytes += blob.Size
+			if !structuredOutput() {
+				logrus.Infof("%s: fetching %s (%s)", entry.Model, blob.Digest, system.FormatBytes(blob.Size))
+			}
+		})
+		if err != nil {
+			return err
+		}
+		entry.SizeBytes = manifest.Size()
+		entries = append(entries, entry)
+		if !structuredOutput() {
+			logrus.Infof("%s: cached (%s, %s fetched)", entry.Model, system.FormatBytes(entry.SizeBytes), system.FormatBytes(entry.FetchedBytes))
+		}
+	}
+
+	if structuredOutput() {
+		return printStructured(map[string][]WarmEntry{"models": entries})
+	}
+	return nil
+}
@@ -6,8 +6,9 @@ import (
 )
 
 var (
-	cfgFile string
-	verbose bool
+	cfgFile      string
+	verbose      bool
+	outputFormat string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -17,6 +18,9 @@ var rootCmd = &cobra.Command{
 	Long: `A comprehensive LLM management tool designed for AMD GPU homelab systems.
 Supports deployment, monitoring, and management of local language models
 using Docker and ROCm acceleration.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return validateOutputFormat()
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -30,6 +34,7 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.lite-llm.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputTable, "output format: table, json or yaml")
 
 	// Bind flags to viper
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
@@ -52,8 +57,35 @@ func initConfig() {
 	viper.SetDefault("ollama.port", 11434)
 	viper.SetDefault("webui.port", 3000)
 	viper.SetDefault("gpu.type", "amd")
-	viper.SetDefault("gpu.override_version", "10.3.0")
+	viper.SetDefault("gpu.gfx_target", "")
+	viper.SetDefault("gpu.override_version", "")
 	viper.SetDefault("models.default", []string{"llama3.1:8b", "mistral:7b"})
+	viper.SetDefault("models.dir", "/var/lib/docker/volumes")
+	viper.SetDefault("docker.socket", "/var/run/docker.sock")
+	viper.SetDefault("stack.name", "llm-stack")
+	viper.SetDefault("stack.dir", "~/.lite-llm/stacks")
+	viper.SetDefault("ollama.models_dir", "")
+	viper.SetDefault("webui.data_dir", "")
+	viper.SetDefault("backup.dir", "~/.lite-llm/backups")
+	viper.SetDefault("usage.dir", "~/.lite-llm/usage")
+	viper.SetDefault("library.url", "https://ollama.com")
+	viper.SetDefault("library.registry_url", "https://registry.ollama.ai")
+	viper.SetDefault("registry.dir", "~/.lite-llm/registry")
+	viper.SetDefault("registry.upstream", "https://registry.ollama.ai")
+	viper.SetDefault("auth.enabled", true)
+	viper.SetDefault("auth.keys_file", "~/.lite-llm/keys.json")
+	viper.SetDefault("limits.requests_per_minute", 60)
+	viper.SetDefault("limits.concurrent", 2)
+	viper.SetDefault("limits.daily_tokens", 0)
+	viper.SetDefault("metrics.interval", "5s")
+	viper.SetDefault("metrics.retention", "1h")
+	viper.SetDefault("metrics.history_file", "")
+	viper.SetDefault("portainer.url", "")
+	viper.SetDefault("portainer.endpoint_id", 1)
+	viper.SetDefault("portainer.insecure", false)
+	viper.BindEnv("portainer.url", "PORTAINER_URL")
+	viper.BindEnv("portainer.api_key", "PORTAINER_API_KEY")
+	viper.BindEnv("serve.api_key", "LITE_LLM_API_KEY")
 
 	if err := viper.ReadInConfig(); err == nil {
 		// Config file found and successfully parsed
@@ -3,15 +3,20 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/lyleclassen/lite-llm/internal/docker"
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/lyleclassen/lite-llm/internal/usage"
 	"github.com/lyleclassen/lite-llm/internal/web"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var serveCmd = &cobra.Command{
@@ -25,8 +30,9 @@ with your LLM models. This serves as an alternative to Open WebUI.`,
 }
 
 var (
-	port int
-	host string
+	port      int
+	host      string
+	ollamaURL string
 )
 
 func init() {
@@ -34,19 +40,70 @@ func init() {
 	
 	serveCmd.Flags().IntVarP(&port, "port", "p", 8080, "Port to serve on")
 	serveCmd.Flags().StringVar(&host, "host", "0.0.0.0", "Host to bind to")
+	serveCmd.Flags().StringVar(&ollamaURL, "ollama-url", "", "Ollama API URL (default: http://localhost:<ollama.port>)")
 }
 
 func runServe() error {
 	logrus.Infof("Starting lite-llm web server on %s:%d", host, port)
 
 	// Create web server
-	server := web.NewServer("http://localhost:11434")
+	if ollamaURL == "" {
+		ollamaURL = localOllamaURL()
+	}
+	server := web.NewServer(ollamaURL)
+	server.SetDocker(docker.NewClient(viper.GetString("docker.socket")), viper.GetString("stack.name"))
+	if err := server.SetTrustedProxies(viper.GetStringSlice("serve.trusted_proxies")); err != nil {
+		return err
+	}
+
+	if viper.GetBool("auth.enabled") {
+		keys, err := openKeyStore()
+		if err != nil {
+			return err
+		}
+		if err := bootstrapAdminKey(keys); err != nil {
+			return err
+		}
+		server.SetAuth(keys)
+		logrus.Infof("API keys required (keys in %s)", keys.Path())
+	} else {
+		logrus.Warn("Authentication is disabled: anyone who can reach the server can use it")
+	}
+
+	if err := setupLimits(server); err != nil {
+		return err
+	}
+
+	// Start background metrics sampling and alerting
+	samplerCtx, stopSampler := context.WithCancel(context.Background())
+	defer stopSampler()
+
+	history, samplerDone := startSampler(samplerCtx)
+	server.SetMetricsHistory(history)
+
+	engine, err := startAlerting(samplerCtx, history, ollamaURL)
+	if err != nil {
+		return err
+	}
+	if engine != nil {
+		logrus.Infof("Alerting enabled with %d rule(s)", len(engine.Rules()))
+	}
+
+	lastUsed, usageDone := startUsageTracking(samplerCtx, ollamaURL)
+	server.SetModelUsage(lastUsed)
+
 	router := server.SetupRoutes()
 
+	// Log streams never finish on their own; cancel them on shutdown.
+	requestCtx, stopRequests := context.WithCancel(context.Background())
+	defer stopRequests()
+
 	httpServer := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", host, port),
-		Handler: router,
+		Addr:        fmt.Sprintf("%s:%d", host, port),
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return requestCtx },
 	}
+	httpServer.RegisterOnShutdown(stopRequests)
 
 	// Start server in a goroutine
 	go func() {
@@ -66,6 +123,11 @@ func runServe() error {
 
 	logrus.Info("Shutting down server...")
 
+	// Stop sampling so the history is persisted before exit
+	stopSampler()
+	<-samplerDone
+	<-usageDone
+
 	// Give it 30 seconds to finish existing requests
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -77,4 +139,55 @@ func runServe() error {
 
 	logrus.Info("Server exited")
 	return nil
-}
\ No newline at end of file
+}
+
+// startUsageTracking loads the models' last-used times and keeps them
+// persisted for `models prune`.
+func startUsageTracking(ctx context.Context, ollamaURL string) (*usage.LastUsed, <-chan struct{}) {
+	lastUsed := usage.NewLastUsed(lastUsedPath())
+	if err := lastUsed.Load(); err != nil {
+		logrus.Warnf("Failed to load model usage: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		lastUsed.Run(ctx, ollama.NewClient(ollamaURL), time.Minute)
+		close(done)
+	}()
+	return lastUsed, done
+}
+
+// setupLimits records model requests to the usage ledger and applies the
+// per-client limits from config.
+func setupLimits(server *web.Server) error {
+	dir, err := usageDir()
+	if err != nil {
+		return err
+	}
+	ledger, err := usage.NewLedger(dir)
+	if err != nil {
+		return err
+	}
+	server.SetUsageLedger(ledger)
+
+	defaults := usage.Limits{
+		RequestsPerMinute: viper.GetInt("limits.requests_per_minute"),
+		Concurrent:        viper.GetInt("limits.concurrent"),
+		DailyTokens:       viper.GetInt("limits.daily_tokens"),
+	}
+	var overrides map[string]usage.Limits
+	if err := viper.UnmarshalKey("limits.clients", &overrides); err != nil {
+		return fmt.Errorf("invalid limits.clients: %w", err)
+	}
+	server.SetLimits(usage.NewLimiter(defaults, overrides, ledger))
+	logrus.Infof("Limits per client: %s requests/minute, %s concurrent, %s tokens/day",
+		limitString(defaults.RequestsPerMinute), limitString(defaults.Concurrent), limitString(defaults.DailyTokens))
+	return nil
+}
+
+func limitString(n int) string {
+	if n <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprint(n)
+}
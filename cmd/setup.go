@@ -24,6 +24,15 @@ var rocmScriptCmd = &cobra.Command{
 	},
 }
 
+var intelScriptCmd = &cobra.Command{
+	Use:   "intel",
+	Short: "Generate Intel GPU compute runtime installation script",
+	Long:  `Generate a bash script to install the Intel compute runtime (Level Zero and OpenCL) for Intel Arc and integrated GPUs.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGenerateIntelScript()
+	},
+}
+
 var dockerComposeCmd = &cobra.Command{
 	Use:   "docker-compose",
 	Short: "Generate docker-compose.yml for reference",
@@ -40,13 +49,29 @@ var (
 func init() {
 	rootCmd.AddCommand(setupCmd)
 	setupCmd.AddCommand(rocmScriptCmd)
+	setupCmd.AddCommand(intelScriptCmd)
 	setupCmd.AddCommand(dockerComposeCmd)
 	
 	setupCmd.PersistentFlags().StringVarP(&setupOutputDir, "output-dir", "d", ".", "Output directory for generated files")
+	setupCmd.PersistentFlags().StringVar(&gfxTarget, "gfx-target", "", "AMD GPU gfx target, e.g. gfx1031 (default: detect)")
+	setupCmd.PersistentFlags().StringVar(&hsaOverride, "hsa-override", "", "HSA_OVERRIDE_GFX_VERSION to set, or 'none' (default: from gfx target)")
+}
+
+// GeneratedFile is the structured output of the setup generators.
+type GeneratedFile struct {
+	Path string `json:"path" yaml:"path"`
+	Kind string `json:"kind" yaml:"kind"`
+}
+
+func printGeneratedFiles(files ...GeneratedFile) error {
+	return printStructured(map[string][]GeneratedFile{"files": files})
 }
 
 func runGenerateROCmScript() error {
-	script := templates.GenerateROCmSetupScript()
+	var config templates.StackConfig
+	config.GFXTarget, config.HSAOverrideGFXVersion = resolveROCmEnv()
+
+	script := templates.GenerateROCmSetupScript(config)
 	
 	filename := fmt.Sprintf("%s/setup-rocm.sh", setupOutputDir)
 	err := os.WriteFile(filename, []byte(script), 0755)
@@ -54,6 +79,10 @@ func runGenerateROCmScript() error {
 		return fmt.Errorf("failed to write ROCm setup script: %w", err)
 	}
 
+	if structuredOutput() {
+		return printGeneratedFiles(GeneratedFile{Path: filename, Kind: "rocm-setup-script"})
+	}
+
 	logrus.Infof("ROCm setup script generated: %s", filename)
 	logrus.Info("")
 	logrus.Info("To install ROCm on your system:")
@@ -67,27 +96,72 @@ func runGenerateROCmScript() error {
 	return nil
 }
 
+func runGenerateIntelScript() error {
+	script := templates.GenerateIntelSetupScript()
+
+	filename := fmt.Sprintf("%s/setup-intel.sh", setupOutputDir)
+	err := os.WriteFile(filename, []byte(script), 0755)
+	if err != nil {
+		return fmt.Errorf("failed to write Intel setup script: %w", err)
+	}
+
+	if structuredOutput() {
+		return printGeneratedFiles(GeneratedFile{Path: filename, Kind: "intel-setup-script"})
+	}
+
+	logrus.Infof("Intel compute runtime setup script generated: %s", filename)
+	logrus.Info("")
+	logrus.Info("To install the Intel compute runtime on your system:")
+	logrus.Infof("  chmod +x %s", filename)
+	logrus.Infof("  ./%s", filename)
+	logrus.Info("")
+	logrus.Info("After running the script, log out and back in and verify with:")
+	logrus.Info("  clinfo | grep 'Device Name'")
+
+	return nil
+}
+
 func runGenerateDockerCompose() error {
 	config := templates.StackConfig{
 		StackName:  "llm-stack",
 		OllamaPort: 11434,
 		WebUIPort:  3000,
 	}
+	config.GFXTarget, config.HSAOverrideGFXVersion = resolveROCmEnv()
+
+	compose, err := templates.GenerateDockerComposeForReference(config, templates.EnvSecrets)
+	if err != nil {
+		return fmt.Errorf("failed to generate docker-compose file: %w", err)
+	}
 
-	compose := templates.GenerateDockerComposeForReference(config)
-	
 	filename := fmt.Sprintf("%s/docker-compose.yml", setupOutputDir)
-	err := os.WriteFile(filename, []byte(compose), 0644)
+	err = os.WriteFile(filename, []byte(compose), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write docker-compose file: %w", err)
 	}
 
+	envFile, err := writeStackSecrets(setupOutputDir, "env", false)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printGeneratedFiles(
+			GeneratedFile{Path: filename, Kind: "docker-compose"},
+			GeneratedFile{Path: envFile, Kind: "secrets"},
+		)
+	}
+
 	logrus.Infof("Docker Compose file generated: %s", filename)
+	logrus.Infof("Secrets written to: %s", envFile)
 	logrus.Info("")
 	logrus.Info("This file is for reference only.")
 	logrus.Info("For Portainer deployment, use: lite-llm stack generate")
 	logrus.Info("")
-	logrus.Info("To use with docker-compose CLI:")
+	logrus.Info("To run it without Portainer or the compose CLI:")
+	logrus.Info("  lite-llm stack up -f " + filename)
+	logrus.Info("")
+	logrus.Info("Or with the docker-compose CLI:")
 	logrus.Info("  cd " + setupOutputDir)
 	logrus.Info("  docker-compose up -d")
 
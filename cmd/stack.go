@@ -3,10 +3,14 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/lyleclassen/lite-llm/internal/secrets"
+	"github.com/lyleclassen/lite-llm/internal/system"
 	"github.com/lyleclassen/lite-llm/internal/templates"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var stackCmd = &cobra.Command{
@@ -21,7 +25,30 @@ var generateStackCmd = &cobra.Command{
 	Long: `Generate a Portainer-compatible Docker Compose stack template optimized for 
 AMD GPU acceleration with Ollama and Open WebUI.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runGenerateStack()
+		return runGenerateStack(cmd)
+	},
+}
+
+var validateStackCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate a stack or compose file",
+	Long: `Check a stack file against the compose rules lite-llm relies on: known
+keys only, required images, port and duration syntax, restart policies, and
+that referenced services, volumes and networks are declared.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runValidateStack(args[0])
+	},
+}
+
+var rotateSecretsCmd = &cobra.Command{
+	Use:   "rotate-secrets",
+	Short: "Regenerate the stack's secrets",
+	Long: `Replace the secrets in the stack's .env file or secrets/ directory with new
+random values. Data volumes are not touched; redeploy the stack to apply the
+new values. Rotating WEBUI_SECRET_KEY signs every Open WebUI user out.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRotateSecrets()
 	},
 }
 
@@ -31,25 +58,92 @@ var (
 	ollamaPort int
 	webuiPort  int
 	gpuType    string
+
+	gfxTarget   string
+	hsaOverride string
+
+	proxyKind        string
+	proxyDomain      string
+	proxyTLS         string
+	proxyDNSProvider string
+	proxyACMEEmail   string
+	proxyServePort   int
+	proxyDirectPorts string
+	proxyImage       string
+
+	secretsMode       string
+	noAuth            bool
+	secretsDir        string
+	rotateSecretsMode string
+
+	stackFormat string
 )
 
 func init() {
 	rootCmd.AddCommand(stackCmd)
 	stackCmd.AddCommand(generateStackCmd)
+	stackCmd.AddCommand(validateStackCmd)
+	stackCmd.AddCommand(rotateSecretsCmd)
 	
-	generateStackCmd.Flags().StringVarP(&outputFile, "output", "o", "portainer-stack.yml", "Output file for the stack template")
+	generateStackCmd.Flags().StringVarP(&outputFile, "out", "o", "portainer-stack.yml", "File (or directory, for helm and quadlet) to write the stack template to")
 	generateStackCmd.Flags().StringVar(&stackName, "name", "llm-stack", "Stack name for Portainer")
 	generateStackCmd.Flags().IntVar(&ollamaPort, "ollama-port", 11434, "Port for Ollama service")
 	generateStackCmd.Flags().IntVar(&webuiPort, "webui-port", 3000, "Port for Open WebUI")
-	generateStackCmd.Flags().StringVar(&gpuType, "gpu", "amd", "GPU type: 'amd' or 'nvidia'")
+	generateStackCmd.Flags().StringVar(&gpuType, "gpu", "amd", "GPU type: 'amd', 'nvidia', 'intel' or 'cpu'")
+	generateStackCmd.Flags().StringVar(&gfxTarget, "gfx-target", "", "AMD GPU gfx target, e.g. gfx1031 (default: detect)")
+	generateStackCmd.Flags().StringVar(&hsaOverride, "hsa-override", "", "HSA_OVERRIDE_GFX_VERSION to set, or 'none' (default: from gfx target)")
+
+	generateStackCmd.Flags().StringVar(&proxyKind, "proxy", "", "Add a TLS reverse proxy: 'caddy' or 'traefik'")
+	generateStackCmd.Flags().StringVar(&proxyDomain, "domain", "home.lan", "Domain for proxy hostnames (chat., ollama., llm.<domain>)")
+	generateStackCmd.Flags().StringVar(&proxyTLS, "tls", "internal", "Proxy certificates: 'internal' (local CA/self-signed) or 'dns' (ACME DNS-01)")
+	generateStackCmd.Flags().StringVar(&proxyDNSProvider, "dns-provider", "", "DNS provider for --tls dns, e.g. cloudflare")
+	generateStackCmd.Flags().StringVar(&proxyACMEEmail, "acme-email", "", "ACME account email for --tls dns")
+	generateStackCmd.Flags().IntVar(&proxyServePort, "serve-port", 8080, "Host port of 'lite-llm serve' to route llm.<domain> to (0 to skip)")
+	generateStackCmd.Flags().StringVar(&proxyDirectPorts, "direct-ports", "localhost", "With --proxy, bind service ports to 'localhost' or expose 'none'")
+	generateStackCmd.Flags().StringVar(&proxyImage, "proxy-image", "", "Override the proxy image (e.g. a Caddy build with a DNS module)")
+	generateStackCmd.Flags().StringVar(&secretsMode, "secrets", "env", "Where to store generated secrets: 'env' (.env file) or 'docker' (Docker secrets)")
+	generateStackCmd.Flags().BoolVar(&noAuth, "no-auth", false, "Disable Open WebUI authentication (not recommended)")
+	generateStackCmd.Flags().StringVar(&stackFormat, "format", "compose", "Output format: 'compose' (Portainer), 'k8s', 'helm', 'quadlet' (Podman units) or 'systemd' (lite-llm serve unit)")
+	addKubernetesFlags(generateStackCmd)
+	addPodmanFlags(generateStackCmd)
+
+	rotateSecretsCmd.Flags().StringVarP(&secretsDir, "dir", "d", ".", "Directory containing the stack file and its secrets")
+	rotateSecretsCmd.Flags().StringVar(&rotateSecretsMode, "secrets", "", "Secret storage to rotate: 'env' or 'docker' (default: detect)")
 }
 
-func runGenerateStack() error {
-	logrus.Info("Generating Portainer stack template...")
+func runGenerateStack(cmd *cobra.Command) error {
+	if stackFormat == "compose" {
+		logrus.Info("Generating Portainer stack template...")
+	} else {
+		logrus.Infof("Generating %s stack...", stackFormat)
+	}
 
 	// Validate GPU type
-	if gpuType != "amd" && gpuType != "nvidia" {
-		return fmt.Errorf("invalid GPU type: %s. Must be 'amd' or 'nvidia'", gpuType)
+	if gpuType != "amd" && gpuType != "nvidia" && gpuType != "intel" && gpuType != "cpu" {
+		return fmt.Errorf("invalid GPU type: %s. Must be 'amd', 'nvidia', 'intel' or 'cpu'", gpuType)
+	}
+
+	switch stackFormat {
+	case "compose":
+	case "k8s", "helm":
+		if proxyKind != "" {
+			return fmt.Errorf("--proxy is not supported with --format %s; use --domain for an Ingress", stackFormat)
+		}
+		if secretsMode != "env" {
+			return fmt.Errorf("--secrets %s is not supported with --format %s", secretsMode, stackFormat)
+		}
+	case "quadlet", "systemd":
+		if proxyKind != "" {
+			return fmt.Errorf("--proxy is not supported with --format %s", stackFormat)
+		}
+		// Quadlet passes the stack secrets as Podman env secrets; the
+		// Docker secrets entrypoint would overwrite them from files that
+		// don't exist.
+		if secretsMode != "env" {
+			return fmt.Errorf("--secrets %s is not supported with --format %s", secretsMode, stackFormat)
+		}
+	default:
+		return fmt.Errorf("invalid format: %s. Must be 'compose', 'k8s', 'helm', 'quadlet' or 'systemd'", stackFormat)
 	}
 
 	config := templates.StackConfig{
@@ -58,8 +152,51 @@ func runGenerateStack() error {
 		WebUIPort:  webuiPort,
 		GPUType:    gpuType,
 	}
+	switch gpuType {
+	case "amd":
+		config.GFXTarget, config.HSAOverrideGFXVersion = resolveROCmEnv()
+	case "cpu":
+		config.CPUSet = resolveCPUSet()
+	}
 
-	template, err := templates.GeneratePortainerStack(config)
+	var features []templates.Feature
+	switch secretsMode {
+	case "env":
+		features = append(features, templates.EnvSecrets)
+	case "docker":
+		features = append(features, templates.DockerSecrets)
+	default:
+		return fmt.Errorf("invalid secrets mode: %s. Must be 'env' or 'docker'", secretsMode)
+	}
+	if noAuth {
+		logrus.Warn("Open WebUI authentication is disabled; anyone who can reach it can use it")
+		features = append(features, templates.NoAuth)
+	}
+	if proxyKind != "" {
+		proxy := templates.ProxyConfig{
+			Kind:        proxyKind,
+			Domain:      proxyDomain,
+			TLS:         proxyTLS,
+			DNSProvider: proxyDNSProvider,
+			ACMEEmail:   proxyACMEEmail,
+			ServePort:   proxyServePort,
+			DirectPorts: proxyDirectPorts,
+			Image:       proxyImage,
+		}
+		if err := proxy.Validate(); err != nil {
+			return err
+		}
+		features = append(features, templates.ReverseProxy(proxy))
+	}
+
+	switch stackFormat {
+	case "k8s", "helm":
+		return runGenerateKubernetes(cmd, config, features)
+	case "quadlet", "systemd":
+		return runGeneratePodman(cmd, config, features)
+	}
+
+	template, err := templates.GeneratePortainerStack(config, features...)
 	if err != nil {
 		return fmt.Errorf("failed to generate stack template: %w", err)
 	}
@@ -70,16 +207,198 @@ func runGenerateStack() error {
 		return fmt.Errorf("failed to write template to file: %w", err)
 	}
 
+	secretsPath, err := writeStackSecrets(filepath.Dir(outputFile), secretsMode, false)
+	if err != nil {
+		return err
+	}
+
 	logrus.Infof("Stack template generated: %s", outputFile)
+	logrus.Infof("Secrets written to: %s (keep it private; do not commit it)", secretsPath)
 	logrus.Info("")
 	logrus.Info("To deploy in Portainer:")
 	logrus.Info("1. Go to Stacks > Add stack")
 	logrus.Info("2. Upload the generated file or copy/paste the content")
-	logrus.Info("3. Deploy the stack")
+	if secretsMode == "env" {
+		logrus.Infof("3. Under Environment variables, choose 'Load variables from .env file' and select %s", secretsPath)
+		logrus.Info("4. Deploy the stack")
+	} else {
+		logrus.Info("3. Deploy the stack")
+	}
+	logrus.Info("")
+	logrus.Infof("Or deploy it through the Portainer API: lite-llm stack deploy -f %s --name %s", outputFile, stackName)
+	if !noAuth {
+		logrus.Info("")
+		logrus.Info("The first account created in Open WebUI becomes the administrator.")
+	}
 	logrus.Info("")
 	logrus.Info("Services will be available at:")
+	if proxyKind != "" {
+		logrus.Infof("  - Open WebUI: https://chat.%s", proxyDomain)
+		logrus.Infof("  - Ollama API: https://ollama.%s", proxyDomain)
+		if proxyServePort > 0 {
+			logrus.Infof("  - Lite LLM UI: https://llm.%s (run 'lite-llm serve --port %d' on the host)", proxyDomain, proxyServePort)
+		}
+		return nil
+	}
 	logrus.Infof("  - Ollama API: http://localhost:%d", ollamaPort)
 	logrus.Infof("  - Open WebUI: http://localhost:%d", webuiPort)
 
 	return nil
-}
\ No newline at end of file
+}
+
+func runValidateStack(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read stack file: %w", err)
+	}
+
+	stack, err := templates.ParseCompose(data)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("%s is valid (%d services, %d volumes, %d networks)",
+		path, len(stack.Services), len(stack.Volumes), len(stack.Networks))
+	return nil
+}
+
+// writeStackSecrets creates any missing stack secrets in dir, or replaces
+// all of them when rotate is set, and returns the file or directory written.
+// mode "env" keeps them in dir/.env alongside any other variables; "docker"
+// writes one file per secret under dir/secrets.
+func writeStackSecrets(dir, mode string, rotate bool) (string, error) {
+	if mode == "docker" {
+		secretDir := filepath.Join(dir, templates.SecretsDir)
+		for _, name := range templates.StackSecrets {
+			path := filepath.Join(secretDir, templates.SecretFileName(name))
+			if _, err := os.Stat(path); err == nil && !rotate {
+				continue
+			}
+			value, err := secrets.Generate(32)
+			if err != nil {
+				return "", err
+			}
+			if err := secrets.WriteFile(path, []byte(value)); err != nil {
+				return "", err
+			}
+		}
+		return secretDir, nil
+	}
+
+	path := filepath.Join(dir, ".env")
+	env, err := secrets.LoadEnvFile(path)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range templates.StackSecrets {
+		if _, ok := env.Get(name); ok && !rotate {
+			continue
+		}
+		value, err := secrets.Generate(32)
+		if err != nil {
+			return "", err
+		}
+		env.Set(name, value)
+	}
+	if err := env.Save(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func runRotateSecrets() error {
+	mode := rotateSecretsMode
+	if mode == "" {
+		switch {
+		case fileExists(filepath.Join(secretsDir, templates.SecretsDir)):
+			mode = "docker"
+		case fileExists(filepath.Join(secretsDir, ".env")):
+			mode = "env"
+		default:
+			return fmt.Errorf("no .env file or %s/ directory in %s; run 'lite-llm stack generate' first", templates.SecretsDir, secretsDir)
+		}
+	}
+	if mode != "env" && mode != "docker" {
+		return fmt.Errorf("invalid secrets mode: %s. Must be 'env' or 'docker'", mode)
+	}
+
+	path, err := writeStackSecrets(secretsDir, mode, true)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Rotated %d secret(s) in %s", len(templates.StackSecrets), path)
+	logrus.Info("")
+	logrus.Info("Redeploy the stack to apply them; data volumes are kept:")
+	logrus.Info("  docker compose up -d --force-recreate open-webui")
+	if mode == "env" {
+		logrus.Info("  (Portainer: update the stack's environment variables from the new .env, then Update the stack)")
+	} else {
+		logrus.Info("  (Podman: recreate the secrets with 'podman secret create --replace', then restart the webui unit)")
+	}
+	logrus.Info("Do not use 'docker compose down -v', which deletes the volumes.")
+	logrus.Info("Open WebUI users will need to sign in again.")
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// resolveROCmEnv determines the gfx target and HSA override for AMD
+// templates. Flags win over ~/.lite-llm.yaml (gpu.gfx_target,
+// gpu.override_version), which wins over detection and the support table.
+func resolveROCmEnv() (target, override string) {
+	target = gfxTarget
+	if target == "" {
+		target = viper.GetString("gpu.gfx_target")
+	}
+	if target == "" {
+		target = system.NewChecker().DetectGFXTarget()
+	}
+
+	env := system.ROCmEnvFor(target)
+	switch {
+	case target == "":
+		logrus.Warn("Could not detect the GPU gfx target; HSA_OVERRIDE_GFX_VERSION will not be set. Use --gfx-target to specify it.")
+	case !env.Known:
+		logrus.Warnf("GPU target %s is not in the support table; no HSA override will be applied", target)
+	case !env.Info.Supported && env.HSAOverrideGFXVersion == "":
+		logrus.Warnf("GPU target %s (%s) is not supported by ROCm: %s", target, env.Info.Cards, env.Info.Notes)
+	default:
+		logrus.Infof("GPU target: %s (%s)", target, env.Info.Cards)
+	}
+
+	override = hsaOverride
+	if override == "" {
+		override = viper.GetString("gpu.override_version")
+	}
+	if override == "" {
+		override = env.HSAOverrideGFXVersion
+	}
+	if override == "none" {
+		override = ""
+	}
+
+	return target, override
+}
+
+// resolveCPUSet logs CPU-only tuning advice and returns the cpuset to pin
+// Ollama to, if the host has more than one NUMA node.
+func resolveCPUSet() string {
+	cpu, err := system.NewChecker().GetCPUInfo()
+	if err != nil {
+		logrus.Warnf("Could not inspect CPU: %v", err)
+		return ""
+	}
+
+	if !cpu.AVX {
+		logrus.Warn("CPU does not support AVX; Ollama's CPU backend will not run on this host")
+	}
+	for _, rec := range cpu.Recommendations() {
+		logrus.Info(rec)
+	}
+
+	return cpu.PinnedCPUs()
+}
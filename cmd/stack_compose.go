@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/compose"
+	"github.com/lyleclassen/lite-llm/internal/docker"
+	"github.com/lyleclassen/lite-llm/internal/secrets"
+	"github.com/lyleclassen/lite-llm/internal/system"
+	"github.com/lyleclassen/lite-llm/internal/templates"
+	"github.com/lyleclassen/lite-llm/internal/tui"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var stackUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Create or update the stack with the Docker Engine API",
+	Long: `Run the stack without Portainer or the compose CLI. The compose file is
+kept in a managed project directory (stack.dir/<name>); -f copies a generated
+stack file (and the .env or secrets/ next to it) there, and without one a
+default stack for gpu.type is generated on first use.
+
+Images are pulled as needed, then containers are created in dependency order,
+waiting for healthchecks. Running 'up' again recreates only the containers
+whose configuration or image changed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStackUp()
+	},
+}
+
+var stackDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Stop and remove the stack's containers",
+	Long:  `Stop and remove the stack's containers and networks. Volumes (models, chats) are kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withProject(func(ctx context.Context, engine *compose.Engine, project *compose.Project) error {
+			if err := engine.Down(ctx); err != nil {
+				return err
+			}
+			logrus.Infof("Stack %s is down. Volumes were kept; start it again with 'lite-llm stack up'.", project.Name)
+			return nil
+		})
+	},
+}
+
+var stackRestartCmd = &cobra.Command{
+	Use:   "restart [service...]",
+	Short: "Restart the stack's containers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withProject(func(ctx context.Context, engine *compose.Engine, project *compose.Project) error {
+			return engine.Restart(ctx, args)
+		})
+	},
+}
+
+var stackPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Pull the latest images for the stack",
+	Long:  `Pull every image used by the stack. Running containers are not changed; use 'lite-llm stack upgrade' to pull and recreate them.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withProject(func(ctx context.Context, engine *compose.Engine, project *compose.Project) error {
+			updated, err := engine.Pull(ctx)
+			if err != nil {
+				return err
+			}
+			if len(updated) == 0 {
+				logrus.Info("All images are up to date")
+				return nil
+			}
+			logrus.Infof("Updated: %s", strings.Join(updated, ", "))
+			logrus.Info("Apply with 'lite-llm stack up'")
+			return nil
+		})
+	},
+}
+
+var stackUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Pull the latest images and recreate changed containers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withProject(func(ctx context.Context, engine *compose.Engine, project *compose.Project) error {
+			updated, err := engine.Pull(ctx)
+			if err != nil {
+				return err
+			}
+			if len(updated) == 0 {
+				logrus.Info("All images are up to date")
+			} else {
+				logrus.Infof("Updated: %s", strings.Join(updated, ", "))
+			}
+			if err := engine.Up(ctx); err != nil {
+				return err
+			}
+			logrus.Infof("Stack %s is up to date", project.Name)
+			return nil
+		})
+	},
+}
+
+var (
+	projectName   string
+	projectFile   string
+	healthTimeout time.Duration
+)
+
+func init() {
+	stackCmd.AddCommand(stackUpCmd)
+	stackCmd.AddCommand(stackDownCmd)
+	stackCmd.AddCommand(stackRestartCmd)
+	stackCmd.AddCommand(stackPullCmd)
+	stackCmd.AddCommand(stackUpgradeCmd)
+
+	for _, cmd := range []*cobra.Command{stackUpCmd, stackDownCmd, stackRestartCmd, stackPullCmd, stackUpgradeCmd} {
+		cmd.Flags().StringVar(&projectName, "name", "", "Stack name (default: stack.name from config)")
+	}
+	stackUpCmd.Flags().StringVarP(&projectFile, "file", "f", "", "Stack file to copy into the project directory")
+	for _, cmd := range []*cobra.Command{stackUpCmd, stackUpgradeCmd} {
+		cmd.Flags().DurationVar(&healthTimeout, "timeout", 5*time.Minute, "How long to wait for a service to become healthy")
+	}
+}
+
+// projectDir returns the managed directory of a stack.
+func projectDir(name string) (string, error) {
+	dir, err := expandHome(viper.GetString("stack.dir"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+func resolveProjectName() string {
+	if projectName != "" {
+		return projectName
+	}
+	return viper.GetString("stack.name")
+}
+
+// withProject loads the stack's project and runs fn with an engine for
+// it, cancelling on Ctrl+C.
+func withProject(fn func(ctx context.Context, engine *compose.Engine, project *compose.Project) error) error {
+	name := resolveProjectName()
+	dir, err := projectDir(name)
+	if err != nil {
+		return err
+	}
+	if !fileExists(filepath.Join(dir, compose.FileName)) {
+		return fmt.Errorf("stack %s has no project in %s; create it with 'lite-llm stack up'", name, dir)
+	}
+
+	project, err := compose.Load(name, dir)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	engine := compose.NewEngine(docker.NewClient(viper.GetString("docker.socket")), project)
+	engine.HealthTimeout = healthTimeout
+	engine.Progress = newPullProgress()
+	return fn(ctx, engine, project)
+}
+
+func runStackUp() error {
+	name := resolveProjectName()
+	dir, err := projectDir(name)
+	if err != nil {
+		return err
+	}
+	if err := materializeProject(name, dir); err != nil {
+		return err
+	}
+
+	return withProject(func(ctx context.Context, engine *compose.Engine, project *compose.Project) error {
+		logrus.Infof("Starting stack %s from %s", name, filepath.Join(dir, compose.FileName))
+		if err := engine.Up(ctx); err != nil {
+			return err
+		}
+
+		logrus.Info("")
+		logrus.Infof("Stack %s is up. Check it with 'lite-llm status --stack %s'.", name, name)
+		for _, service := range project.Services() {
+			for _, port := range project.File.Services[service].Ports {
+				parts := strings.Split(string(port), ":")
+				logrus.Infof("  - %s: http://localhost:%s", service, parts[len(parts)-2])
+			}
+		}
+		return nil
+	})
+}
+
+// materializeProject sets up the project directory: it copies --file and
+// its secrets in, or generates a default stack if there is none yet, then
+// fills in any missing secrets.
+func materializeProject(name, dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create project directory: %w", err)
+	}
+	target := filepath.Join(dir, compose.FileName)
+
+	switch {
+	case projectFile != "":
+		data, err := os.ReadFile(projectFile)
+		if err != nil {
+			return fmt.Errorf("failed to read stack file: %w", err)
+		}
+		if _, err := templates.ParseCompose(data); err != nil {
+			return err
+		}
+		if err := copyProjectSecrets(filepath.Dir(projectFile), dir); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("failed to write compose file: %w", err)
+		}
+		logrus.Infof("Copied %s to %s", projectFile, target)
+
+	case !fileExists(target):
+		config := templates.StackConfig{
+			StackName:  name,
+			OllamaPort: viper.GetInt("ollama.port"),
+			WebUIPort:  viper.GetInt("webui.port"),
+			GPUType:    viper.GetString("gpu.type"),
+		}
+		switch config.GPUType {
+		case "amd":
+			config.GFXTarget, config.HSAOverrideGFXVersion = resolveROCmEnv()
+		case "cpu":
+			config.CPUSet = resolveCPUSet()
+		}
+		data, err := templates.GenerateComposeProject(config, templates.EnvSecrets)
+		if err != nil {
+			return fmt.Errorf("failed to generate stack: %w", err)
+		}
+		if err := os.WriteFile(target, []byte(data), 0644); err != nil {
+			return fmt.Errorf("failed to write compose file: %w", err)
+		}
+		logrus.Infof("Generated a default %s stack in %s", config.GPUType, target)
+		logrus.Info("  (for other options: lite-llm stack generate -o stack.yml ... && lite-llm stack up -f stack.yml)")
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return fmt.Errorf("failed to read compose file: %w", err)
+	}
+	stack, err := templates.ParseCompose(data)
+	if err != nil {
+		return err
+	}
+	mode := "env"
+	if len(stack.Secrets) > 0 {
+		mode = "docker"
+	}
+	_, err = writeStackSecrets(dir, mode, false)
+	return err
+}
+
+// copyProjectSecrets merges the .env and secrets/ next to a stack file into
+// the project directory, so a stack keeps the secrets it was generated with.
+func copyProjectSecrets(from, to string) error {
+	source, err := secrets.LoadEnvFile(filepath.Join(from, ".env"))
+	if err != nil {
+		return err
+	}
+	if keys := source.Keys(); len(keys) > 0 {
+		path := filepath.Join(to, ".env")
+		target, err := secrets.LoadEnvFile(path)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			value, _ := source.Get(key)
+			target.Set(key, value)
+		}
+		if err := target.Save(path); err != nil {
+			return err
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(from, templates.SecretsDir))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read secrets: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(from, templates.SecretsDir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read secret: %w", err)
+		}
+		if err := secrets.WriteFile(filepath.Join(to, templates.SecretsDir, entry.Name()), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newPullProgress reports image pulls: a live byte count on a terminal and
+// the final status line otherwise.
+func newPullProgress() func(string, docker.PullProgress) {
+	interactive := tui.IsTerminal()
+	layers := map[string][2]int64{}
+	var last time.Time
+
+	return func(image string, progress docker.PullProgress) {
+		if strings.HasPrefix(progress.Status, "Status:") {
+			if interactive {
+				fmt.Fprint(os.Stderr, "\r\033[K")
+			}
+			logrus.Infof("%s: %s", image, strings.TrimSpace(strings.TrimPrefix(progress.Status, "Status:")))
+			layers = map[string][2]int64{}
+			return
+		}
+		if !interactive || progress.ID == "" || progress.ProgressDetail.Total == 0 || progress.Status != "Downloading" {
+			return
+		}
+
+		layers[progress.ID] = [2]int64{progress.ProgressDetail.Current, progress.ProgressDetail.Total}
+		if time.Since(last) < 200*time.Millisecond {
+			return
+		}
+		last = time.Now()
+
+		var current, total int64
+		for _, layer := range layers {
+			current += layer[0]
+			total += layer[1]
+		}
+		fmt.Fprintf(os.Stderr, "\r\033[KPulling %s: %s / %s (%d layers)", image,
+			system.FormatMemoryMB(int(current>>20)), system.FormatMemoryMB(int(total>>20)), len(layers))
+	}
+}
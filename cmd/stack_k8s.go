@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/lyleclassen/lite-llm/internal/templates"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	kubeNamespace     string
+	kubeStorageClass  string
+	kubeOllamaStorage string
+	kubeWebUIStorage  string
+	kubeIngressClass  string
+	kubeTLSSecret     string
+	kubeRuntimeClass  string
+	kubeNodeSelector  map[string]string
+	kubeTolerations   []string
+)
+
+func addKubernetesFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&kubeNamespace, "namespace", "", "k8s/helm: namespace for the manifests")
+	cmd.Flags().StringVar(&kubeStorageClass, "storage-class", "", "k8s/helm: storage class for the volumes (default: cluster default)")
+	cmd.Flags().StringVar(&kubeOllamaStorage, "ollama-storage", "50Gi", "k8s/helm: size of the Ollama model volume")
+	cmd.Flags().StringVar(&kubeWebUIStorage, "webui-storage", "5Gi", "k8s/helm: size of the Open WebUI data volume")
+	cmd.Flags().StringVar(&kubeIngressClass, "ingress-class", "", "k8s/helm: Ingress class (default: cluster default)")
+	cmd.Flags().StringVar(&kubeTLSSecret, "tls-secret", "", "k8s/helm: existing TLS secret for the Ingress")
+	cmd.Flags().StringVar(&kubeRuntimeClass, "runtime-class", "", "k8s/helm: Ollama runtimeClassName ('none' to unset; default: nvidia for --gpu nvidia)")
+	cmd.Flags().StringToStringVar(&kubeNodeSelector, "node-selector", nil, "k8s/helm: Ollama node selector, e.g. gpu=amd (repeatable)")
+	cmd.Flags().StringSliceVar(&kubeTolerations, "toleration", nil, "k8s/helm: extra Ollama toleration key[=value][:effect] (repeatable)")
+}
+
+func runGenerateKubernetes(cmd *cobra.Command, config templates.StackConfig, features []templates.Feature) error {
+	kube := templates.KubernetesConfig{
+		Namespace:     kubeNamespace,
+		IngressClass:  kubeIngressClass,
+		TLSSecret:     kubeTLSSecret,
+		StorageClass:  kubeStorageClass,
+		OllamaStorage: kubeOllamaStorage,
+		WebUIStorage:  kubeWebUIStorage,
+		NodeSelector:  kubeNodeSelector,
+		RuntimeClass:  kubeRuntimeClass,
+	}
+	// --domain defaults to home.lan for the proxy; only add an Ingress when
+	// it was asked for.
+	if cmd.Flags().Changed("domain") {
+		kube.Domain = proxyDomain
+	}
+	for _, t := range kubeTolerations {
+		toleration, err := templates.ParseToleration(t)
+		if err != nil {
+			return err
+		}
+		kube.Tolerations = append(kube.Tolerations, toleration)
+	}
+
+	output := outputFile
+	if !cmd.Flags().Changed("out") {
+		output = config.StackName + "-k8s.yml"
+		if stackFormat == "helm" {
+			output = config.StackName + "-chart"
+		}
+	}
+
+	if stackFormat == "helm" {
+		files, err := templates.GenerateHelmChart(config, kube, features...)
+		if err != nil {
+			return fmt.Errorf("failed to generate Helm chart: %w", err)
+		}
+		if err := writeChart(output, files); err != nil {
+			return err
+		}
+		logrus.Infof("Helm chart generated: %s", output)
+	} else {
+		manifests, err := templates.GenerateKubernetes(config, kube, features...)
+		if err != nil {
+			return fmt.Errorf("failed to generate Kubernetes manifests: %w", err)
+		}
+		if err := os.WriteFile(output, []byte(manifests), 0644); err != nil {
+			return fmt.Errorf("failed to write manifests to file: %w", err)
+		}
+		logrus.Infof("Kubernetes manifests generated: %s", output)
+	}
+
+	// Keep .env outside the chart directory so it is never packaged.
+	secretsPath, err := writeStackSecrets(filepath.Dir(filepath.Clean(output)), "env", false)
+	if err != nil {
+		return err
+	}
+	logrus.Infof("Secrets written to: %s (keep it private; do not commit it)", secretsPath)
+
+	ns := ""
+	if kubeNamespace != "" {
+		ns = " -n " + kubeNamespace
+	}
+	logrus.Info("")
+	logrus.Info("To deploy:")
+	if kubeNamespace != "" {
+		logrus.Infof("  kubectl create namespace %s", kubeNamespace)
+	}
+	logrus.Infof("  kubectl create secret generic %s%s --from-env-file=%s", kube.SecretName(config), ns, secretsPath)
+	if stackFormat == "helm" {
+		logrus.Infof("  helm install %s %s%s", config.StackName, output, ns)
+	} else {
+		logrus.Infof("  kubectl apply -f %s", output)
+	}
+	if kube.Domain != "" {
+		logrus.Info("")
+		logrus.Infof("Open WebUI will be served at chat.%s and the Ollama API at ollama.%s", kube.Domain, kube.Domain)
+	}
+	if config.GPUType != "cpu" {
+		logrus.Info("")
+		logrus.Infof("The cluster needs the %s GPU device plugin so nodes advertise GPU resources.", config.GPUType)
+	}
+	return nil
+}
+
+// writeChart writes the chart files under dir, creating subdirectories.
+func writeChart(dir string, files map[string]string) error {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		target := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create chart directory: %w", err)
+		}
+		if err := os.WriteFile(target, []byte(files[path]), 0644); err != nil {
+			return fmt.Errorf("failed to write chart file: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lyleclassen/lite-llm/internal/templates"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	podmanRootful bool
+	serveWorkDir  string
+)
+
+func addPodmanFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&podmanRootful, "rootful", false, "quadlet/systemd: generate system units run as root instead of user units")
+	cmd.Flags().StringVar(&serveWorkDir, "workdir", "", "systemd: lite-llm checkout or install directory holding web/ (default: current directory)")
+}
+
+func runGeneratePodman(cmd *cobra.Command, config templates.StackConfig, features []templates.Feature) error {
+	if stackFormat == "systemd" {
+		return runGenerateServeUnit(cmd, config)
+	}
+
+	quadlet := templates.QuadletConfig{Rootful: podmanRootful}
+
+	output := outputFile
+	if !cmd.Flags().Changed("out") {
+		output = config.StackName + "-quadlet"
+	}
+
+	files, err := templates.GenerateQuadlet(config, quadlet, features...)
+	if err != nil {
+		return fmt.Errorf("failed to generate Quadlet units: %w", err)
+	}
+	if err := writeChart(output, files); err != nil {
+		return err
+	}
+
+	// Podman secrets are created from files, so use the docker layout.
+	secretDir, err := writeStackSecrets(filepath.Dir(filepath.Clean(output)), "docker", false)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	logrus.Infof("Quadlet units generated in %s: %s", output, strings.Join(names, ", "))
+	logrus.Infof("Secrets written to: %s (keep it private; do not commit it)", secretDir)
+	logrus.Info("")
+	logrus.Info("To deploy with Podman:")
+	sudo, systemctl := "", "systemctl --user"
+	if podmanRootful {
+		sudo, systemctl = "sudo ", "sudo systemctl"
+	}
+	for _, secret := range templates.StackSecrets {
+		logrus.Infof("  %spodman secret create %s %s", sudo,
+			templates.PodmanSecretName(config, secret), filepath.Join(secretDir, templates.SecretFileName(secret)))
+	}
+	logrus.Infof("  %smkdir -p %s && %scp %s/* %s/", sudo, quadlet.UnitDir(), sudo, output, quadlet.UnitDir())
+	logrus.Infof("  %s daemon-reload", systemctl)
+	logrus.Infof("  %s start %s-webui.service", systemctl, config.StackName)
+	if !podmanRootful {
+		logrus.Info("  loginctl enable-linger $USER   # keep the stack running after logout")
+	}
+	logrus.Info("")
+	logrus.Infof("Enable image updates with: %s enable --now podman-auto-update.timer", systemctl)
+	logrus.Info("")
+	logrus.Info("Services will be available at:")
+	logrus.Infof("  - Ollama API: http://localhost:%d", ollamaPort)
+	logrus.Infof("  - Open WebUI: http://localhost:%d", webuiPort)
+	return nil
+}
+
+// runGenerateServeUnit writes a systemd unit running `lite-llm serve`
+// against the stack's Ollama port.
+func runGenerateServeUnit(cmd *cobra.Command, config templates.StackConfig) error {
+	binary, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate lite-llm binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+
+	workDir, err := serveAssetsDir(serveWorkDir)
+	if err != nil {
+		return err
+	}
+
+	output := outputFile
+	if !cmd.Flags().Changed("out") {
+		output = "lite-llm-serve.service"
+	}
+
+	unit := templates.GenerateServeUnit(config, templates.SystemdConfig{
+		Binary:    binary,
+		WorkDir:   workDir,
+		ServePort: proxyServePort,
+		Rootful:   podmanRootful,
+	})
+	if err := os.WriteFile(output, []byte(unit), 0644); err != nil {
+		return fmt.Errorf("failed to write unit file: %w", err)
+	}
+
+	unitDir, systemctl := "~/.config/systemd/user", "systemctl --user"
+	if podmanRootful {
+		unitDir, systemctl = "/etc/systemd/system", "sudo systemctl"
+	}
+
+	logrus.Infof("systemd unit generated: %s", output)
+	logrus.Info("")
+	logrus.Info("To install:")
+	if podmanRootful {
+		logrus.Infof("  sudo cp %s %s/", output, unitDir)
+	} else {
+		logrus.Infof("  mkdir -p %s && cp %s %s/", unitDir, output, unitDir)
+	}
+	logrus.Infof("  %s daemon-reload", systemctl)
+	logrus.Infof("  %s enable --now %s", systemctl, filepath.Base(output))
+	logrus.Info("")
+	logrus.Infof("Web interface: http://localhost:%d", proxyServePort)
+	return nil
+}
+
+// serveAssetsDir resolves the directory serve must run in to find its web
+// pages, the current directory if dir is empty.
+func serveAssetsDir(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(filepath.Join(dir, "web", "templates")); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s has no web/templates, which 'lite-llm serve' needs; pass --workdir with the lite-llm checkout or install directory", dir)
+	}
+	return dir, nil
+}
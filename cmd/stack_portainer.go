@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lyleclassen/lite-llm/internal/portainer"
+	"github.com/lyleclassen/lite-llm/internal/secrets"
+	"github.com/lyleclassen/lite-llm/internal/templates"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var deployStackCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Deploy a generated stack through the Portainer API",
+	Long: `Create a Portainer stack from a generated stack file, passing the variables
+from the .env file next to it. Configure Portainer in ~/.lite-llm.yaml
+(portainer.url, portainer.endpoint_id) and set PORTAINER_API_KEY to an
+access token.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDeployStack()
+	},
+}
+
+var updateStackCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update a deployed stack through the Portainer API",
+	Long: `Replace a Portainer stack's compose file and variables with the generated
+stack file and redeploy it. Volumes are kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUpdateStack()
+	},
+}
+
+var stackStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show a deployed stack and its containers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStackStatus()
+	},
+}
+
+var removeStackCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove a deployed stack through the Portainer API",
+	Long:  `Stop and remove a Portainer stack's containers and networks. Named volumes (models, chats) are kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRemoveStack()
+	},
+}
+
+var (
+	deployFile    string
+	deployName    string
+	deployEnvFile string
+	deployEnv     []string
+	deployNoPull  bool
+)
+
+func init() {
+	stackCmd.AddCommand(deployStackCmd)
+	stackCmd.AddCommand(updateStackCmd)
+	stackCmd.AddCommand(stackStatusCmd)
+	stackCmd.AddCommand(removeStackCmd)
+
+	for _, cmd := range []*cobra.Command{deployStackCmd, updateStackCmd, stackStatusCmd, removeStackCmd} {
+		cmd.Flags().StringVar(&deployName, "name", "llm-stack", "Portainer stack name")
+	}
+	for _, cmd := range []*cobra.Command{deployStackCmd, updateStackCmd} {
+		cmd.Flags().StringVarP(&deployFile, "file", "f", "portainer-stack.yml", "Stack file to deploy")
+		cmd.Flags().StringVar(&deployEnvFile, "env-file", "", "Variables to pass to the stack (default: .env next to the stack file)")
+		cmd.Flags().StringArrayVarP(&deployEnv, "env", "e", nil, "Extra stack variable KEY=VALUE (repeatable)")
+	}
+	updateStackCmd.Flags().BoolVar(&deployNoPull, "no-pull", false, "Don't pull newer images before redeploying")
+}
+
+// newPortainerClient builds a client from portainer.* config, with the
+// API key normally supplied as PORTAINER_API_KEY.
+func newPortainerClient() (*portainer.Client, error) {
+	url := viper.GetString("portainer.url")
+	if url == "" {
+		return nil, fmt.Errorf("portainer.url is not set; add it to ~/.lite-llm.yaml or set PORTAINER_URL")
+	}
+	apiKey := viper.GetString("portainer.api_key")
+	if apiKey == "" {
+		return nil, fmt.Errorf("no Portainer API key; create an access token under My account and set PORTAINER_API_KEY")
+	}
+	return portainer.NewClient(url, apiKey, viper.GetInt("portainer.endpoint_id"), viper.GetBool("portainer.insecure")), nil
+}
+
+// loadDeployment reads and validates the stack file and collects its
+// variables: existing ones (kept on update), then the env file, then --env.
+func loadDeployment(existing []portainer.EnvVar) (string, []portainer.EnvVar, error) {
+	data, err := os.ReadFile(deployFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read stack file (run 'lite-llm stack generate' first): %w", err)
+	}
+	stack, err := templates.ParseCompose(data)
+	if err != nil {
+		return "", nil, err
+	}
+	for name, secret := range stack.Secrets {
+		if secret.File != "" {
+			return "", nil, fmt.Errorf("secret %q is read from %s, which Portainer cannot see; regenerate with --secrets env", name, secret.File)
+		}
+	}
+
+	values := make(map[string]string)
+	for _, env := range existing {
+		values[env.Name] = env.Value
+	}
+
+	envFile := deployEnvFile
+	if envFile == "" {
+		envFile = filepath.Join(filepath.Dir(deployFile), ".env")
+	}
+	file, err := secrets.LoadEnvFile(envFile)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, key := range file.Keys() {
+		values[key], _ = file.Get(key)
+	}
+
+	for _, kv := range deployEnv {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return "", nil, fmt.Errorf("invalid --env %q: expected KEY=VALUE", kv)
+		}
+		values[key] = value
+	}
+
+	for _, name := range templates.StackSecrets {
+		if values[name] == "" && strings.Contains(string(data), "${"+name) {
+			return "", nil, fmt.Errorf("%s is not set; expected it in %s", name, envFile)
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	env := make([]portainer.EnvVar, len(keys))
+	for i, key := range keys {
+		env[i] = portainer.EnvVar{Name: key, Value: values[key]}
+	}
+	return string(data), env, nil
+}
+
+func runDeployStack() error {
+	client, err := newPortainerClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	existing, err := client.FindStack(ctx, deployName)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("stack %s already exists (id %d); use 'lite-llm stack update'", deployName, existing.ID)
+	}
+
+	content, env, err := loadDeployment(nil)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Deploying %s to Portainer (this pulls images and may take a while)...", deployName)
+	stack, err := client.CreateStack(ctx, deployName, content, env)
+	if err != nil {
+		return fmt.Errorf("failed to deploy stack: %w", err)
+	}
+
+	logrus.Infof("Stack %s deployed (id %d) with %d variable(s)", stack.Name, stack.ID, len(env))
+	return printStackStatus(ctx, client, stack)
+}
+
+func runUpdateStack() error {
+	client, err := newPortainerClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	existing, err := client.FindStack(ctx, deployName)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("stack %s not found; use 'lite-llm stack deploy'", deployName)
+	}
+
+	content, env, err := loadDeployment(existing.Env)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("Updating %s (id %d)...", deployName, existing.ID)
+	stack, err := client.UpdateStack(ctx, existing.ID, content, env, !deployNoPull)
+	if err != nil {
+		return fmt.Errorf("failed to update stack: %w", err)
+	}
+
+	logrus.Infof("Stack %s updated", stack.Name)
+	return printStackStatus(ctx, client, stack)
+}
+
+func runStackStatus() error {
+	client, err := newPortainerClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	stack, err := client.FindStack(ctx, deployName)
+	if err != nil {
+		return err
+	}
+	if stack == nil {
+		return fmt.Errorf("stack %s not found", deployName)
+	}
+	return printStackStatus(ctx, client, stack)
+}
+
+func runRemoveStack() error {
+	client, err := newPortainerClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	stack, err := client.FindStack(ctx, deployName)
+	if err != nil {
+		return err
+	}
+	if stack == nil {
+		return fmt.Errorf("stack %s not found", deployName)
+	}
+
+	if err := client.DeleteStack(ctx, stack.ID); err != nil {
+		return fmt.Errorf("failed to remove stack: %w", err)
+	}
+
+	logrus.Infof("Stack %s removed. Its volumes were kept; redeploy with 'lite-llm stack deploy'.", deployName)
+	return nil
+}
+
+// StackStatus is the structured output of `stack status`.
+type StackStatus struct {
+	Name       string            `json:"name" yaml:"name"`
+	ID         int               `json:"id" yaml:"id"`
+	Active     bool              `json:"active" yaml:"active"`
+	Containers []ContainerStatus `json:"containers" yaml:"containers"`
+}
+
+type ContainerStatus struct {
+	Name    string `json:"name" yaml:"name"`
+	Service string `json:"service" yaml:"service"`
+	Image   string `json:"image" yaml:"image"`
+	State   string `json:"state" yaml:"state"`
+	Status  string `json:"status" yaml:"status"`
+}
+
+func printStackStatus(ctx context.Context, client *portainer.Client, stack *portainer.Stack) error {
+	containers, err := client.StackContainers(ctx, stack.Name)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	status := StackStatus{Name: stack.Name, ID: stack.ID, Active: stack.Active()}
+	for _, c := range containers {
+		status.Containers = append(status.Containers, ContainerStatus{
+			Name:    c.Name(),
+			Service: c.Service(),
+			Image:   c.Image,
+			State:   c.State,
+			Status:  c.Status,
+		})
+	}
+	sort.Slice(status.Containers, func(i, j int) bool { return status.Containers[i].Name < status.Containers[j].Name })
+
+	if structuredOutput() {
+		return printStructured(status)
+	}
+
+	state := "inactive"
+	if status.Active {
+		state = "active"
+	}
+	logrus.Infof("Stack %s (id %d): %s", status.Name, status.ID, state)
+	if len(status.Containers) == 0 {
+		logrus.Info("  No containers")
+		return nil
+	}
+	for _, c := range status.Containers {
+		logrus.Infof("  %-24s %-12s %-10s %s", c.Name, c.Service, c.State, c.Status)
+	}
+	return nil
+}
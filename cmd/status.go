@@ -2,35 +2,49 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/lyleclassen/lite-llm/internal/docker"
 	"github.com/lyleclassen/lite-llm/internal/monitor"
 	"github.com/lyleclassen/lite-llm/internal/ollama"
 	"github.com/lyleclassen/lite-llm/internal/system"
+	"github.com/lyleclassen/lite-llm/internal/tui"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check system and service status",
-	Long:  `Get detailed status information about the LLM deployment, system health, and service availability.`,
+	Long: `Get detailed status information about the LLM deployment, system health, and service availability.
+
+Exit codes: 0 when all services are up, 2 when the Ollama API is down,
+3 when Ollama is up but another required service (Open WebUI) is down.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// An unhealthy status is reported through the exit code alone
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
 		return runStatus()
 	},
 }
 
 var (
-	watch    bool
-	interval int
+	watch       bool
+	interval    int
+	statusStack string
 )
 
 func init() {
 	rootCmd.AddCommand(statusCmd)
 	
-	statusCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Watch status continuously")
+	statusCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Watch status continuously (full-screen dashboard on a terminal)")
 	statusCmd.Flags().IntVarP(&interval, "interval", "i", 5, "Update interval in seconds (when watching)")
+	statusCmd.Flags().StringVar(&statusStack, "stack", "", "Stack whose containers to report (default: stack.name from config)")
 }
 
 func runStatus() error {
@@ -41,14 +55,48 @@ func runStatus() error {
 	return printStatus()
 }
 
+// runStatusStructuredWatch prints one JSON or YAML report per interval,
+// suitable for piping into a log collector.
+func runStatusStructuredWatch() error {
+	for {
+		report := collectStatus(context.Background())
+		if err := printStructured(report); err != nil {
+			return err
+		}
+		if outputFormat == outputYAML {
+			fmt.Println("---")
+		}
+
+		time.Sleep(time.Duration(interval) * time.Second)
+	}
+}
+
 func runStatusWatch() error {
+	if structuredOutput() {
+		return runStatusStructuredWatch()
+	}
+
+	// Use the full-screen dashboard when attached to a terminal; fall back
+	// to periodic plain output when piped or redirected.
+	if tui.IsTerminal() {
+		dashboard := tui.NewDashboard(tui.Config{
+			OllamaURL: "http://localhost:11434",
+			WebUIURL:  "http://localhost:3000",
+			ServeURL:  "http://localhost:8080",
+			ServeKey:  viper.GetString("serve.api_key"),
+			Interval:  time.Duration(interval) * time.Second,
+		}, system.NewDetector(system.NewChecker(), 5*time.Minute))
+		return dashboard.Run()
+	}
+
 	logrus.Infof("Watching status (updating every %d seconds, press Ctrl+C to stop)", interval)
 	
 	for {
 		// Clear screen (works on most terminals)
 		fmt.Print("\033[2J\033[H")
 		
-		if err := printStatus(); err != nil {
+		var exitErr *ExitError
+		if err := printStatus(); err != nil && !errors.As(err, &exitErr) {
 			logrus.Errorf("Error getting status: %v", err)
 		}
 		
@@ -56,58 +104,162 @@ func runStatusWatch() error {
 	}
 }
 
-func printStatus() error {
-	ctx := context.Background()
-	
-	logrus.Info("=== Lite LLM Status ===")
-	logrus.Infof("Timestamp: %s", time.Now().Format("2006-01-02 15:04:05"))
-	logrus.Info("")
+// StatusReport is the structured form of `lite-llm status`.
+type StatusReport struct {
+	Timestamp   time.Time                   `json:"timestamp" yaml:"timestamp"`
+	Healthy     bool                        `json:"healthy" yaml:"healthy"`
+	System      *system.SystemInfo          `json:"system" yaml:"system"`
+	Services    []ServiceStatus             `json:"services" yaml:"services"`
+	Stack       string                      `json:"stack" yaml:"stack"`
+	Containers  []docker.ContainerStatus    `json:"containers" yaml:"containers"`
+	DockerError string                      `json:"docker_error,omitempty" yaml:"docker_error,omitempty"`
+	Models      []ModelEntry                `json:"models" yaml:"models"`
+	Metrics     *monitor.PerformanceMetrics `json:"metrics" yaml:"metrics"`
+}
+
+// ServiceStatus describes one endpoint checked by `status`. Optional
+// services don't affect the exit code.
+type ServiceStatus struct {
+	Name     string `json:"name" yaml:"name"`
+	URL      string `json:"url" yaml:"url"`
+	Up       bool   `json:"up" yaml:"up"`
+	Optional bool   `json:"optional" yaml:"optional"`
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Exit codes returned by `status` so it can be used in healthchecks.
+const (
+	exitOllamaDown   = 2
+	exitServicesDown = 3
+)
+
+func collectStatus(ctx context.Context) *StatusReport {
+	report := &StatusReport{
+		Timestamp: time.Now(),
+		Models:    []ModelEntry{},
+	}
 
-	// System Information
-	logrus.Info("=== System Information ===")
 	checker := system.NewChecker()
+	checker.SetDockerSocket(viper.GetString("docker.socket"))
 	sysInfo, err := checker.GetSystemInfo()
 	if err != nil {
 		logrus.Errorf("Failed to get system info: %v", err)
 	} else {
+		report.System = sysInfo
+	}
+
+	ollamaStatus := ServiceStatus{Name: "ollama", URL: "http://localhost:11434"}
+	ollamaClient := ollama.NewClient(ollamaStatus.URL)
+	if err := ollamaClient.Health(ctx); err != nil {
+		ollamaStatus.Error = err.Error()
+	} else {
+		ollamaStatus.Up = true
+
+		models, err := ollamaClient.ListModels(ctx)
+		if err != nil {
+			ollamaStatus.Error = fmt.Sprintf("failed to list models: %v", err)
+		} else {
+			report.Models = modelEntries(models)
+		}
+	}
+
+	report.Services = []ServiceStatus{
+		ollamaStatus,
+		{Name: "open-webui", URL: "http://localhost:3000", Up: checkWebInterface("http://localhost:3000")},
+		{Name: "lite-llm-serve", URL: "http://localhost:8080", Up: checkWebInterface("http://localhost:8080"), Optional: true},
+	}
+
+	report.Stack = statusStack
+	if report.Stack == "" {
+		report.Stack = viper.GetString("stack.name")
+	}
+	report.Containers = []docker.ContainerStatus{}
+	containers, err := docker.NewClient(viper.GetString("docker.socket")).StackStatus(ctx, report.Stack)
+	if err != nil {
+		report.DockerError = err.Error()
+	} else {
+		report.Containers = containers
+	}
+
+	report.Metrics = monitor.GetPerformanceMetrics()
+
+	report.Healthy = true
+	for _, svc := range report.Services {
+		if !svc.Up && !svc.Optional {
+			report.Healthy = false
+		}
+	}
+
+	return report
+}
+
+// statusExitError maps an unhealthy report to a non-zero exit status.
+func statusExitError(report *StatusReport) error {
+	if report.Healthy {
+		return nil
+	}
+	if !report.Services[0].Up {
+		return &ExitError{Code: exitOllamaDown, Message: "Ollama API is down"}
+	}
+	return &ExitError{Code: exitServicesDown, Message: "one or more services are down"}
+}
+
+func printStatus() error {
+	report := collectStatus(context.Background())
+
+	if structuredOutput() {
+		if err := printStructured(report); err != nil {
+			return err
+		}
+		return statusExitError(report)
+	}
+
+	logrus.Info("=== Lite LLM Status ===")
+	logrus.Infof("Timestamp: %s", report.Timestamp.Format("2006-01-02 15:04:05"))
+	logrus.Info("")
+
+	// System Information
+	logrus.Info("=== System Information ===")
+	if sysInfo := report.System; sysInfo != nil {
 		logrus.Infof("Kernel: %s", sysInfo.KernelVersion)
 		logrus.Infof("Docker: %v", formatStatus(sysInfo.HasDocker))
 		logrus.Infof("AMD GPU: %v", formatStatus(sysInfo.HasAMDGPU))
 		if sysInfo.HasAMDGPU {
 			logrus.Infof("  Model: %s", sysInfo.GPUModel)
-			logrus.Infof("  Memory: %d MB", sysInfo.GPUMemory)
+			logrus.Infof("  Memory: %s", system.FormatMemoryMB(sysInfo.GPUMemory))
+		}
+		if sysInfo.HasIntelGPU {
+			logrus.Infof("Intel GPU: %v", formatStatus(sysInfo.HasIntelGPU))
+			if sysInfo.GPUType == "intel" {
+				logrus.Infof("  Model: %s", sysInfo.GPUModel)
+			}
 		}
 		logrus.Infof("ROCm: %v", formatStatus(sysInfo.HasROCm))
 		logrus.Infof("System Memory: %d MB", sysInfo.SystemMemory)
 	}
 	logrus.Info("")
 
-	// Container Services (via Portainer)
-	logrus.Info("=== Container Services ===")
-	logrus.Info("Note: Use Portainer to manage Docker containers")
-	logrus.Info("Expected containers: ollama-amd, open-webui")
+	// Container Services
+	logrus.Infof("=== Containers (%s) ===", report.Stack)
+	printContainers(report)
 	logrus.Info("")
 
 	// Ollama Service
 	logrus.Info("=== Ollama Service ===")
-	ollamaClient := ollama.NewClient("http://localhost:11434")
-	
-	err = ollamaClient.Health(ctx)
-	if err != nil {
+	ollamaStatus := report.Services[0]
+	if !ollamaStatus.Up {
 		logrus.Errorf("Ollama API: %v", formatStatus(false))
-		logrus.Errorf("  Error: %v", err)
+		logrus.Errorf("  Error: %v", ollamaStatus.Error)
 	} else {
 		logrus.Infof("Ollama API: %v", formatStatus(true))
-		logrus.Info("  Endpoint: http://localhost:11434")
-		
-		// Get models
-		models, err := ollamaClient.ListModels(ctx)
-		if err != nil {
-			logrus.Errorf("  Models: Failed to list (%v)", err)
+		logrus.Infof("  Endpoint: %s", ollamaStatus.URL)
+
+		if ollamaStatus.Error != "" {
+			logrus.Errorf("  Models: %s", ollamaStatus.Error)
 		} else {
-			logrus.Infof("  Models: %d installed", len(models))
-			for _, model := range models {
-				logrus.Infof("    - %s (%.1f GB)", model.Name, float64(model.Size)/(1024*1024*1024))
+			logrus.Infof("  Models: %d installed", len(report.Models))
+			for _, model := range report.Models {
+				logrus.Infof("    - %s (%.1f GB)", model.Name, float64(model.SizeBytes)/(1024*1024*1024))
 			}
 		}
 	}
@@ -115,25 +267,21 @@ func printStatus() error {
 
 	// Web Interfaces
 	logrus.Info("=== Web Interfaces ===")
-	
-	// Check Open WebUI
-	webUIStatus := checkWebInterface("http://localhost:3000")
-	logrus.Infof("Open WebUI: %v", formatStatus(webUIStatus))
-	if webUIStatus {
-		logrus.Info("  URL: http://localhost:3000")
-	}
-	
-	// Check custom web interface (if it's running)
-	customWebStatus := checkWebInterface("http://localhost:8080")
-	logrus.Infof("Custom Web: %v", formatStatus(customWebStatus))
-	if customWebStatus {
-		logrus.Info("  URL: http://localhost:8080")
+	for _, svc := range report.Services[1:] {
+		label := "Open WebUI"
+		if svc.Name == "lite-llm-serve" {
+			label = "Custom Web"
+		}
+		logrus.Infof("%s: %v", label, formatStatus(svc.Up))
+		if svc.Up {
+			logrus.Infof("  URL: %s", svc.URL)
+		}
 	}
 	logrus.Info("")
 
 	// Performance Metrics (if available)
 	logrus.Info("=== Performance Metrics ===")
-	metrics := monitor.GetPerformanceMetrics()
+	metrics := report.Metrics
 	if metrics != nil {
 		logrus.Infof("CPU Usage: %.1f%%", metrics.CPUUsage)
 		logrus.Infof("Memory Usage: %.1f%% (%d MB / %d MB)", 
@@ -147,7 +295,70 @@ func printStatus() error {
 		logrus.Info("Performance metrics unavailable")
 	}
 
-	return nil
+	return statusExitError(report)
+}
+
+func printContainers(report *StatusReport) {
+	if report.DockerError != "" {
+		logrus.Errorf("Docker: %s", report.DockerError)
+		return
+	}
+	if len(report.Containers) == 0 {
+		logrus.Warnf("No containers found for stack %s (deploy it with 'lite-llm stack deploy' or pass --stack)", report.Stack)
+		return
+	}
+
+	for _, c := range report.Containers {
+		state := c.State
+		if c.Health != "" {
+			state += " (" + c.Health + ")"
+		}
+		line := strings.TrimSpace(fmt.Sprintf("%-20s %-22s", c.Name, state))
+		if c.Running() {
+			line += fmt.Sprintf(" up %s", formatUptime(c.Uptime()))
+		}
+		if c.Restarts > 0 {
+			line += fmt.Sprintf(", %d restart(s)", c.Restarts)
+		}
+		if c.Running() && c.Health != "unhealthy" {
+			logrus.Info(line)
+		} else {
+			logrus.Warn(line)
+		}
+
+		logrus.Infof("  Image: %s %s", c.Image, shortDigest(c.ImageDigest))
+		if c.Running() {
+			logrus.Infof("  CPU: %.1f%%  Memory: %d MB / %d MB", c.CPUPercent, c.MemoryUsedMB, c.MemoryLimitMB)
+		}
+		if len(c.GPUDevices) > 0 {
+			logrus.Infof("  GPU devices: %s", strings.Join(c.GPUDevices, ", "))
+		} else if c.Service == "ollama" {
+			logrus.Warn("  GPU devices: none mapped, Ollama will run on the CPU")
+		}
+		if c.Error != "" {
+			logrus.Errorf("  Error: %s", c.Error)
+		}
+	}
+}
+
+// formatUptime rounds an uptime to the two most significant units.
+func formatUptime(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd%dh", int(d.Hours())/24, int(d.Hours())%24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return d.Truncate(time.Second).String()
+	}
+}
+
+// shortDigest abbreviates sha256:<hex> to its first 12 hex digits.
+func shortDigest(digest string) string {
+	if _, hex, ok := strings.Cut(digest, ":"); ok && len(hex) > 12 {
+		return "(" + hex[:12] + ")"
+	}
+	return digest
 }
 
 func formatStatus(status bool) string {
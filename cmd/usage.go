@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/usage"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report model usage recorded by the web server",
+}
+
+var usageReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize requests, tokens and latency per key or model",
+	Long: `Summarize the model requests 'lite-llm serve' has recorded in usage.dir:
+request and error counts, prompt and completion tokens, and average latency,
+grouped by API key (or client address without authentication) or by model.`,
+	Example: `  lite-llm usage report --by key --since 7d
+  lite-llm usage report --by model --since 24h --output json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUsageReport()
+	},
+}
+
+var (
+	usageBy    string
+	usageSince string
+)
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+	usageCmd.AddCommand(usageReportCmd)
+
+	usageReportCmd.Flags().StringVar(&usageBy, "by", "key", "Group by key or model")
+	usageReportCmd.Flags().StringVar(&usageSince, "since", "7d", "How far back to report, e.g. 7d or 12h")
+}
+
+// UsageReport is the stable schema for `usage report` output.
+type UsageReport struct {
+	Since  time.Time       `json:"since" yaml:"since"`
+	By     string          `json:"by" yaml:"by"`
+	Groups []usage.Summary `json:"groups" yaml:"groups"`
+}
+
+func runUsageReport() error {
+	age, err := parseAge(usageSince)
+	if err != nil {
+		return err
+	}
+	dir, err := usageDir()
+	if err != nil {
+		return err
+	}
+
+	since := time.Now().Add(-age)
+	records, err := usage.ReadRecords(dir, since)
+	if err != nil {
+		return err
+	}
+	summaries, err := usage.Summarize(records, usageBy)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		if summaries == nil {
+			summaries = []usage.Summary{}
+		}
+		return printStructured(UsageReport{Since: since, By: usageBy, Groups: summaries})
+	}
+
+	if len(summaries) == 0 {
+		logrus.Infof("No model requests recorded since %s", since.Format("2006-01-02 15:04"))
+		return nil
+	}
+	header := "KEY"
+	if usageBy == "model" {
+		header = "MODEL"
+	}
+	logrus.Infof("Usage since %s:", since.Format("2006-01-02 15:04"))
+	logrus.Infof("%-28s %9s %7s %12s %12s %12s", header, "REQUESTS", "ERRORS", "PROMPT TOK", "OUTPUT TOK", "AVG LATENCY")
+	var total usage.Summary
+	for _, s := range summaries {
+		logrus.Infof("%-28s %9d %7d %12d %12d %12s", s.Group, s.Requests, s.Errors, s.PromptTokens, s.CompletionTokens,
+			formatLatency(s.AvgLatencyMS))
+		total.Requests += s.Requests
+		total.Errors += s.Errors
+		total.PromptTokens += s.PromptTokens
+		total.CompletionTokens += s.CompletionTokens
+	}
+	logrus.Infof("%-28s %9d %7d %12d %12d", "TOTAL", total.Requests, total.Errors, total.PromptTokens, total.CompletionTokens)
+	return nil
+}
+
+func formatLatency(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.1fs", ms/1000)
+	}
+	return fmt.Sprintf("%.0fms", ms)
+}
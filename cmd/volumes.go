@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/docker"
+	"github.com/spf13/viper"
+)
+
+// expandHome expands a leading ~ in a configured path.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// resolveModelsDir returns Ollama's model store: ollama.models_dir, the
+// models directory in the stack's ollama_data volume, or ~/.ollama/models
+// for a native install.
+func resolveModelsDir() (string, error) {
+	home, _ := os.UserHomeDir()
+	dir, err := resolveDataDir("ollama.models_dir", "ollama_data", "models", filepath.Join(home, ".ollama", "models"))
+	if err != nil {
+		return "", fmt.Errorf("%w; set ollama.models_dir to Ollama's model directory", err)
+	}
+	return dir, nil
+}
+
+// resolveWebUIDir returns Open WebUI's data directory: webui.data_dir or
+// the stack's open_webui_data volume.
+func resolveWebUIDir() (string, error) {
+	dir, err := resolveDataDir("webui.data_dir", "open_webui_data", "", "")
+	if err != nil {
+		return "", fmt.Errorf("%w; set webui.data_dir to Open WebUI's data directory", err)
+	}
+	return dir, nil
+}
+
+// resolveDataDir looks up a stack volume's directory on the host. Reading
+// it usually needs root, as Docker keeps volumes under /var/lib/docker.
+func resolveDataDir(key, volume, sub, fallback string) (string, error) {
+	if dir := viper.GetString(key); dir != "" {
+		return expandHome(dir)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	name := viper.GetString("stack.name") + "_" + volume
+	v, err := docker.NewClient(viper.GetString("docker.socket")).InspectVolume(ctx, name)
+	if err == nil {
+		return filepath.Join(v.Mountpoint, sub), nil
+	}
+
+	if fallback != "" && fileExists(fallback) {
+		return fallback, nil
+	}
+	if docker.IsNotFound(err) {
+		return "", fmt.Errorf("no %s volume", name)
+	}
+	return "", fmt.Errorf("cannot find the %s volume: %w", name, err)
+}
@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scopes a key can hold. Admin grants every other scope.
+const (
+	ScopeChat  = "chat"
+	ScopeAdmin = "admin"
+)
+
+var Scopes = []string{ScopeChat, ScopeAdmin}
+
+// tokenPrefix marks lite-llm keys, so they are recognisable in configs and
+// secret scanners.
+const tokenPrefix = "llk_"
+
+var (
+	ErrInvalidKey = errors.New("invalid API key")
+	ErrExpiredKey = errors.New("API key has expired")
+	ErrRevokedKey = errors.New("API key has been revoked")
+)
+
+// Key is an API key as stored: only the SHA-256 of its secret is kept, so
+// the token itself is shown once, when the key is created.
+type Key struct {
+	ID        string     `json:"id" yaml:"id"`
+	Name      string     `json:"name" yaml:"name"`
+	Hash      string     `json:"hash" yaml:"-"`
+	Scopes    []string   `json:"scopes" yaml:"scopes"`
+	CreatedAt time.Time  `json:"created_at" yaml:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" yaml:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" yaml:"revoked_at,omitempty"`
+}
+
+// Allows reports whether the key grants scope.
+func (k *Key) Allows(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Status is "active", "expired" or "revoked".
+func (k *Key) Status(now time.Time) string {
+	switch {
+	case k.RevokedAt != nil:
+		return "revoked"
+	case k.ExpiresAt != nil && !now.Before(*k.ExpiresAt):
+		return "expired"
+	}
+	return "active"
+}
+
+// ParseScopes checks a comma-separated scope list.
+func ParseScopes(list string) ([]string, error) {
+	var scopes []string
+	for _, scope := range strings.Split(list, ",") {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		valid := false
+		for _, known := range Scopes {
+			valid = valid || scope == known
+		}
+		if !valid {
+			return nil, fmt.Errorf("unknown scope %q (valid: %s)", scope, strings.Join(Scopes, ", "))
+		}
+		scopes = append(scopes, scope)
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return scopes, nil
+}
+
+// KeyStore keeps API keys in a JSON file. `lite-llm keys` edits the file
+// while `serve` runs, so the server reloads it when it changes.
+type KeyStore struct {
+	mu      sync.Mutex
+	path    string
+	keys    []Key
+	modTime time.Time
+	size    int64
+}
+
+// OpenKeyStore loads the keys in path; a missing file is an empty store.
+func OpenKeyStore(path string) (*KeyStore, error) {
+	s := &KeyStore{path: path}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *KeyStore) Path() string {
+	return s.path
+}
+
+// reload re-reads the file if it changed since it was last read. The
+// caller holds s.mu, or owns s exclusively.
+func (s *KeyStore) reload() error {
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		s.keys, s.modTime, s.size = nil, time.Time{}, 0
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read API keys: %w", err)
+	}
+	if info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read API keys: %w", err)
+	}
+	var keys []Key
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("failed to decode API keys in %s: %w", s.path, err)
+	}
+	s.keys, s.modTime, s.size = keys, info.ModTime(), info.Size()
+	return nil
+}
+
+func (s *KeyStore) save() error {
+	data, err := json.MarshalIndent(s.keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write API keys: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime, s.size = info.ModTime(), info.Size()
+	}
+	return nil
+}
+
+// Create adds a key and returns it with its token. ttl of zero means the
+// key never expires.
+func (s *KeyStore) Create(name string, scopes []string, ttl time.Duration) (*Key, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, "", err
+	}
+	for _, k := range s.keys {
+		if k.Name == name && k.RevokedAt == nil {
+			return nil, "", fmt.Errorf("a key named %q already exists", name)
+		}
+	}
+
+	id, err := randomString(6)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomToken(24)
+	if err != nil {
+		return nil, "", err
+	}
+	key := Key{
+		ID:        id,
+		Name:      name,
+		Hash:      hashSecret(secret),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if ttl > 0 {
+		expires := key.CreatedAt.Add(ttl)
+		key.ExpiresAt = &expires
+	}
+	s.keys = append(s.keys, key)
+	if err := s.save(); err != nil {
+		return nil, "", err
+	}
+	return &key, tokenPrefix + id + "_" + secret, nil
+}
+
+// List returns the keys, oldest first.
+func (s *KeyStore) List() ([]Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	keys := append([]Key(nil), s.keys...)
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+// Revoke revokes the active key with the given ID or name.
+func (s *KeyStore) Revoke(idOrName string) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	for i := range s.keys {
+		k := &s.keys[i]
+		if (k.ID == idOrName || k.Name == idOrName) && k.RevokedAt == nil {
+			now := time.Now().UTC()
+			k.RevokedAt = &now
+			if err := s.save(); err != nil {
+				return nil, err
+			}
+			revoked := *k
+			return &revoked, nil
+		}
+	}
+	return nil, fmt.Errorf("no active key %q", idOrName)
+}
+
+// Empty reports whether no keys have been created yet.
+func (s *KeyStore) Empty() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return false, err
+	}
+	return len(s.keys) == 0, nil
+}
+
+// Authenticate returns the active key a token belongs to.
+func (s *KeyStore) Authenticate(token string) (*Key, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(token, tokenPrefix), "_")
+	if !ok || !strings.HasPrefix(token, tokenPrefix) {
+		return nil, ErrInvalidKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	for _, k := range s.keys {
+		if k.ID != id {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(k.Hash), []byte(hashSecret(secret))) != 1 {
+			return nil, ErrInvalidKey
+		}
+		switch k.Status(time.Now()) {
+		case "revoked":
+			return nil, ErrRevokedKey
+		case "expired":
+			return nil, ErrExpiredKey
+		}
+		key := k
+		return &key, nil
+	}
+	return nil, ErrInvalidKey
+}
+
+// Lookup returns the key with the given ID, or nil.
+func (s *KeyStore) Lookup(id string) *Key {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.reload(); err != nil {
+		return nil
+	}
+	for _, k := range s.keys {
+		if k.ID == id {
+			key := k
+			return &key
+		}
+	}
+	return nil
+}
+
+// Tokens are random and long, so a plain SHA-256 is enough; a slow
+// password hash would only add latency to every request.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuthenticate(t *testing.T) {
+	store, err := OpenKeyStore(filepath.Join(t.TempDir(), "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(name string, ttl time.Duration) (*Key, string) {
+		t.Helper()
+		key, token, err := store.Create(name, []string{ScopeChat}, ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key, token
+	}
+	alice, aliceToken := create("alice", 0)
+	_, expiredToken := create("old", time.Nanosecond)
+	revoked, revokedToken := create("bob", 0)
+	if _, err := store.Revoke(revoked.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	id, secret, _ := strings.Cut(strings.TrimPrefix(aliceToken, tokenPrefix), "_")
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"valid", aliceToken, nil},
+		{"wrong secret", tokenPrefix + id + "_" + strings.Repeat("A", len(secret)), ErrInvalidKey},
+		{"another key's secret", tokenPrefix + id + "_" + strings.SplitN(revokedToken, "_", 3)[2], ErrInvalidKey},
+		{"unknown id", tokenPrefix + "000000000000_" + secret, ErrInvalidKey},
+		{"missing prefix", strings.TrimPrefix(aliceToken, tokenPrefix), ErrInvalidKey},
+		{"no secret", tokenPrefix + id, ErrInvalidKey},
+		{"empty", "", ErrInvalidKey},
+		{"expired", expiredToken, ErrExpiredKey},
+		{"revoked", revokedToken, ErrRevokedKey},
+	}
+	for _, tt := range tests {
+		key, err := store.Authenticate(tt.token)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: Authenticate error = %v, want %v", tt.name, err, tt.want)
+			continue
+		}
+		if tt.want == nil && key.ID != alice.ID {
+			t.Errorf("%s: Authenticate = %s, want %s", tt.name, key.ID, alice.ID)
+		}
+		if tt.want != nil && key != nil {
+			t.Errorf("%s: Authenticate returned key %s with an error", tt.name, key.ID)
+		}
+	}
+}
+
+func TestKeyStoreReloads(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	server, err := OpenKeyStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// `lite-llm keys` edits the same file through its own store.
+	cli, err := OpenKeyStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, token, err := cli.Create("alice", []string{ScopeChat}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Authenticate(token); err != nil {
+		t.Fatalf("key created by another store: %v", err)
+	}
+	if _, err := cli.Revoke("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.Authenticate(token); !errors.Is(err, ErrRevokedKey) {
+		t.Errorf("key revoked by another store: %v, want ErrRevokedKey", err)
+	}
+}
+
+func TestAllows(t *testing.T) {
+	chat := &Key{Scopes: []string{ScopeChat}}
+	admin := &Key{Scopes: []string{ScopeAdmin}}
+	if !chat.Allows(ScopeChat) || chat.Allows(ScopeAdmin) {
+		t.Error("chat key scopes")
+	}
+	if !admin.Allows(ScopeChat) || !admin.Allows(ScopeAdmin) {
+		t.Error("admin key must grant every scope")
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	if scopes, err := ParseScopes(" chat, admin "); err != nil || len(scopes) != 2 {
+		t.Errorf("ParseScopes = %v, %v", scopes, err)
+	}
+	for _, list := range []string{"", " , ", "chat,models:manage", "root"} {
+		if _, err := ParseScopes(list); err == nil {
+			t.Errorf("ParseScopes(%q) succeeded", list)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// Sessions are browser logins made with an API key. They live in memory,
+// so restarting the server signs everyone out.
+type Sessions struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]session
+}
+
+type session struct {
+	keyID   string
+	expires time.Time
+}
+
+func NewSessions(ttl time.Duration) *Sessions {
+	return &Sessions{ttl: ttl, sessions: map[string]session{}}
+}
+
+// TTL is how long a session lasts.
+func (s *Sessions) TTL() time.Duration {
+	return s.ttl
+}
+
+// Start opens a session for a key and returns its ID.
+func (s *Sessions) Start(keyID string) (string, error) {
+	id, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for sid, sess := range s.sessions {
+		if now.After(sess.expires) {
+			delete(s.sessions, sid)
+		}
+	}
+	s.sessions[id] = session{keyID: keyID, expires: now.Add(s.ttl)}
+	return id, nil
+}
+
+// KeyID returns the key a session was opened with, or "" if the session
+// doesn't exist or has expired.
+func (s *Sessions) KeyID(id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok || time.Now().After(sess.expires) {
+		delete(s.sessions, id)
+		return ""
+	}
+	return sess.keyID
+}
+
+// End closes a session.
+func (s *Sessions) End(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
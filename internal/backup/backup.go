@@ -0,0 +1,567 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/lyleclassen/lite-llm/internal/system"
+	"github.com/sirupsen/logrus"
+)
+
+// Components that can be selected for a backup.
+const (
+	ComponentManifests = "manifests" // Ollama model manifests
+	ComponentBlobs     = "blobs"     // the model weights they reference
+	ComponentWebUI     = "webui"     // Open WebUI's data directory (users, chats, uploads)
+	ComponentLiteLLM   = "lite-llm"  // lite-llm's own config and state files
+)
+
+// Components lists every component in backup order.
+var Components = []string{ComponentManifests, ComponentBlobs, ComponentWebUI, ComponentLiteLLM}
+
+// Archive directories of each kind of content.
+const (
+	manifestsPrefix = "ollama/manifests/"
+	blobsPrefix     = "ollama/blobs/"
+	webUIPrefix     = "webui/"
+	liteLLMPrefix   = "lite-llm/"
+	indexFile       = "index.json"
+)
+
+// indexVersion is bumped when the archive layout changes.
+const indexVersion = 1
+
+// Index describes a backup. It is the last entry of the archive and is
+// also written next to it, with the archive's checksum, for listing.
+type Index struct {
+	Version    int       `json:"version"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"created_at"`
+	Host       string    `json:"host"`
+	Components []string  `json:"components"`
+	Models     []string  `json:"models,omitempty"`
+	Files      []File    `json:"files"`
+	Blobs      []Blob    `json:"blobs,omitempty"`
+	// SharedBlobs means blobs are kept in the repository's blob store
+	// instead of the archive, so backups share them.
+	SharedBlobs bool `json:"shared_blobs,omitempty"`
+
+	// Only in the index next to the archive.
+	ArchiveSize   int64  `json:"archive_size,omitempty"`
+	ArchiveSHA256 string `json:"archive_sha256,omitempty"`
+}
+
+// File is an archived file.
+type File struct {
+	Path   string      `json:"path"`
+	Size   int64       `json:"size"`
+	SHA256 string      `json:"sha256"`
+	Mode   fs.FileMode `json:"mode"`
+	// Source is where lite-llm files are restored to.
+	Source string `json:"source,omitempty"`
+}
+
+// Blob is a model blob referenced by the backup's manifests.
+type Blob struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+}
+
+// Has reports whether the backup includes a component.
+func (i *Index) Has(component string) bool {
+	for _, c := range i.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// BlobSize is the total size of the referenced blobs.
+func (i *Index) BlobSize() int64 {
+	var size int64
+	for _, blob := range i.Blobs {
+		size += blob.Size
+	}
+	return size
+}
+
+// ParseComponents validates a comma-separated component list.
+func ParseComponents(list string) ([]string, error) {
+	selected := map[string]bool{}
+	for _, c := range strings.Split(list, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if c == "all" {
+			return Components, nil
+		}
+		known := false
+		for _, k := range Components {
+			known = known || k == c
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown component %q (choose from %s)", c, strings.Join(Components, ", "))
+		}
+		selected[c] = true
+	}
+	if selected[ComponentBlobs] {
+		selected[ComponentManifests] = true // blobs are useless without them
+	}
+
+	var components []string
+	for _, c := range Components {
+		if selected[c] {
+			components = append(components, c)
+		}
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("no components selected")
+	}
+	return components, nil
+}
+
+// Sources are the directories and files a backup reads from, or a restore
+// writes to.
+type Sources struct {
+	ModelsDir string
+	WebUIDir  string
+	// LiteLLMPaths are files or directories; a restore puts them back at
+	// the paths recorded in the backup, and refuses any outside these.
+	LiteLLMPaths []string
+}
+
+// Quiesce is called before a component's data is read or written and
+// returns a function that resumes the service, e.g. pausing Open WebUI so
+// its database is consistent.
+type Quiesce func(component string) (resume func(), err error)
+
+// CreateOptions configure Repository.Create.
+type CreateOptions struct {
+	Name       string // defaults to the creation time
+	Components []string
+	Sources    Sources
+	Dedup      bool
+	Quiesce    Quiesce
+}
+
+// Repository is a directory of backups: <name>.tar.gz archives, their
+// <name>.json indexes and the shared blob store.
+type Repository struct {
+	Dir string
+}
+
+func NewRepository(dir string) *Repository {
+	return &Repository{Dir: dir}
+}
+
+func (r *Repository) ArchivePath(name string) string {
+	return filepath.Join(r.Dir, name+".tar.gz")
+}
+
+func (r *Repository) indexPath(name string) string {
+	return filepath.Join(r.Dir, name+".json")
+}
+
+// blobs is the shared blob store, laid out like Ollama's: <dir>/blobs.
+func (r *Repository) blobs() *ollama.Store {
+	return ollama.NewStore(r.Dir)
+}
+
+// List returns the repository's backups, oldest first.
+func (r *Repository) List() ([]Index, error) {
+	entries, err := os.ReadDir(r.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+
+	var backups []Index
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		if entry.IsDir() || name == entry.Name() {
+			continue
+		}
+		index, err := r.Index(name)
+		if err != nil {
+			logrus.Warnf("Skipping %s: %v", entry.Name(), err)
+			continue
+		}
+		backups = append(backups, *index)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.Before(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// Index reads the index stored next to a backup's archive.
+func (r *Repository) Index(name string) (*Index, error) {
+	data, err := os.ReadFile(r.indexPath(name))
+	if err != nil {
+		return nil, err
+	}
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid backup index: %w", err)
+	}
+	return &index, nil
+}
+
+// BlobStoreSize is the disk space used by the shared blob store.
+func (r *Repository) BlobStoreSize() (int64, error) {
+	blobs, err := r.blobs().Blobs()
+	var size int64
+	for _, blob := range blobs {
+		size += blob.Size
+	}
+	return size, err
+}
+
+// Create writes a new backup and returns its index.
+func (r *Repository) Create(opts CreateOptions) (*Index, error) {
+	name := opts.Name
+	if name == "" {
+		name = time.Now().Format("20060102-150405")
+	}
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid backup name %q", name)
+	}
+	if _, err := os.Stat(r.ArchivePath(name)); err == nil {
+		return nil, fmt.Errorf("backup %s already exists", name)
+	}
+	if err := os.MkdirAll(r.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	host, _ := os.Hostname()
+	index := &Index{
+		Version:    indexVersion,
+		Name:       name,
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		Host:       host,
+		Components: opts.Components,
+	}
+	index.SharedBlobs = opts.Dedup && index.Has(ComponentBlobs)
+
+	tmp := r.ArchivePath(name) + ".partial"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(tmp)
+	defer f.Close()
+
+	sum := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(f, sum)}
+	// Model weights don't compress; favour speed.
+	gz, _ := gzip.NewWriterLevel(counter, gzip.BestSpeed)
+	w := &archiveWriter{tw: tar.NewWriter(gz), index: index}
+
+	for _, component := range opts.Components {
+		if err := r.createComponent(w, component, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := w.writeBytes(indexFile, data); err != nil {
+		return nil, err
+	}
+	if err := w.tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	index.ArchiveSize = counter.n
+	index.ArchiveSHA256 = hex.EncodeToString(sum.Sum(nil))
+	data, err = json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(r.indexPath(name), data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write backup index: %w", err)
+	}
+	if err := os.Rename(tmp, r.ArchivePath(name)); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return index, nil
+}
+
+func (r *Repository) createComponent(w *archiveWriter, component string, opts CreateOptions) error {
+	if opts.Quiesce != nil {
+		resume, err := opts.Quiesce(component)
+		if err != nil {
+			return err
+		}
+		if resume != nil {
+			defer resume()
+		}
+	}
+
+	switch component {
+	case ComponentManifests:
+		store := ollama.NewStore(opts.Sources.ModelsDir)
+		models, err := store.Models()
+		if err != nil {
+			return err
+		}
+		for _, model := range models {
+			path := store.ManifestPath(model.Name)
+			if err := w.writeFile(manifestsPrefix+filepath.ToSlash(model.Name.Path()), path, ""); err != nil {
+				return err
+			}
+			w.index.Models = append(w.index.Models, model.Name.String())
+		}
+		logrus.Infof("Manifests: %d model(s)", len(models))
+
+	case ComponentBlobs:
+		return r.createBlobs(w, opts)
+
+	case ComponentWebUI:
+		if err := w.writeDir(webUIPrefix, opts.Sources.WebUIDir, false); err != nil {
+			return err
+		}
+		logrus.Infof("Open WebUI: %s", opts.Sources.WebUIDir)
+
+	case ComponentLiteLLM:
+		for _, source := range opts.Sources.LiteLLMPaths {
+			info, err := os.Stat(source)
+			if os.IsNotExist(err) {
+				continue
+			} else if err != nil {
+				return err
+			}
+			abs, err := filepath.Abs(source)
+			if err != nil {
+				return err
+			}
+			target := liteLLMPrefix + strings.TrimPrefix(filepath.ToSlash(abs), "/")
+			if info.IsDir() {
+				err = w.writeDir(target+"/", abs, true)
+			} else {
+				err = w.writeFile(target, abs, abs)
+			}
+			if err != nil {
+				return err
+			}
+			logrus.Infof("lite-llm: %s", abs)
+		}
+	}
+	return nil
+}
+
+// createBlobs archives the blobs the backed-up manifests reference, or with
+// Dedup copies the ones not yet in the blob store there.
+func (r *Repository) createBlobs(w *archiveWriter, opts CreateOptions) error {
+	source := ollama.NewStore(opts.Sources.ModelsDir)
+	models, err := source.Models()
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for _, model := range models {
+		for _, layer := range model.Manifest.Blobs() {
+			if !seen[layer.Digest] {
+				seen[layer.Digest] = true
+				w.index.Blobs = append(w.index.Blobs, Blob{Digest: layer.Digest, Size: layer.Size})
+			}
+		}
+	}
+	sort.Slice(w.index.Blobs, func(i, j int) bool { return w.index.Blobs[i].Digest < w.index.Blobs[j].Digest })
+
+	var copied, reused int
+	var copiedBytes int64
+	for _, blob := range w.index.Blobs {
+		from, err := source.BlobPath(blob.Digest)
+		if err != nil {
+			return err
+		}
+		if !opts.Dedup {
+			if err := w.writeBlob(blob, from); err != nil {
+				return err
+			}
+			copied++
+			copiedBytes += blob.Size
+			continue
+		}
+
+		to, _ := r.blobs().BlobPath(blob.Digest)
+		if info, err := os.Stat(to); err == nil && info.Size() == blob.Size {
+			reused++
+			continue
+		}
+		if err := copyBlob(from, to, blob.Digest); err != nil {
+			return err
+		}
+		copied++
+		copiedBytes += blob.Size
+	}
+
+	if opts.Dedup {
+		logrus.Infof("Blobs: %d new (%s), %d already in the blob store", copied, system.FormatBytes(copiedBytes), reused)
+	} else {
+		logrus.Infof("Blobs: %d (%s)", copied, system.FormatBytes(copiedBytes))
+	}
+	return nil
+}
+
+// copyBlob copies a blob into a store, verifying its digest on the way.
+func copyBlob(from, to, digest string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return fmt.Errorf("missing blob %s: %w", digest, err)
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(to), 0700); err != nil {
+		return err
+	}
+	tmp := to + "-partial"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	actual, _, err := ollama.DigestReader(io.TeeReader(src, dst))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy blob %s: %w", digest, err)
+	}
+	if actual != digest {
+		return fmt.Errorf("blob %s is corrupt (content hashes to %s)", digest, actual)
+	}
+	return os.Rename(tmp, to)
+}
+
+// archiveWriter writes tar entries and records them in the index.
+type archiveWriter struct {
+	tw    *tar.Writer
+	index *Index
+}
+
+func (w *archiveWriter) writeDir(prefix, dir string, recordSource bool) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil // directories are implied; sockets and links are skipped
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		source := ""
+		if recordSource {
+			source = path
+		}
+		return w.writeFile(prefix+filepath.ToSlash(rel), path, source)
+	})
+}
+
+func (w *archiveWriter) writeFile(name, path, source string) error {
+	file, err := w.copyFile(name, path)
+	if err != nil {
+		return err
+	}
+	file.Source = source
+	w.index.Files = append(w.index.Files, *file)
+	return nil
+}
+
+// writeBlob archives a blob, checking it against its digest.
+func (w *archiveWriter) writeBlob(blob Blob, path string) error {
+	file, err := w.copyFile(blobsPrefix+"sha256-"+strings.TrimPrefix(blob.Digest, "sha256:"), path)
+	if err != nil {
+		return fmt.Errorf("missing blob %s: %w", blob.Digest, err)
+	}
+	if "sha256:"+file.SHA256 != blob.Digest {
+		return fmt.Errorf("blob %s is corrupt (content hashes to sha256:%s)", blob.Digest, file.SHA256)
+	}
+	return nil
+}
+
+func (w *archiveWriter) copyFile(name, path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	header := &tar.Header{
+		Name:    name,
+		Mode:    int64(info.Mode().Perm()),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	sum := sha256.New()
+	if _, err := io.CopyN(io.MultiWriter(w.tw, sum), f, info.Size()); err != nil {
+		return nil, fmt.Errorf("failed to archive %s (did it change during the backup?): %w", path, err)
+	}
+	return &File{Path: name, Size: info.Size(), SHA256: hex.EncodeToString(sum.Sum(nil)), Mode: info.Mode().Perm()}, nil
+}
+
+func (w *archiveWriter) writeBytes(name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: time.Now()}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	_, err := w.tw.Write(data)
+	return err
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// cleanArchivePath rejects entries that would escape the restore
+// directory.
+func cleanArchivePath(name string) (string, error) {
+	clean := path.Clean("/" + name)[1:]
+	if clean == "" || clean != name {
+		return "", fmt.Errorf("unsafe path in archive: %q", name)
+	}
+	return clean, nil
+}
@@ -0,0 +1,414 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/sirupsen/logrus"
+)
+
+// RestoreOptions configure Repository.Restore.
+type RestoreOptions struct {
+	// Components to restore; empty restores everything in the backup.
+	Components []string
+	// Targets are the model store and Open WebUI directories to restore
+	// into. lite-llm files go back to the paths recorded in the backup,
+	// which must be among Targets.LiteLLMPaths.
+	Targets Sources
+	// VerifyOnly checks the backup without restoring anything.
+	VerifyOnly bool
+	Quiesce    Quiesce
+}
+
+// RestoreResult summarises a restore.
+type RestoreResult struct {
+	Index *Index `json:"index"`
+	Files int    `json:"files"`
+	Blobs int    `json:"blobs"`
+	// Models were restored; Skipped models lack blobs in the target store
+	// and need to be downloaded again.
+	Models  []string `json:"models,omitempty"`
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Restore verifies a backup and restores it. Nothing is written to the
+// targets unless every file and blob matches its checksum.
+func (r *Repository) Restore(name string, opts RestoreOptions) (*RestoreResult, error) {
+	archive := r.ArchivePath(name)
+	if _, err := os.Stat(archive); err != nil {
+		return nil, fmt.Errorf("backup %s not found in %s", name, r.Dir)
+	}
+	stored, err := r.Index(name)
+	if os.IsNotExist(err) {
+		logrus.Warnf("%s has no index next to it; only its contents can be verified", filepath.Base(archive))
+	} else if err != nil {
+		return nil, err
+	}
+
+	staging, err := os.MkdirTemp(r.Dir, ".restore-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	index, sums, archiveSum, err := extract(archive, staging)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil && stored.ArchiveSHA256 != "" && stored.ArchiveSHA256 != archiveSum {
+		return nil, fmt.Errorf("archive checksum mismatch: %s is corrupt", filepath.Base(archive))
+	}
+	if err := r.verify(index, sums); err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{Index: index}
+	if opts.VerifyOnly {
+		return result, nil
+	}
+
+	components := opts.Components
+	if len(components) == 0 {
+		components = index.Components
+	}
+	for _, component := range components {
+		if !index.Has(component) {
+			return nil, fmt.Errorf("backup %s does not include %s", name, component)
+		}
+	}
+	// Blobs go first so restored manifests can find them.
+	for _, component := range []string{ComponentBlobs, ComponentManifests, ComponentWebUI, ComponentLiteLLM} {
+		for _, selected := range components {
+			if selected == component {
+				if err := r.restoreComponent(component, index, staging, opts, result); err != nil {
+					return result, err
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// extract unpacks an archive into dir and returns its index, the checksum
+// of every entry and of the archive itself.
+func extract(archive, dir string) (*Index, map[string]string, string, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer f.Close()
+
+	archiveSum := sha256.New()
+	raw := io.TeeReader(f, archiveSum)
+	gz, err := gzip.NewReader(raw)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%s is not a backup archive: %w", filepath.Base(archive), err)
+	}
+	tr := tar.NewReader(gz)
+
+	var index *Index
+	sums := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, "", fmt.Errorf("corrupt archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name, err := cleanArchivePath(header.Name)
+		if err != nil {
+			return nil, nil, "", err
+		}
+
+		if name == indexFile {
+			index = &Index{}
+			if err := json.NewDecoder(tr).Decode(index); err != nil {
+				return nil, nil, "", fmt.Errorf("invalid backup index: %w", err)
+			}
+			continue
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, nil, "", err
+		}
+		out, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		sum := sha256.New()
+		_, err = io.Copy(io.MultiWriter(out, sum), tr)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("corrupt archive: %w", err)
+		}
+		sums[name] = hex.EncodeToString(sum.Sum(nil))
+	}
+	// Read to the end so the checksum covers the whole file.
+	if _, err := io.Copy(io.Discard, gz); err != nil {
+		return nil, nil, "", fmt.Errorf("corrupt archive: %w", err)
+	}
+	if _, err := io.Copy(io.Discard, raw); err != nil {
+		return nil, nil, "", err
+	}
+
+	if index == nil {
+		return nil, nil, "", fmt.Errorf("%s has no index; it is truncated or not a lite-llm backup", filepath.Base(archive))
+	}
+	if index.Version > indexVersion {
+		return nil, nil, "", fmt.Errorf("backup format %d is newer than this lite-llm supports (%d)", index.Version, indexVersion)
+	}
+	return index, sums, hex.EncodeToString(archiveSum.Sum(nil)), nil
+}
+
+// verify checks the extracted entries and the blobs against the index.
+func (r *Repository) verify(index *Index, sums map[string]string) error {
+	expected := map[string]bool{}
+	for _, file := range index.Files {
+		sum, ok := sums[file.Path]
+		if !ok {
+			return fmt.Errorf("%s is missing from the archive", file.Path)
+		}
+		if sum != file.SHA256 {
+			return fmt.Errorf("checksum mismatch for %s", file.Path)
+		}
+		expected[file.Path] = true
+	}
+
+	for _, blob := range index.Blobs {
+		if index.SharedBlobs {
+			if err := r.blobs().VerifyBlob(blob.Digest); os.IsNotExist(err) {
+				return fmt.Errorf("blob %s is missing from the blob store in %s", blob.Digest, r.Dir)
+			} else if err != nil {
+				return err
+			}
+			continue
+		}
+		name := blobsPrefix + "sha256-" + strings.TrimPrefix(blob.Digest, "sha256:")
+		sum, ok := sums[name]
+		if !ok {
+			return fmt.Errorf("blob %s is missing from the archive", blob.Digest)
+		}
+		if "sha256:"+sum != blob.Digest {
+			return fmt.Errorf("blob %s is corrupt", blob.Digest)
+		}
+		expected[name] = true
+	}
+
+	for name := range sums {
+		if !expected[name] {
+			return fmt.Errorf("unexpected file in archive: %s", name)
+		}
+	}
+	return nil
+}
+
+func (r *Repository) restoreComponent(component string, index *Index, staging string, opts RestoreOptions, result *RestoreResult) error {
+	if opts.Quiesce != nil {
+		resume, err := opts.Quiesce(component)
+		if err != nil {
+			return err
+		}
+		if resume != nil {
+			defer resume()
+		}
+	}
+
+	staged := func(name string) string {
+		return filepath.Join(staging, filepath.FromSlash(name))
+	}
+	target := ollama.NewStore(opts.Targets.ModelsDir)
+
+	switch component {
+	case ComponentBlobs:
+		for _, blob := range index.Blobs {
+			to, err := target.BlobPath(blob.Digest)
+			if err != nil {
+				return err
+			}
+			if info, err := os.Stat(to); err == nil && info.Size() == blob.Size {
+				continue
+			}
+			if index.SharedBlobs {
+				from, _ := r.blobs().BlobPath(blob.Digest)
+				err = installFile(from, to, 0644, false)
+			} else {
+				err = installFile(staged(blobsPrefix+"sha256-"+strings.TrimPrefix(blob.Digest, "sha256:")), to, 0644, true)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to restore blob %s: %w", blob.Digest, err)
+			}
+			result.Blobs++
+		}
+		logrus.Infof("Restored %d blob(s) to %s", result.Blobs, target.BlobsDir())
+
+	case ComponentManifests:
+		for _, file := range filesWithPrefix(index, manifestsPrefix) {
+			rel := strings.TrimPrefix(file.Path, manifestsPrefix)
+			parts := strings.Split(rel, "/")
+			if len(parts) != 4 {
+				return fmt.Errorf("unexpected manifest path %s", file.Path)
+			}
+			model := ollama.ModelName{Host: parts[0], Namespace: parts[1], Model: parts[2], Tag: parts[3]}
+
+			data, err := os.ReadFile(staged(file.Path))
+			if err != nil {
+				return err
+			}
+			var manifest ollama.Manifest
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return fmt.Errorf("invalid manifest for %s: %w", model, err)
+			}
+			if !hasBlobs(target, &manifest) {
+				result.Skipped = append(result.Skipped, model.String())
+				continue
+			}
+
+			if err := installFile(staged(file.Path), target.ManifestPath(model), 0644, true); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", model, err)
+			}
+			result.Models = append(result.Models, model.String())
+			result.Files++
+		}
+		logrus.Infof("Restored %d model manifest(s) to %s", len(result.Models), target.ManifestsDir())
+
+	case ComponentWebUI:
+		dir := opts.Targets.WebUIDir
+		// The data directory is replaced, not merged, so the database and
+		// uploads stay consistent with each other.
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, entry := range entries {
+			if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+				return fmt.Errorf("failed to clear %s: %w", dir, err)
+			}
+		}
+		files := filesWithPrefix(index, webUIPrefix)
+		for _, file := range files {
+			to := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(file.Path, webUIPrefix)))
+			if err := installFile(staged(file.Path), to, file.Mode, true); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", to, err)
+			}
+		}
+		result.Files += len(files)
+		logrus.Infof("Restored Open WebUI data (%d files) to %s", len(files), dir)
+
+	case ComponentLiteLLM:
+		files := filesWithPrefix(index, liteLLMPrefix)
+		// Sources come from the archive; check them all before writing so a
+		// crafted backup can't replace files outside lite-llm's state.
+		for _, file := range files {
+			if file.Source != "" && !withinPaths(file.Source, opts.Targets.LiteLLMPaths) {
+				return fmt.Errorf("backup restores %s, which is not one of lite-llm's state paths", file.Source)
+			}
+		}
+		for _, file := range files {
+			if file.Source == "" {
+				continue
+			}
+			if err := installFile(staged(file.Path), file.Source, file.Mode, true); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", file.Source, err)
+			}
+			result.Files++
+			logrus.Infof("Restored %s", file.Source)
+		}
+	}
+	return nil
+}
+
+// withinPaths reports whether path is absolute and is one of paths or
+// inside one of them.
+func withinPaths(path string, paths []string) bool {
+	if !filepath.IsAbs(path) {
+		return false
+	}
+	path = filepath.Clean(path)
+	for _, allowed := range paths {
+		allowed, err := filepath.Abs(allowed)
+		if err != nil {
+			continue
+		}
+		if path == allowed || strings.HasPrefix(path, allowed+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func filesWithPrefix(index *Index, prefix string) []File {
+	var files []File
+	for _, file := range index.Files {
+		if strings.HasPrefix(file.Path, prefix) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// hasBlobs reports whether every blob of a manifest is in a store.
+func hasBlobs(store *ollama.Store, manifest *ollama.Manifest) bool {
+	for _, blob := range manifest.Blobs() {
+		path, err := store.BlobPath(blob.Digest)
+		if err != nil {
+			return false
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() != blob.Size {
+			return false
+		}
+	}
+	return true
+}
+
+// installFile puts src at dst via a temporary file, so a failed restore
+// never leaves a truncated file behind. move renames src when it is on the
+// same filesystem instead of copying it.
+func installFile(src, dst string, mode os.FileMode, move bool) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if move {
+		if err := os.Chmod(src, mode); err != nil {
+			return err
+		}
+		if err := os.Rename(src, dst); err == nil {
+			return nil
+		}
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := dst + ".restoring"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
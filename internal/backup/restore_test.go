@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRestoreLiteLLMState(t *testing.T) {
+	state := t.TempDir()
+	config := filepath.Join(state, "lite-llm.yaml")
+	keys := filepath.Join(state, "keys.json")
+	usage := filepath.Join(state, "usage")
+	writeTestFile(t, config, "stack:\n  name: llm-stack\n")
+	writeTestFile(t, keys, `{"keys":[]}`)
+	writeTestFile(t, filepath.Join(usage, "requests-2026-10-18.jsonl"), "{}\n")
+
+	sources := Sources{LiteLLMPaths: []string{config, usage, keys}}
+	repo := NewRepository(t.TempDir())
+	if _, err := repo.Create(CreateOptions{Name: "state", Components: []string{ComponentLiteLLM}, Sources: sources}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	writeTestFile(t, config, "changed\n")
+	os.RemoveAll(usage)
+
+	result, err := repo.Restore("state", RestoreOptions{Targets: sources})
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if result.Files != 3 {
+		t.Errorf("restored %d files, want 3", result.Files)
+	}
+	if got := readTestFile(t, config); got != "stack:\n  name: llm-stack\n" {
+		t.Errorf("config = %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(usage, "requests-2026-10-18.jsonl")); got != "{}\n" {
+		t.Errorf("usage = %q", got)
+	}
+}
+
+// writeArchive builds a backup holding a lite-llm file that restores to
+// source, as a crafted or foreign backup could.
+func writeArchive(t *testing.T, repo *Repository, name, source, content string) {
+	t.Helper()
+	if err := os.MkdirAll(repo.Dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(repo.ArchivePath(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	add := func(name string, data []byte) {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	path := liteLLMPrefix + "payload"
+	add(path, []byte(content))
+	index, err := json.Marshal(Index{
+		Version:    indexVersion,
+		Name:       name,
+		Components: []string{ComponentLiteLLM},
+		Files: []File{{
+			Path:   path,
+			Size:   int64(len(content)),
+			SHA256: hex.EncodeToString(sum[:]),
+			Mode:   0600,
+			Source: source,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	add(indexFile, index)
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRestoreRejectsPathsOutsideState(t *testing.T) {
+	state := t.TempDir()
+	keys := filepath.Join(state, "keys.json")
+	usage := filepath.Join(state, "usage")
+	writeTestFile(t, keys, `{"keys":[]}`)
+
+	outside := t.TempDir()
+	victim := filepath.Join(outside, ".bashrc")
+	writeTestFile(t, victim, "original\n")
+
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"absolute path elsewhere", victim},
+		{"dot-dot out of a state directory", usage + "/../../" + filepath.Base(outside) + "/.bashrc"},
+		{"sibling with a shared prefix", usage + "-evil/requests.jsonl"},
+		{"relative path", "keys.json"},
+		{"parent of a state file", state},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewRepository(t.TempDir())
+			name := "crafted" + string(rune('a'+i))
+			writeArchive(t, repo, name, tt.source, "planted\n")
+
+			_, err := repo.Restore(name, RestoreOptions{Targets: Sources{LiteLLMPaths: []string{keys, usage}}})
+			if err == nil || !strings.Contains(err.Error(), "not one of lite-llm's state paths") {
+				t.Fatalf("Restore error = %v, want the source rejected", err)
+			}
+			if got := readTestFile(t, victim); got != "original\n" {
+				t.Errorf("%s was overwritten: %q", victim, got)
+			}
+			if got := readTestFile(t, keys); got != `{"keys":[]}` {
+				t.Errorf("%s was overwritten: %q", keys, got)
+			}
+		})
+	}
+}
+
+func TestWithinPaths(t *testing.T) {
+	allowed := []string{"/home/u/.lite-llm/keys.json", "/home/u/.lite-llm/usage/"}
+	tests := map[string]bool{
+		"/home/u/.lite-llm/keys.json":             true,
+		"/home/u/.lite-llm/usage":                 true,
+		"/home/u/.lite-llm/usage/requests.jsonl":  true,
+		"/home/u/.lite-llm/usage/../keys.json":    true,
+		"/home/u/.lite-llm/usage/../../.bashrc":   false,
+		"/home/u/.lite-llm/keys.json.bak":         false,
+		"/home/u/.lite-llm/usage2/requests.jsonl": false,
+		"/home/u/.lite-llm":                       false,
+		"home/u/.lite-llm/usage/requests.jsonl":   false,
+		"/etc/cron.d/lite-llm":                    false,
+	}
+	for path, want := range tests {
+		if got := withinPaths(path, allowed); got != want {
+			t.Errorf("withinPaths(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
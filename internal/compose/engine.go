@@ -0,0 +1,371 @@
+package compose
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/docker"
+	"github.com/sirupsen/logrus"
+)
+
+// Engine applies a project to the Docker daemon the way `docker compose`
+// does, without needing the compose CLI.
+type Engine struct {
+	client  *docker.Client
+	project *Project
+
+	// StopTimeout is how long containers get to exit before being killed.
+	StopTimeout time.Duration
+	// HealthTimeout bounds the wait for a dependency to become healthy.
+	HealthTimeout time.Duration
+	// Progress receives image pull progress, if set.
+	Progress func(image string, progress docker.PullProgress)
+}
+
+// NewEngine returns an engine for project.
+func NewEngine(client *docker.Client, project *Project) *Engine {
+	return &Engine{
+		client:        client,
+		project:       project,
+		StopTimeout:   30 * time.Second,
+		HealthTimeout: 5 * time.Minute,
+	}
+}
+
+// Images returns the project's images, sorted.
+func (e *Engine) Images() []string {
+	seen := map[string]bool{}
+	var images []string
+	for _, svc := range e.project.File.Services {
+		if !seen[svc.Image] {
+			seen[svc.Image] = true
+			images = append(images, svc.Image)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// Pull pulls every image of the project and returns those whose local
+// image changed.
+func (e *Engine) Pull(ctx context.Context) ([]string, error) {
+	var updated []string
+	for _, image := range e.Images() {
+		before, err := e.client.ImageID(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.pull(ctx, image); err != nil {
+			return nil, err
+		}
+		after, err := e.client.ImageID(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		if after != before {
+			updated = append(updated, image)
+		}
+	}
+	return updated, nil
+}
+
+func (e *Engine) pull(ctx context.Context, image string) error {
+	return e.client.PullImage(ctx, image, func(progress docker.PullProgress) {
+		if e.Progress != nil {
+			e.Progress(image, progress)
+		}
+	})
+}
+
+// containers returns the project's containers by service.
+func (e *Engine) containers(ctx context.Context) (map[string]docker.Container, error) {
+	list, err := e.client.ListContainers(ctx, true, map[string][]string{
+		"label": {LabelProject + "=" + e.project.Name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	byService := make(map[string]docker.Container, len(list))
+	for _, container := range list {
+		byService[container.Labels[LabelService]] = container
+	}
+	return byService, nil
+}
+
+// Up creates the project's networks and volumes and brings every service
+// up to date in dependency order: missing containers are created, changed
+// ones (configuration or image) recreated and stopped ones started.
+// Dependents wait for service_healthy dependencies to pass their
+// healthcheck.
+func (e *Engine) Up(ctx context.Context) error {
+	order, err := e.project.ServiceOrder(nil)
+	if err != nil {
+		return err
+	}
+
+	for _, network := range e.project.Networks() {
+		driver := "bridge"
+		if declared := e.project.File.Networks[network]; declared != nil && declared.Driver != "" {
+			driver = declared.Driver
+		}
+		if err := e.client.CreateNetwork(ctx, e.project.NetworkName(network), driver, map[string]string{
+			LabelProject: e.project.Name,
+			LabelNetwork: network,
+		}); err != nil {
+			return fmt.Errorf("failed to create network %s: %w", network, err)
+		}
+	}
+
+	volumes := make([]string, 0, len(e.project.File.Volumes))
+	for name := range e.project.File.Volumes {
+		volumes = append(volumes, name)
+	}
+	sort.Strings(volumes)
+	for _, name := range volumes {
+		driver := "local"
+		if volume := e.project.File.Volumes[name]; volume != nil && volume.Driver != "" {
+			driver = volume.Driver
+		}
+		if err := e.client.CreateVolume(ctx, e.project.VolumeName(name), driver, map[string]string{
+			LabelProject: e.project.Name,
+			LabelVolume:  name,
+		}); err != nil {
+			return fmt.Errorf("failed to create volume %s: %w", name, err)
+		}
+	}
+
+	for name, cfg := range e.project.File.Configs {
+		if cfg.Content == "" {
+			continue
+		}
+		path := e.project.ConfigPath(name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to write config %s: %w", name, err)
+		}
+		if err := os.WriteFile(path, []byte(cfg.Content), 0644); err != nil {
+			return fmt.Errorf("failed to write config %s: %w", name, err)
+		}
+	}
+
+	existing, err := e.containers(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[string]string, len(order))
+	for _, service := range order {
+		for dep, condition := range e.project.File.Services[service].DependsOn {
+			if err := e.waitFor(ctx, dep, ids[dep], condition.Condition); err != nil {
+				return err
+			}
+		}
+
+		id, err := e.upService(ctx, service, existing)
+		if err != nil {
+			return fmt.Errorf("service %s: %w", service, err)
+		}
+		ids[service] = id
+	}
+	return nil
+}
+
+// upService makes one service's container match the project and returns
+// its ID.
+func (e *Engine) upService(ctx context.Context, service string, existing map[string]docker.Container) (string, error) {
+	svc := e.project.File.Services[service]
+	imageID, err := e.client.ImageID(ctx, svc.Image)
+	if err != nil {
+		return "", err
+	}
+	if imageID == "" {
+		logrus.Infof("Pulling %s...", svc.Image)
+		if err := e.pull(ctx, svc.Image); err != nil {
+			return "", err
+		}
+		if imageID, err = e.client.ImageID(ctx, svc.Image); err != nil {
+			return "", err
+		}
+	}
+
+	config, err := e.project.ContainerConfig(service)
+	if err != nil {
+		return "", err
+	}
+	name := e.project.ContainerName(service)
+
+	if current, ok := existing[service]; ok {
+		switch {
+		case current.Labels[LabelConfigHash] != config.Labels[LabelConfigHash]:
+			logrus.Infof("Recreating %s (configuration changed)", name)
+		case current.ImageID != imageID:
+			logrus.Infof("Recreating %s (new image)", name)
+		case current.State == "running":
+			logrus.Infof("%s is up to date", name)
+			return current.ID, nil
+		default:
+			logrus.Infof("Starting %s", name)
+			return current.ID, e.client.StartContainer(ctx, current.ID)
+		}
+		if err := e.client.StopContainer(ctx, current.ID, int(e.StopTimeout.Seconds())); err != nil {
+			return "", err
+		}
+		if err := e.client.RemoveContainer(ctx, current.ID, true); err != nil {
+			return "", err
+		}
+	} else {
+		logrus.Infof("Creating %s", name)
+	}
+
+	// Like docker compose, create missing bind-mount directories.
+	for _, mount := range config.HostConfig.Mounts {
+		if mount.Type == "bind" && !mount.ReadOnly && !fileExists(mount.Source) {
+			if err := os.MkdirAll(mount.Source, 0755); err != nil {
+				return "", fmt.Errorf("failed to create %s: %w", mount.Source, err)
+			}
+		}
+	}
+
+	id, err := e.client.CreateContainer(ctx, name, config)
+	if err != nil {
+		return "", err
+	}
+	for _, network := range e.project.serviceNetworks(service)[1:] {
+		if err := e.client.ConnectNetwork(ctx, e.project.NetworkName(network), id, []string{service}); err != nil {
+			return "", err
+		}
+	}
+	if err := e.client.StartContainer(ctx, id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// waitFor blocks until a dependency meets its depends_on condition.
+func (e *Engine) waitFor(ctx context.Context, service, id, condition string) error {
+	if condition == "service_started" || id == "" {
+		return nil
+	}
+
+	name := e.project.ContainerName(service)
+	ctx, cancel := context.WithTimeout(ctx, e.HealthTimeout)
+	defer cancel()
+
+	logged := false
+	for {
+		details, err := e.client.InspectContainer(ctx, id)
+		if err != nil {
+			return fmt.Errorf("waiting for %s: %w", name, err)
+		}
+		state := details.State
+
+		switch condition {
+		case "service_healthy":
+			if !state.Running {
+				return fmt.Errorf("%s exited with code %d before becoming healthy (see 'lite-llm logs %s')", name, state.ExitCode, service)
+			}
+			if state.Health == nil || state.Health.Status == "healthy" {
+				return nil
+			}
+			if state.Health.Status == "unhealthy" {
+				return fmt.Errorf("%s is unhealthy (see 'lite-llm logs %s')", name, service)
+			}
+		case "service_completed_successfully":
+			if !state.Running && state.Status == "exited" {
+				if state.ExitCode != 0 {
+					return fmt.Errorf("%s exited with code %d", name, state.ExitCode)
+				}
+				return nil
+			}
+		}
+
+		if !logged {
+			logrus.Infof("Waiting for %s to be %s...", name, map[string]string{
+				"service_healthy":                "healthy",
+				"service_completed_successfully": "done",
+			}[condition])
+			logged = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s", name)
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// Down stops and removes the project's containers, dependents first, and
+// its networks. Volumes are kept.
+func (e *Engine) Down(ctx context.Context) error {
+	existing, err := e.containers(ctx)
+	if err != nil {
+		return err
+	}
+
+	order := e.project.Services()
+	for i := len(order) - 1; i >= 0; i-- {
+		if container, ok := existing[order[i]]; ok {
+			logrus.Infof("Removing %s", container.Name())
+			if err := e.client.StopContainer(ctx, container.ID, int(e.StopTimeout.Seconds())); err != nil {
+				return err
+			}
+			if err := e.client.RemoveContainer(ctx, container.ID, true); err != nil {
+				return err
+			}
+			delete(existing, order[i])
+		}
+	}
+	// Containers of services no longer in the compose file.
+	for _, container := range existing {
+		logrus.Infof("Removing orphan %s", container.Name())
+		if err := e.client.RemoveContainer(ctx, container.ID, true); err != nil {
+			return err
+		}
+	}
+
+	for _, network := range e.project.Networks() {
+		if err := e.client.RemoveNetwork(ctx, e.project.NetworkName(network)); err != nil {
+			return fmt.Errorf("failed to remove network %s: %w", network, err)
+		}
+	}
+	return nil
+}
+
+// Restart restarts the given services' containers (all when none are
+// given) in dependency order.
+func (e *Engine) Restart(ctx context.Context, services []string) error {
+	order, err := e.project.ServiceOrder(services)
+	if err != nil {
+		return err
+	}
+	existing, err := e.containers(ctx)
+	if err != nil {
+		return err
+	}
+
+	restarted := 0
+	for _, service := range order {
+		container, ok := existing[service]
+		if !ok {
+			continue
+		}
+		logrus.Infof("Restarting %s", container.Name())
+		if err := e.client.RestartContainer(ctx, container.ID, int(e.StopTimeout.Seconds())); err != nil {
+			return fmt.Errorf("service %s: %w", service, err)
+		}
+		restarted++
+	}
+	if restarted == 0 {
+		return fmt.Errorf("no containers to restart; run 'lite-llm stack up' first")
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
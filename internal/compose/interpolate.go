@@ -0,0 +1,152 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Interpolate substitutes variables in the values of a compose file the way
+// docker compose does: $VAR and ${VAR}, with ${VAR:-default},
+// ${VAR-default}, ${VAR:?error}, ${VAR?error}, ${VAR:+alt} and ${VAR+alt};
+// $$ is a literal $. Comments and keys are left alone.
+func Interpolate(data []byte, lookup func(string) (string, bool)) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid compose file: %w", err)
+	}
+	if err := interpolateNode(&doc, lookup); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(&doc)
+}
+
+func interpolateNode(node *yaml.Node, lookup func(string) (string, bool)) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		value, err := interpolateString(node.Value, lookup)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if value != node.Value {
+			// Let the value resolve afresh, so "${RETRIES}" can fill an int.
+			node.Value = value
+			node.Tag = ""
+			node.Style = 0
+		}
+	case yaml.MappingNode:
+		// Only values are interpolated.
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interpolateNode(node.Content[i], lookup); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, child := range node.Content {
+			if err := interpolateNode(child, lookup); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func interpolateString(s string, lookup func(string) (string, bool)) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		switch next := s[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated variable in %q", s)
+			}
+			value, err := expand(s[i+2:i+end], lookup)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i += end
+		case isNameStart(next):
+			j := i + 1
+			for j < len(s) && isNameChar(s[j]) {
+				j++
+			}
+			value, _ := lookup(s[i+1 : j])
+			b.WriteString(value)
+			i = j - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// expand resolves the inside of ${...}.
+func expand(expr string, lookup func(string) (string, bool)) (string, error) {
+	j := 0
+	for j < len(expr) && isNameChar(expr[j]) {
+		j++
+	}
+	name, modifier := expr[:j], expr[j:]
+	if name == "" {
+		return "", fmt.Errorf("invalid variable ${%s}", expr)
+	}
+	value, set := lookup(name)
+
+	for _, op := range []string{":-", ":?", ":+", "-", "?", "+"} {
+		if !strings.HasPrefix(modifier, op) {
+			continue
+		}
+		arg := modifier[len(op):]
+		// The colon forms treat an empty value like an unset one.
+		present := set
+		if strings.HasPrefix(op, ":") {
+			present = set && value != ""
+		}
+		switch op[len(op)-1] {
+		case '-':
+			if !present {
+				return arg, nil
+			}
+		case '?':
+			if !present {
+				if arg == "" {
+					arg = "variable is not set"
+				}
+				return "", fmt.Errorf("%s: %s", name, arg)
+			}
+		case '+':
+			if present {
+				return arg, nil
+			}
+			return "", nil
+		}
+		return value, nil
+	}
+
+	if modifier != "" {
+		return "", fmt.Errorf("invalid variable ${%s}", expr)
+	}
+	return value, nil
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
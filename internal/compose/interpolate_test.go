@@ -0,0 +1,114 @@
+package compose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInterpolateString(t *testing.T) {
+	env := map[string]string{"PORT": "11434", "EMPTY": "", "GPU_1": "gfx1031"}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr string
+	}{
+		{in: "no variables", want: "no variables"},
+		{in: "$PORT", want: "11434"},
+		{in: "${PORT}", want: "11434"},
+		{in: "localhost:${PORT}/api", want: "localhost:11434/api"},
+		{in: "$GPU_1-native", want: "gfx1031-native"},
+		{in: "$UNSET", want: ""},
+		{in: "${UNSET}", want: ""},
+
+		// :- and - default an unset value; only :- defaults an empty one.
+		{in: "${UNSET:-8080}", want: "8080"},
+		{in: "${EMPTY:-8080}", want: "8080"},
+		{in: "${PORT:-8080}", want: "11434"},
+		{in: "${UNSET-8080}", want: "8080"},
+		{in: "${EMPTY-8080}", want: ""},
+		{in: "${UNSET:-}", want: ""},
+
+		// :+ and + substitute the alternative when the value is there.
+		{in: "${PORT:+set}", want: "set"},
+		{in: "${EMPTY:+set}", want: ""},
+		{in: "${UNSET:+set}", want: ""},
+		{in: "${EMPTY+set}", want: "set"},
+		{in: "${UNSET+set}", want: ""},
+
+		// :? and ? fail on a missing value.
+		{in: "${PORT:?port required}", want: "11434"},
+		{in: "${EMPTY?port required}", want: ""},
+		{in: "${EMPTY:?port required}", wantErr: "EMPTY: port required"},
+		{in: "${UNSET?port required}", wantErr: "UNSET: port required"},
+		{in: "${UNSET:?}", wantErr: "UNSET: variable is not set"},
+
+		{in: "$$PORT", want: "$PORT"},
+		{in: "echo $${HOME} $$", want: "echo ${HOME} $"},
+		{in: "cost: 5$", want: "cost: 5$"},
+		{in: "$1 and $-", want: "$1 and $-"},
+
+		{in: "${PORT", wantErr: "unterminated variable"},
+		{in: "${}", wantErr: "invalid variable ${}"},
+		{in: "${PORT:=8080}", wantErr: "invalid variable ${PORT:=8080}"},
+		{in: "${-8080}", wantErr: "invalid variable"},
+	}
+	for _, tt := range tests {
+		got, err := interpolateString(tt.in, lookup)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("interpolateString(%q) error = %v, want %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("interpolateString(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	env := map[string]string{"RETRIES": "5", "TAG": "rocm"}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	data, err := Interpolate([]byte(`# pulls ollama:${TAG}
+services:
+  ollama:
+    image: "ollama/ollama:${TAG}"
+    healthcheck:
+      retries: ${RETRIES}
+    environment:
+      - "${TAG}=literal $$ sign"
+  ${TAG}: {}
+`), lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Keys and comments are left alone, and an interpolated value can
+	// become an int.
+	want := `# pulls ollama:${TAG}
+services:
+    ollama:
+        image: ollama/ollama:rocm
+        healthcheck:
+            retries: 5
+        environment:
+            - rocm=literal $ sign
+    ${TAG}: {}
+`
+	if string(data) != want {
+		t.Errorf("Interpolate:\n%s\nwant:\n%s", data, want)
+	}
+
+	if _, err := Interpolate([]byte("services:\n  ollama:\n    image: ${IMAGE:?set IMAGE}\n"), lookup); err == nil ||
+		!strings.Contains(err.Error(), "line 3: IMAGE: set IMAGE") {
+		t.Errorf("Interpolate error = %v, want the line and message", err)
+	}
+}
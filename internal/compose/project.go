@@ -0,0 +1,342 @@
+package compose
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/docker"
+	"github.com/lyleclassen/lite-llm/internal/secrets"
+	"github.com/lyleclassen/lite-llm/internal/templates"
+)
+
+// FileName is the compose file inside a project directory.
+const FileName = "docker-compose.yml"
+
+// Labels docker compose sets on the objects of a project. lite-llm sets the
+// same ones so `docker compose ls/ps` and Portainer show the stack.
+const (
+	LabelProject    = "com.docker.compose.project"
+	LabelService    = "com.docker.compose.service"
+	LabelNetwork    = "com.docker.compose.network"
+	LabelVolume     = "com.docker.compose.volume"
+	LabelConfigHash = "com.docker.compose.config-hash"
+)
+
+// defaultNetwork is created for services that don't list any networks.
+const defaultNetwork = "default"
+
+// Project is a compose file materialised in a directory, with variables
+// interpolated from the directory's .env file and the environment.
+type Project struct {
+	Name string
+	Dir  string
+	File *templates.ComposeFile
+}
+
+// Load reads dir/docker-compose.yml, interpolating variables from the
+// process environment and dir/.env, in that order of precedence.
+func Load(name, dir string) (*Project, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read compose file: %w", err)
+	}
+	env, err := secrets.LoadEnvFile(filepath.Join(dir, ".env"))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = Interpolate(data, func(key string) (string, bool) {
+		if value, ok := os.LookupEnv(key); ok {
+			return value, true
+		}
+		return env.Get(key)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to interpolate %s: %w", FileName, err)
+	}
+
+	file, err := templates.ParseCompose(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Project{Name: name, Dir: dir, File: file}, nil
+}
+
+// Services returns every service name in dependency order.
+func (p *Project) Services() []string {
+	order, _ := p.ServiceOrder(nil)
+	return order
+}
+
+// ServiceOrder returns the named services, plus the services they depend
+// on, with dependencies first. No names selects every service.
+func (p *Project) ServiceOrder(names []string) ([]string, error) {
+	if len(names) == 0 {
+		for name := range p.File.Services {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var order []string
+	state := map[string]int{} // 1 visiting, 2 done
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		svc, ok := p.File.Services[name]
+		if !ok {
+			return fmt.Errorf("no such service: %s", name)
+		}
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		deps := make([]string, 0, len(svc.DependsOn))
+		for dep := range svc.DependsOn {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// ContainerName is the service's container_name, or compose's
+// <project>-<service>-1.
+func (p *Project) ContainerName(service string) string {
+	if name := p.File.Services[service].ContainerName; name != "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%s-1", p.Name, service)
+}
+
+// VolumeName is the Docker name of a top-level volume.
+func (p *Project) VolumeName(volume string) string {
+	return p.Name + "_" + volume
+}
+
+// NetworkName is the Docker name of a top-level network.
+func (p *Project) NetworkName(network string) string {
+	return p.Name + "_" + network
+}
+
+// serviceNetworks lists the networks a service joins.
+func (p *Project) serviceNetworks(service string) []string {
+	if networks := p.File.Services[service].Networks; len(networks) > 0 {
+		return networks
+	}
+	return []string{defaultNetwork}
+}
+
+// Networks lists every network the project's services join.
+func (p *Project) Networks() []string {
+	seen := map[string]bool{}
+	var networks []string
+	for _, service := range p.Services() {
+		for _, network := range p.serviceNetworks(service) {
+			if !seen[network] {
+				seen[network] = true
+				networks = append(networks, network)
+			}
+		}
+	}
+	return networks
+}
+
+// ConfigPath is where a config with inline content is written for
+// bind-mounting.
+func (p *Project) ConfigPath(name string) string {
+	return filepath.Join(p.Dir, "configs", name)
+}
+
+// hostPath resolves a relative bind mount or file against the project
+// directory.
+func (p *Project) hostPath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.Dir, path)
+}
+
+// ContainerConfig translates a service to a Docker create request. The
+// config-hash label changes whenever the request does.
+func (p *Project) ContainerConfig(service string) (*docker.ContainerConfig, error) {
+	svc := p.File.Services[service]
+	config := &docker.ContainerConfig{
+		Image: svc.Image,
+		Cmd:   svc.Command,
+		Env:   svc.Environment,
+		Labels: map[string]string{
+			LabelProject: p.Name,
+			LabelService: service,
+		},
+	}
+	host := &config.HostConfig
+
+	for _, label := range svc.Labels {
+		key, value, _ := strings.Cut(label, "=")
+		config.Labels[key] = value
+	}
+
+	for _, port := range svc.Ports {
+		spec, proto, ok := strings.Cut(string(port), "/")
+		if !ok {
+			proto = "tcp"
+		}
+		parts := strings.Split(spec, ":")
+		binding := docker.PortBinding{HostPort: parts[len(parts)-2]}
+		if len(parts) == 3 {
+			binding.HostIP = parts[0]
+		}
+		key := parts[len(parts)-1] + "/" + proto
+		if config.ExposedPorts == nil {
+			config.ExposedPorts = map[string]struct{}{}
+			host.PortBindings = map[string][]docker.PortBinding{}
+		}
+		config.ExposedPorts[key] = struct{}{}
+		host.PortBindings[key] = append(host.PortBindings[key], binding)
+	}
+
+	for _, volume := range svc.Volumes {
+		parts := strings.Split(volume, ":")
+		mount := docker.Mount{Type: "bind", Source: parts[0], Target: parts[1]}
+		if len(parts) > 2 {
+			for _, option := range strings.Split(parts[2], ",") {
+				mount.ReadOnly = mount.ReadOnly || option == "ro"
+			}
+		}
+		if _, named := p.File.Volumes[parts[0]]; named {
+			mount.Type, mount.Source = "volume", p.VolumeName(parts[0])
+		} else {
+			mount.Source = p.hostPath(parts[0])
+		}
+		host.Mounts = append(host.Mounts, mount)
+	}
+	for _, cfg := range svc.Configs {
+		source := p.ConfigPath(cfg.Source)
+		if file := p.File.Configs[cfg.Source].File; file != "" {
+			source = p.hostPath(file)
+		}
+		host.Mounts = append(host.Mounts, docker.Mount{Type: "bind", Source: source, Target: cfg.Target, ReadOnly: true})
+	}
+	for _, name := range svc.Secrets {
+		host.Mounts = append(host.Mounts, docker.Mount{
+			Type: "bind", Source: p.hostPath(p.File.Secrets[name].File), Target: "/run/secrets/" + name, ReadOnly: true,
+		})
+	}
+
+	for _, device := range svc.Devices {
+		parts := strings.Split(device, ":")
+		mapping := docker.DeviceMapping{PathOnHost: parts[0], PathInContainer: parts[0], CgroupPermissions: "rwm"}
+		if len(parts) > 1 {
+			mapping.PathInContainer = parts[1]
+		}
+		if len(parts) > 2 {
+			mapping.CgroupPermissions = parts[2]
+		}
+		host.Devices = append(host.Devices, mapping)
+	}
+
+	if svc.Deploy != nil {
+		if limits := svc.Deploy.Resources.Limits; limits != nil {
+			if limits.Memory != "" {
+				memory, err := docker.ParseBytes(limits.Memory)
+				if err != nil {
+					return nil, fmt.Errorf("service %s: %w", service, err)
+				}
+				host.Memory = memory
+			}
+			if limits.CPUs != "" {
+				cpus, err := strconv.ParseFloat(limits.CPUs, 64)
+				if err != nil {
+					return nil, fmt.Errorf("service %s: invalid cpus %q", service, limits.CPUs)
+				}
+				host.NanoCpus = int64(cpus * 1e9)
+			}
+		}
+		if reservations := svc.Deploy.Resources.Reservations; reservations != nil {
+			for _, device := range reservations.Devices {
+				count := device.Count
+				if count == 0 {
+					count = -1 // all
+				}
+				host.DeviceRequests = append(host.DeviceRequests, docker.DeviceRequest{
+					Driver: device.Driver, Count: count, Capabilities: [][]string{device.Capabilities},
+				})
+			}
+		}
+	}
+
+	policy, retries, _ := strings.Cut(svc.Restart, ":")
+	host.RestartPolicy.Name = policy
+	if retries != "" {
+		host.RestartPolicy.MaximumRetryCount, _ = strconv.Atoi(retries)
+	}
+	host.ExtraHosts = svc.ExtraHosts
+	host.CpusetCpus = svc.CPUSet
+	if svc.ShmSize != "" {
+		size, err := docker.ParseBytes(svc.ShmSize)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", service, err)
+		}
+		host.ShmSize = size
+	}
+
+	if hc := svc.Healthcheck; hc != nil {
+		config.Healthcheck = &docker.HealthConfig{
+			Test:        hc.Test,
+			Interval:    nanoseconds(hc.Interval),
+			Timeout:     nanoseconds(hc.Timeout),
+			Retries:     hc.Retries,
+			StartPeriod: nanoseconds(hc.StartPeriod),
+		}
+	}
+
+	// The first network is joined at creation; Up connects the rest.
+	networks := p.serviceNetworks(service)
+	host.NetworkMode = p.NetworkName(networks[0])
+	config.NetworkingConfig = &docker.NetworkingConfig{EndpointsConfig: map[string]docker.EndpointSettings{
+		p.NetworkName(networks[0]): {Aliases: []string{service}},
+	}}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	config.Labels[LabelConfigHash] = hex.EncodeToString(sum[:])
+	return config, nil
+}
+
+// nanoseconds converts a validated compose duration; empty is zero.
+func nanoseconds(d string) int64 {
+	parsed, _ := time.ParseDuration(d)
+	return int64(parsed)
+}
@@ -0,0 +1,185 @@
+package compose
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lyleclassen/lite-llm/internal/docker"
+	"github.com/lyleclassen/lite-llm/internal/templates"
+)
+
+func testProject(t *testing.T, compose string) *Project {
+	t.Helper()
+	file, err := templates.ParseCompose([]byte(compose))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Project{Name: "llm-stack", Dir: "/srv/llm-stack", File: file}
+}
+
+func TestServiceOrder(t *testing.T) {
+	p := testProject(t, `
+services:
+  caddy:
+    image: caddy:2
+    depends_on:
+      open-webui:
+        condition: service_started
+  open-webui:
+    image: ghcr.io/open-webui/open-webui:main
+    depends_on:
+      ollama:
+        condition: service_started
+  ollama:
+    image: ollama/ollama:rocm
+  tools:
+    image: busybox
+`)
+	tests := []struct {
+		names   []string
+		want    []string
+		wantErr string
+	}{
+		{names: nil, want: []string{"ollama", "open-webui", "caddy", "tools"}},
+		{names: []string{"caddy"}, want: []string{"ollama", "open-webui", "caddy"}},
+		{names: []string{"tools", "open-webui"}, want: []string{"ollama", "open-webui", "tools"}},
+		{names: []string{"ollama"}, want: []string{"ollama"}},
+		{names: []string{"missing"}, wantErr: "no such service: missing"},
+	}
+	for _, tt := range tests {
+		got, err := p.ServiceOrder(tt.names)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ServiceOrder(%v) error = %v, want %q", tt.names, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ServiceOrder(%v) = %v, %v; want %v", tt.names, got, err, tt.want)
+		}
+	}
+}
+
+func TestServiceOrderCycle(t *testing.T) {
+	p := testProject(t, `
+services:
+  a:
+    image: busybox
+    depends_on:
+      b:
+        condition: service_started
+  b:
+    image: busybox
+    depends_on:
+      c:
+        condition: service_started
+  c:
+    image: busybox
+    depends_on:
+      a:
+        condition: service_started
+`)
+	_, err := p.ServiceOrder(nil)
+	if want := "dependency cycle: a -> b -> c -> a"; err == nil || err.Error() != want {
+		t.Errorf("ServiceOrder error = %v, want %q", err, want)
+	}
+}
+
+const containerCompose = `
+services:
+  ollama:
+    image: ollama/ollama:rocm
+    restart: on-failure:3
+    ports:
+      - "11434:11434"
+      - "127.0.0.1:8053:53/udp"
+    volumes:
+      - ollama_data:/root/.ollama
+      - ./models:/models:ro
+      - /etc/localtime:/etc/localtime:ro,z
+    devices:
+      - /dev/kfd
+      - /dev/dri/renderD128:/dev/dri/renderD128:rw
+volumes:
+  ollama_data: {}
+`
+
+func TestContainerConfig(t *testing.T) {
+	config, err := testProject(t, containerCompose).ContainerConfig("ollama")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := config.HostConfig
+
+	wantPorts := map[string][]docker.PortBinding{
+		"11434/tcp": {{HostPort: "11434"}},
+		"53/udp":    {{HostIP: "127.0.0.1", HostPort: "8053"}},
+	}
+	if !reflect.DeepEqual(host.PortBindings, wantPorts) {
+		t.Errorf("PortBindings = %v, want %v", host.PortBindings, wantPorts)
+	}
+	if len(config.ExposedPorts) != 2 {
+		t.Errorf("ExposedPorts = %v", config.ExposedPorts)
+	}
+
+	wantMounts := []docker.Mount{
+		{Type: "volume", Source: "llm-stack_ollama_data", Target: "/root/.ollama"},
+		{Type: "bind", Source: "/srv/llm-stack/models", Target: "/models", ReadOnly: true},
+		{Type: "bind", Source: "/etc/localtime", Target: "/etc/localtime", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(host.Mounts, wantMounts) {
+		t.Errorf("Mounts = %+v, want %+v", host.Mounts, wantMounts)
+	}
+
+	wantDevices := []docker.DeviceMapping{
+		{PathOnHost: "/dev/kfd", PathInContainer: "/dev/kfd", CgroupPermissions: "rwm"},
+		{PathOnHost: "/dev/dri/renderD128", PathInContainer: "/dev/dri/renderD128", CgroupPermissions: "rw"},
+	}
+	if !reflect.DeepEqual(host.Devices, wantDevices) {
+		t.Errorf("Devices = %+v, want %+v", host.Devices, wantDevices)
+	}
+
+	if host.RestartPolicy != (docker.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3}) {
+		t.Errorf("RestartPolicy = %+v", host.RestartPolicy)
+	}
+	if config.Labels[LabelProject] != "llm-stack" || config.Labels[LabelService] != "ollama" {
+		t.Errorf("Labels = %v", config.Labels)
+	}
+}
+
+func TestContainerConfigHash(t *testing.T) {
+	hash := func(compose string) string {
+		t.Helper()
+		config, err := testProject(t, compose).ContainerConfig("ollama")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return config.Labels[LabelConfigHash]
+	}
+
+	base := hash(containerCompose)
+	if len(base) != 64 {
+		t.Fatalf("config hash = %q, want a sha256", base)
+	}
+	if again := hash(containerCompose); again != base {
+		t.Errorf("config hash changed across identical inputs: %s, %s", base, again)
+	}
+
+	changes := map[string][2]string{
+		"image":   {"ollama/ollama:rocm", "ollama/ollama:0.5.7-rocm"},
+		"port":    {`"11434:11434"`, `"11435:11434"`},
+		"volume":  {"./models:/models:ro", "./models:/models"},
+		"device":  {"renderD128:rw", "renderD128:rwm"},
+		"restart": {"on-failure:3", "always"},
+	}
+	for field, change := range changes {
+		changed := strings.Replace(containerCompose, change[0], change[1], 1)
+		if changed == containerCompose {
+			t.Fatalf("%s: %q not found in the compose file", field, change[0])
+		}
+		if hash(changed) == base {
+			t.Errorf("config hash didn't change with the %s", field)
+		}
+	}
+}
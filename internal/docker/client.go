@@ -0,0 +1,302 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultSocket is where the Docker daemon listens unless docker.socket
+// says otherwise.
+const DefaultSocket = "/var/run/docker.sock"
+
+// Client talks to the Docker Engine API over its unix socket.
+type Client struct {
+	socket       string
+	httpClient   *http.Client
+	streamClient *http.Client // no timeout, for followed logs and pulls
+}
+
+// APIError is an error response from the Docker daemon.
+type APIError struct {
+	StatusCode int
+	Message    string `json:"message"`
+}
+
+// IsNotFound reports whether err is a 404 from the daemon, e.g. for a
+// missing container, image, network or volume.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("docker API error (%d): %s", e.StatusCode, msg)
+}
+
+// Container is an entry of /containers/json.
+type Container struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	ImageID string            `json:"ImageID"`
+	State   string            `json:"State"`
+	Status  string            `json:"Status"`
+	Labels  map[string]string `json:"Labels"`
+}
+
+// Name returns the container name without Docker's leading slash.
+func (c Container) Name() string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// ContainerDetails is the subset of /containers/{id}/json lite-llm reads.
+type ContainerDetails struct {
+	ID           string `json:"Id"`
+	Name         string `json:"Name"`
+	Image        string `json:"Image"`
+	RestartCount int    `json:"RestartCount"`
+	State        struct {
+		Status     string `json:"Status"`
+		Running    bool   `json:"Running"`
+		ExitCode   int    `json:"ExitCode"`
+		StartedAt  string `json:"StartedAt"`
+		FinishedAt string `json:"FinishedAt"`
+		Health     *struct {
+			Status        string `json:"Status"`
+			FailingStreak int    `json:"FailingStreak"`
+		} `json:"Health"`
+	} `json:"State"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Tty    bool              `json:"Tty"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	HostConfig struct {
+		Devices []struct {
+			PathOnHost      string `json:"PathOnHost"`
+			PathInContainer string `json:"PathInContainer"`
+		} `json:"Devices"`
+		DeviceRequests []struct {
+			Driver       string     `json:"Driver"`
+			Count        int        `json:"Count"`
+			DeviceIDs    []string   `json:"DeviceIDs"`
+			Capabilities [][]string `json:"Capabilities"`
+		} `json:"DeviceRequests"`
+	} `json:"HostConfig"`
+}
+
+// Image is the subset of /images/{name}/json lite-llm reads.
+type Image struct {
+	ID          string   `json:"Id"`
+	RepoTags    []string `json:"RepoTags"`
+	RepoDigests []string `json:"RepoDigests"`
+}
+
+// Stats is a one-shot sample from /containers/{id}/stats.
+type Stats struct {
+	CPUStats    cpuStats `json:"cpu_stats"`
+	PreCPUStats cpuStats `json:"precpu_stats"`
+	MemoryStats struct {
+		Usage uint64            `json:"usage"`
+		Limit uint64            `json:"limit"`
+		Stats map[string]uint64 `json:"stats"`
+	} `json:"memory_stats"`
+}
+
+type cpuStats struct {
+	CPUUsage struct {
+		TotalUsage  uint64   `json:"total_usage"`
+		PercpuUsage []uint64 `json:"percpu_usage"`
+	} `json:"cpu_usage"`
+	SystemUsage uint64 `json:"system_cpu_usage"`
+	OnlineCPUs  int    `json:"online_cpus"`
+}
+
+// CPUPercent computes CPU usage the way `docker stats` does, where 100%
+// is one full core.
+func (s *Stats) CPUPercent() float64 {
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+	cpus := s.CPUStats.OnlineCPUs
+	if cpus == 0 {
+		cpus = len(s.CPUStats.CPUUsage.PercpuUsage)
+	}
+	return cpuDelta / systemDelta * float64(cpus) * 100
+}
+
+// MemoryUsed excludes the page cache, matching `docker stats`.
+func (s *Stats) MemoryUsed() uint64 {
+	used := s.MemoryStats.Usage
+	cache := s.MemoryStats.Stats["inactive_file"] // cgroup v2
+	if cache == 0 {
+		cache = s.MemoryStats.Stats["total_inactive_file"] // cgroup v1
+	}
+	if cache < used {
+		used -= cache
+	}
+	return used
+}
+
+// NewClient returns a client for the daemon listening on socket.
+func NewClient(socket string) *Client {
+	if socket == "" {
+		socket = DefaultSocket
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &Client{
+		socket:       socket,
+		httpClient:   &http.Client{Timeout: 30 * time.Second, Transport: transport},
+		streamClient: &http.Client{Transport: transport},
+	}
+}
+
+// Socket returns the socket path the client connects to.
+func (c *Client) Socket() string {
+	return c.socket
+}
+
+// Ping checks that the daemon is reachable and healthy.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, "GET", "/_ping", nil, nil)
+}
+
+// ListContainers lists containers matching filters (e.g. "label" or
+// "name"), including stopped ones when all is set.
+func (c *Client) ListContainers(ctx context.Context, all bool, filters map[string][]string) ([]Container, error) {
+	query := url.Values{}
+	if all {
+		query.Set("all", "1")
+	}
+	if len(filters) > 0 {
+		data, err := json.Marshal(filters)
+		if err != nil {
+			return nil, err
+		}
+		query.Set("filters", string(data))
+	}
+
+	var containers []Container
+	if err := c.do(ctx, "GET", "/containers/json?"+query.Encode(), nil, &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+// InspectContainer returns a container's configuration and state.
+func (c *Client) InspectContainer(ctx context.Context, id string) (*ContainerDetails, error) {
+	var details ContainerDetails
+	if err := c.do(ctx, "GET", "/containers/"+url.PathEscape(id)+"/json", nil, &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// InspectImage returns an image by ID or reference.
+func (c *Client) InspectImage(ctx context.Context, ref string) (*Image, error) {
+	var image Image
+	if err := c.do(ctx, "GET", "/images/"+ref+"/json", nil, &image); err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
+
+// ContainerStats takes a single stats sample. The daemon waits for a
+// second reading so CPU usage can be computed, so this takes ~1s.
+func (c *Client) ContainerStats(ctx context.Context, id string) (*Stats, error) {
+	var stats Stats
+	if err := c.do(ctx, "GET", "/containers/"+url.PathEscape(id)+"/stats?stream=false", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// do sends a request with an optional JSON body and decodes a JSON
+// response into out, if set.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	resp, err := c.send(ctx, c.httpClient, method, path, in)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// stream sends a request and returns the response body for the caller to
+// read and close. It is only bounded by ctx.
+func (c *Client) stream(ctx context.Context, method, path string) (io.ReadCloser, error) {
+	resp, err := c.send(ctx, c.streamClient, method, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) send(ctx context.Context, client *http.Client, method, path string, in interface{}) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, "http://docker"+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err // the URL is a placeholder
+		}
+		return nil, fmt.Errorf("cannot reach Docker at %s: %w", c.socket, err)
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(data, apiErr) != nil {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return nil, apiErr
+	}
+	return resp, nil
+}
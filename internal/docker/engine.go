@@ -0,0 +1,322 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ContainerConfig is the body of POST /containers/create.
+type ContainerConfig struct {
+	Image            string              `json:"Image"`
+	Cmd              []string            `json:"Cmd,omitempty"`
+	Env              []string            `json:"Env,omitempty"`
+	Labels           map[string]string   `json:"Labels,omitempty"`
+	ExposedPorts     map[string]struct{} `json:"ExposedPorts,omitempty"`
+	Healthcheck      *HealthConfig       `json:"Healthcheck,omitempty"`
+	HostConfig       HostConfig          `json:"HostConfig"`
+	NetworkingConfig *NetworkingConfig   `json:"NetworkingConfig,omitempty"`
+}
+
+// HealthConfig is a container healthcheck; durations are in nanoseconds.
+type HealthConfig struct {
+	Test        []string `json:"Test"`
+	Interval    int64    `json:"Interval,omitempty"`
+	Timeout     int64    `json:"Timeout,omitempty"`
+	Retries     int      `json:"Retries,omitempty"`
+	StartPeriod int64    `json:"StartPeriod,omitempty"`
+}
+
+type HostConfig struct {
+	Mounts         []Mount                  `json:"Mounts,omitempty"`
+	PortBindings   map[string][]PortBinding `json:"PortBindings,omitempty"`
+	Devices        []DeviceMapping          `json:"Devices,omitempty"`
+	DeviceRequests []DeviceRequest          `json:"DeviceRequests,omitempty"`
+	RestartPolicy  RestartPolicy            `json:"RestartPolicy"`
+	ExtraHosts     []string                 `json:"ExtraHosts,omitempty"`
+	ShmSize        int64                    `json:"ShmSize,omitempty"`
+	CpusetCpus     string                   `json:"CpusetCpus,omitempty"`
+	NanoCpus       int64                    `json:"NanoCpus,omitempty"`
+	Memory         int64                    `json:"Memory,omitempty"`
+	NetworkMode    string                   `json:"NetworkMode,omitempty"`
+}
+
+type Mount struct {
+	Type     string `json:"Type"` // "volume" or "bind"
+	Source   string `json:"Source"`
+	Target   string `json:"Target"`
+	ReadOnly bool   `json:"ReadOnly,omitempty"`
+}
+
+type PortBinding struct {
+	HostIP   string `json:"HostIp"`
+	HostPort string `json:"HostPort"`
+}
+
+type DeviceMapping struct {
+	PathOnHost        string `json:"PathOnHost"`
+	PathInContainer   string `json:"PathInContainer"`
+	CgroupPermissions string `json:"CgroupPermissions"`
+}
+
+type DeviceRequest struct {
+	Driver       string     `json:"Driver"`
+	Count        int        `json:"Count"`
+	Capabilities [][]string `json:"Capabilities"`
+}
+
+type RestartPolicy struct {
+	Name              string `json:"Name"`
+	MaximumRetryCount int    `json:"MaximumRetryCount,omitempty"`
+}
+
+type NetworkingConfig struct {
+	EndpointsConfig map[string]EndpointSettings `json:"EndpointsConfig"`
+}
+
+type EndpointSettings struct {
+	Aliases []string `json:"Aliases,omitempty"`
+}
+
+// CreateContainer creates a container called name and returns its ID.
+func (c *Client) CreateContainer(ctx context.Context, name string, config *ContainerConfig) (string, error) {
+	var created struct {
+		ID       string   `json:"Id"`
+		Warnings []string `json:"Warnings"`
+	}
+	path := "/containers/create?name=" + url.QueryEscape(name)
+	if err := c.do(ctx, "POST", path, config, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// StartContainer starts a created or stopped container.
+func (c *Client) StartContainer(ctx context.Context, id string) error {
+	return ignoreNotModified(c.do(ctx, "POST", "/containers/"+url.PathEscape(id)+"/start", nil, nil))
+}
+
+// StopContainer stops a container, killing it after timeout seconds.
+func (c *Client) StopContainer(ctx context.Context, id string, timeout int) error {
+	path := fmt.Sprintf("/containers/%s/stop?t=%d", url.PathEscape(id), timeout)
+	return ignoreNotModified(c.do(ctx, "POST", path, nil, nil))
+}
+
+// RestartContainer restarts a container, killing it after timeout seconds.
+func (c *Client) RestartContainer(ctx context.Context, id string, timeout int) error {
+	path := fmt.Sprintf("/containers/%s/restart?t=%d", url.PathEscape(id), timeout)
+	return c.do(ctx, "POST", path, nil, nil)
+}
+
+// RemoveContainer removes a container, stopping it first if force is set.
+// Its named volumes are kept.
+func (c *Client) RemoveContainer(ctx context.Context, id string, force bool) error {
+	path := "/containers/" + url.PathEscape(id)
+	if force {
+		path += "?force=1"
+	}
+	return c.do(ctx, "DELETE", path, nil, nil)
+}
+
+// PauseContainer freezes a container's processes.
+func (c *Client) PauseContainer(ctx context.Context, id string) error {
+	return c.do(ctx, "POST", "/containers/"+url.PathEscape(id)+"/pause", nil, nil)
+}
+
+// UnpauseContainer resumes a paused container.
+func (c *Client) UnpauseContainer(ctx context.Context, id string) error {
+	return c.do(ctx, "POST", "/containers/"+url.PathEscape(id)+"/unpause", nil, nil)
+}
+
+// Start and stop return 304 when the container is already in that state.
+func ignoreNotModified(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == 304 {
+		return nil
+	}
+	return err
+}
+
+// Network is the subset of /networks/{id} lite-llm reads.
+type Network struct {
+	ID     string            `json:"Id"`
+	Name   string            `json:"Name"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// InspectNetwork returns a network by name or ID.
+func (c *Client) InspectNetwork(ctx context.Context, name string) (*Network, error) {
+	var network Network
+	if err := c.do(ctx, "GET", "/networks/"+url.PathEscape(name), nil, &network); err != nil {
+		return nil, err
+	}
+	return &network, nil
+}
+
+// CreateNetwork creates a network unless one with that name exists.
+func (c *Client) CreateNetwork(ctx context.Context, name, driver string, labels map[string]string) error {
+	if _, err := c.InspectNetwork(ctx, name); err == nil {
+		return nil
+	} else if !IsNotFound(err) {
+		return err
+	}
+	body := map[string]interface{}{
+		"Name":           name,
+		"Driver":         driver,
+		"Labels":         labels,
+		"CheckDuplicate": true,
+	}
+	return c.do(ctx, "POST", "/networks/create", body, nil)
+}
+
+// ConnectNetwork attaches a container to a further network.
+func (c *Client) ConnectNetwork(ctx context.Context, network, container string, aliases []string) error {
+	body := map[string]interface{}{
+		"Container":      container,
+		"EndpointConfig": EndpointSettings{Aliases: aliases},
+	}
+	return c.do(ctx, "POST", "/networks/"+url.PathEscape(network)+"/connect", body, nil)
+}
+
+// RemoveNetwork removes a network; a missing network is not an error.
+func (c *Client) RemoveNetwork(ctx context.Context, name string) error {
+	err := c.do(ctx, "DELETE", "/networks/"+url.PathEscape(name), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Volume is the subset of /volumes/{name} lite-llm reads.
+type Volume struct {
+	Name       string            `json:"Name"`
+	Driver     string            `json:"Driver"`
+	Mountpoint string            `json:"Mountpoint"`
+	Labels     map[string]string `json:"Labels"`
+}
+
+// InspectVolume returns a named volume.
+func (c *Client) InspectVolume(ctx context.Context, name string) (*Volume, error) {
+	var volume Volume
+	if err := c.do(ctx, "GET", "/volumes/"+url.PathEscape(name), nil, &volume); err != nil {
+		return nil, err
+	}
+	return &volume, nil
+}
+
+// CreateVolume creates a named volume unless it exists. Existing volumes
+// keep their data.
+func (c *Client) CreateVolume(ctx context.Context, name, driver string, labels map[string]string) error {
+	_, err := c.InspectVolume(ctx, name)
+	if err == nil {
+		return nil
+	} else if !IsNotFound(err) {
+		return err
+	}
+	body := map[string]interface{}{
+		"Name":   name,
+		"Driver": driver,
+		"Labels": labels,
+	}
+	return c.do(ctx, "POST", "/volumes/create", body, nil)
+}
+
+// PullProgress is one message of an image pull. Layer progress messages
+// carry the layer ID and byte counts.
+type PullProgress struct {
+	Status         string `json:"status"`
+	ID             string `json:"id"`
+	ProgressDetail struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"progressDetail"`
+	Error string `json:"error"`
+}
+
+// PullImage pulls ref from its registry, reporting progress to fn.
+func (c *Client) PullImage(ctx context.Context, ref string, fn func(PullProgress)) error {
+	name, tag := SplitImageRef(ref)
+	query := url.Values{}
+	query.Set("fromImage", name)
+	query.Set("tag", tag)
+
+	body, err := c.stream(ctx, "POST", "/images/create?"+query.Encode())
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	for {
+		var msg PullProgress
+		if err := dec.Decode(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read pull progress: %w", err)
+		}
+		// Pull failures arrive in the stream after a 200 response.
+		if msg.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", ref, msg.Error)
+		}
+		if fn != nil {
+			fn(msg)
+		}
+	}
+}
+
+// SplitImageRef splits an image reference into name and tag or digest,
+// defaulting to "latest".
+func SplitImageRef(ref string) (name, tag string) {
+	if name, digest, ok := strings.Cut(ref, "@"); ok {
+		return name, digest
+	}
+	// A colon after the last slash is a tag; before it, a registry port.
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
+// ImageID returns the local ID of ref, or "" if it hasn't been pulled.
+func (c *Client) ImageID(ctx context.Context, ref string) (string, error) {
+	image, err := c.InspectImage(ctx, ref)
+	if IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return image.ID, nil
+}
+
+// ParseBytes parses a compose byte value such as "16g", "512m" or "1024".
+func ParseBytes(v string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(v))
+	s = strings.TrimSuffix(s, "b")
+	multiplier := int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'k':
+			multiplier = 1 << 10
+		case 'm':
+			multiplier = 1 << 20
+		case 'g':
+			multiplier = 1 << 30
+		case 't':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid byte value %q", v)
+	}
+	return int64(n * float64(multiplier)), nil
+}
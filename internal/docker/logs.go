@@ -0,0 +1,157 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogsOptions selects which log lines ContainerLogs returns.
+type LogsOptions struct {
+	Follow bool
+	Since  time.Time // zero for the beginning
+	Tail   int       // lines from the end; negative for all
+}
+
+// ParseSince accepts a duration before now ("10m") or an RFC 3339
+// timestamp, as `docker logs --since` does.
+func ParseSince(v string) (time.Time, error) {
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q: expected a duration such as 10m or an RFC 3339 timestamp", v)
+}
+
+// LogLine is one line of container output.
+type LogLine struct {
+	Time   time.Time `json:"time" yaml:"time"`
+	Stream string    `json:"stream" yaml:"stream"` // "stdout" or "stderr"
+	Text   string    `json:"text" yaml:"text"`
+}
+
+// ContainerLogs streams a container's logs, calling fn for each line until
+// the stream ends, ctx is cancelled or fn returns an error.
+func (c *Client) ContainerLogs(ctx context.Context, id string, opts LogsOptions, fn func(LogLine) error) error {
+	details, err := c.InspectContainer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("stdout", "1")
+	query.Set("stderr", "1")
+	query.Set("timestamps", "1")
+	if opts.Follow {
+		query.Set("follow", "1")
+	}
+	if !opts.Since.IsZero() {
+		query.Set("since", strconv.FormatInt(opts.Since.Unix(), 10))
+	}
+	if opts.Tail >= 0 {
+		query.Set("tail", strconv.Itoa(opts.Tail))
+	} else {
+		query.Set("tail", "all")
+	}
+
+	body, err := c.stream(ctx, "GET", "/containers/"+url.PathEscape(id)+"/logs?"+query.Encode())
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	err = ReadLogs(body, details.Config.Tty, fn)
+	if ctx.Err() != nil {
+		return nil // stopped by the caller
+	}
+	return err
+}
+
+// ReadLogs splits a Docker log stream into lines. Containers without a TTY
+// multiplex stdout and stderr into frames of an 8-byte header (stream
+// type, three zero bytes, big-endian payload length) and the payload; TTY
+// containers send raw stdout. Lines are expected to carry the timestamp
+// prefix added by timestamps=1.
+func ReadLogs(r io.Reader, tty bool, fn func(LogLine) error) error {
+	if tty {
+		return readLines(r, "stdout", fn)
+	}
+
+	reader := bufio.NewReader(r)
+	partial := map[string]string{}
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return err
+		}
+
+		stream := "stdout"
+		switch header[0] {
+		case 0, 1:
+		case 2:
+			stream = "stderr"
+		default:
+			return fmt.Errorf("unexpected log stream type %d", header[0])
+		}
+
+		payload := make([]byte, binary.BigEndian.Uint32(header[4:]))
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return fmt.Errorf("truncated log frame: %w", err)
+		}
+
+		// Frames needn't end on a line boundary; keep the remainder for
+		// the next frame of the same stream.
+		text := partial[stream] + string(payload)
+		lines := strings.Split(text, "\n")
+		partial[stream] = lines[len(lines)-1]
+		for _, line := range lines[:len(lines)-1] {
+			if err := fn(parseLogLine(stream, line)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, stream := range []string{"stdout", "stderr"} {
+		if partial[stream] != "" {
+			if err := fn(parseLogLine(stream, partial[stream])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readLines(r io.Reader, stream string, fn func(LogLine) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err := fn(parseLogLine(stream, scanner.Text())); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func parseLogLine(stream, line string) LogLine {
+	line = strings.TrimSuffix(line, "\r")
+	entry := LogLine{Stream: stream, Text: line}
+	if stamp, text, ok := strings.Cut(line, " "); ok {
+		if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+			entry.Time = t
+			entry.Text = text
+		}
+	}
+	return entry
+}
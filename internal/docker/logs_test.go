@@ -0,0 +1,165 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// frame is one multiplexed log frame: stream 1 is stdout, 2 stderr.
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+func frames(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func readAll(t *testing.T, data []byte, tty bool) ([]LogLine, error) {
+	t.Helper()
+	var lines []LogLine
+	err := ReadLogs(bytes.NewReader(data), tty, func(line LogLine) error {
+		lines = append(lines, line)
+		return nil
+	})
+	return lines, err
+}
+
+const (
+	stamp1 = "2026-10-18T12:00:00.000000001Z"
+	stamp2 = "2026-10-18T12:00:01.5Z"
+)
+
+func TestReadLogs(t *testing.T) {
+	t1, _ := time.Parse(time.RFC3339Nano, stamp1)
+	t2, _ := time.Parse(time.RFC3339Nano, stamp2)
+
+	tests := []struct {
+		name string
+		data []byte
+		tty  bool
+		want []LogLine
+	}{
+		{
+			name: "stdout and stderr",
+			data: frames(
+				frame(1, stamp1+" listening on 11434\n"),
+				frame(2, stamp2+" warning: no GPU\n"),
+			),
+			want: []LogLine{
+				{Time: t1, Stream: "stdout", Text: "listening on 11434"},
+				{Time: t2, Stream: "stderr", Text: "warning: no GPU"},
+			},
+		},
+		{
+			name: "several lines in one frame",
+			data: frame(1, stamp1+" one\n"+stamp2+" two\n"),
+			want: []LogLine{
+				{Time: t1, Stream: "stdout", Text: "one"},
+				{Time: t2, Stream: "stdout", Text: "two"},
+			},
+		},
+		{
+			name: "line split across frames",
+			data: frames(
+				frame(1, stamp1+" loading mod"),
+				frame(1, "el llama3.1:8b\n"),
+			),
+			want: []LogLine{{Time: t1, Stream: "stdout", Text: "loading model llama3.1:8b"}},
+		},
+		{
+			name: "interleaved partial lines",
+			data: frames(
+				frame(1, stamp1+" out "),
+				frame(2, stamp2+" err "),
+				frame(1, "first\n"+stamp2+" out second\n"),
+				frame(2, "first\n"),
+			),
+			want: []LogLine{
+				{Time: t1, Stream: "stdout", Text: "out first"},
+				{Time: t2, Stream: "stdout", Text: "out second"},
+				{Time: t2, Stream: "stderr", Text: "err first"},
+			},
+		},
+		{
+			name: "trailing lines without newline",
+			data: frames(
+				frame(1, stamp1+" done\n"+stamp2+" exiting"),
+				frame(2, stamp2+" killed"),
+			),
+			want: []LogLine{
+				{Time: t1, Stream: "stdout", Text: "done"},
+				{Time: t2, Stream: "stdout", Text: "exiting"},
+				{Time: t2, Stream: "stderr", Text: "killed"},
+			},
+		},
+		{
+			name: "stdin frames count as stdout",
+			data: frame(0, stamp1+" echoed\n"),
+			want: []LogLine{{Time: t1, Stream: "stdout", Text: "echoed"}},
+		},
+		{
+			name: "empty frame and blank line",
+			data: frames(frame(1, ""), frame(1, "\n")),
+			want: []LogLine{{Stream: "stdout", Text: ""}},
+		},
+		{
+			name: "line without a timestamp",
+			data: frame(2, "panic: runtime error\r\n"),
+			want: []LogLine{{Stream: "stderr", Text: "panic: runtime error"}},
+		},
+		{
+			name: "tty stream is unframed stdout",
+			data: []byte(stamp1 + " >>> loading\r\n" + stamp2 + " ready"),
+			tty:  true,
+			want: []LogLine{
+				{Time: t1, Stream: "stdout", Text: ">>> loading"},
+				{Time: t2, Stream: "stdout", Text: "ready"},
+			},
+		},
+		{
+			name: "empty stream",
+			data: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAll(t, tt.data, tt.tty)
+			if err != nil {
+				t.Fatalf("ReadLogs: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadLogs:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLogsErrors(t *testing.T) {
+	if _, err := readAll(t, frame(3, "x\n"), false); err == nil || !strings.Contains(err.Error(), "unexpected log stream type 3") {
+		t.Errorf("unknown stream: %v", err)
+	}
+
+	truncated := frame(1, stamp1+" cut off\n")
+	if _, err := readAll(t, truncated[:len(truncated)-3], false); err == nil || !strings.Contains(err.Error(), "truncated log frame") {
+		t.Errorf("truncated frame: %v", err)
+	}
+
+	// A callback error stops reading.
+	stop := errors.New("stop")
+	calls := 0
+	err := ReadLogs(bytes.NewReader(frame(1, "a\nb\nc\n")), false, func(LogLine) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("ReadLogs = %v after %d calls, want stop after 1", err, calls)
+	}
+}
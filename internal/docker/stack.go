@@ -0,0 +1,234 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	labelProject = "com.docker.compose.project"
+	labelService = "com.docker.compose.service"
+)
+
+// ContainerStatus is the state of one stack container as reported by
+// `status` and the web dashboard.
+type ContainerStatus struct {
+	Name          string     `json:"name" yaml:"name"`
+	Service       string     `json:"service" yaml:"service"`
+	Image         string     `json:"image" yaml:"image"`
+	ImageDigest   string     `json:"image_digest,omitempty" yaml:"image_digest,omitempty"`
+	State         string     `json:"state" yaml:"state"`
+	Health        string     `json:"health,omitempty" yaml:"health,omitempty"`
+	Restarts      int        `json:"restarts" yaml:"restarts"`
+	StartedAt     *time.Time `json:"started_at,omitempty" yaml:"started_at,omitempty"`
+	UptimeSeconds int64      `json:"uptime_seconds" yaml:"uptime_seconds"`
+	CPUPercent    float64    `json:"cpu_percent" yaml:"cpu_percent"`
+	MemoryUsedMB  int        `json:"memory_used_mb" yaml:"memory_used_mb"`
+	MemoryLimitMB int        `json:"memory_limit_mb" yaml:"memory_limit_mb"`
+	GPUDevices    []string   `json:"gpu_devices" yaml:"gpu_devices"`
+	Error         string     `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Running reports whether the container is up.
+func (s ContainerStatus) Running() bool {
+	return s.State == "running"
+}
+
+// Uptime returns how long a running container has been up.
+func (s ContainerStatus) Uptime() time.Duration {
+	return time.Duration(s.UptimeSeconds) * time.Second
+}
+
+// FindStackContainers returns the containers of a stack, running or not.
+// Containers are matched by compose project label first; stacks started
+// from another directory get a different project name, so it falls back
+// to the "<stack>-" container name prefix used by generated stacks.
+func (c *Client) FindStackContainers(ctx context.Context, stack string) ([]Container, error) {
+	containers, err := c.ListContainers(ctx, true, map[string][]string{
+		"label": {labelProject + "=" + stack},
+	})
+	if err != nil || len(containers) > 0 {
+		return containers, err
+	}
+
+	candidates, err := c.ListContainers(ctx, true, map[string][]string{
+		"name": {"^/?" + stack + "-"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, container := range candidates {
+		if strings.HasPrefix(container.Name(), stack+"-") {
+			containers = append(containers, container)
+		}
+	}
+	return containers, nil
+}
+
+// serviceAliases maps short names accepted on the command line to the
+// compose services of generated stacks.
+var serviceAliases = map[string]string{
+	"webui": "open-webui",
+}
+
+// FindService returns the container running service in stack. service may
+// be a compose service name, an alias such as "webui", or a container name.
+func (c *Client) FindService(ctx context.Context, stack, service string) (*Container, error) {
+	containers, err := c.FindStackContainers(ctx, stack)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{service}
+	if alias, ok := serviceAliases[service]; ok {
+		names = append(names, alias)
+	}
+	for _, name := range names {
+		for i, container := range containers {
+			if container.Labels[labelService] == name || container.Name() == name || container.Name() == stack+"-"+name {
+				return &containers[i], nil
+			}
+		}
+	}
+
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("no containers found for stack %s", stack)
+	}
+	available := make([]string, len(containers))
+	for i, container := range containers {
+		available[i] = container.Name()
+	}
+	sort.Strings(available)
+	return nil, fmt.Errorf("no %s container in stack %s (found: %s)", service, stack, strings.Join(available, ", "))
+}
+
+// StackStatus inspects each container of a stack and samples its
+// resource usage. Per-container failures are reported in Error rather
+// than failing the whole call.
+func (c *Client) StackStatus(ctx context.Context, stack string) ([]ContainerStatus, error) {
+	containers, err := c.FindStackContainers(ctx, stack)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]ContainerStatus, len(containers))
+	var wg sync.WaitGroup
+	for i, container := range containers {
+		wg.Add(1)
+		go func(i int, container Container) {
+			defer wg.Done()
+			statuses[i] = c.containerStatus(ctx, stack, container)
+		}(i, container)
+	}
+	wg.Wait()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+func (c *Client) containerStatus(ctx context.Context, stack string, container Container) ContainerStatus {
+	status := ContainerStatus{
+		Name:       container.Name(),
+		Service:    container.Labels[labelService],
+		Image:      container.Image,
+		State:      container.State,
+		GPUDevices: []string{},
+	}
+	if status.Service == "" {
+		status.Service = strings.TrimPrefix(status.Name, stack+"-")
+	}
+
+	details, err := c.InspectContainer(ctx, container.ID)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.State = details.State.Status
+	status.Restarts = details.RestartCount
+	if details.Config.Image != "" {
+		status.Image = details.Config.Image
+	}
+	if details.State.Health != nil {
+		status.Health = details.State.Health.Status
+	}
+	if started, err := time.Parse(time.RFC3339Nano, details.State.StartedAt); err == nil && started.Year() > 1 {
+		status.StartedAt = &started
+		if details.State.Running {
+			status.UptimeSeconds = int64(time.Since(started).Seconds())
+		}
+	}
+	status.GPUDevices = gpuDevices(details)
+
+	if image, err := c.InspectImage(ctx, details.Image); err == nil {
+		status.ImageDigest = imageDigest(image)
+	}
+
+	if details.State.Running {
+		stats, err := c.ContainerStats(ctx, container.ID)
+		if err != nil {
+			status.Error = err.Error()
+			return status
+		}
+		status.CPUPercent = stats.CPUPercent()
+		status.MemoryUsedMB = int(stats.MemoryUsed() / (1024 * 1024))
+		status.MemoryLimitMB = int(stats.MemoryStats.Limit / (1024 * 1024))
+	}
+	return status
+}
+
+// gpuDevices lists the GPU devices mapped into a container: AMD and Intel
+// device nodes, NVIDIA device requests and CDI devices.
+func gpuDevices(details *ContainerDetails) []string {
+	devices := []string{}
+	for _, device := range details.HostConfig.Devices {
+		path := device.PathOnHost
+		if strings.HasPrefix(path, "/dev/dri") || path == "/dev/kfd" || strings.HasPrefix(path, "/dev/nvidia") {
+			devices = append(devices, path)
+		}
+	}
+	for _, request := range details.HostConfig.DeviceRequests {
+		switch {
+		case request.Driver == "cdi":
+			devices = append(devices, request.DeviceIDs...)
+		case request.Driver == "nvidia" || hasCapability(request.Capabilities, "gpu"):
+			if len(request.DeviceIDs) == 0 {
+				// Count -1 is "all"; otherwise the first N GPUs.
+				if request.Count > 0 {
+					devices = append(devices, fmt.Sprintf("nvidia.com/gpu=count:%d", request.Count))
+				} else {
+					devices = append(devices, "nvidia.com/gpu=all")
+				}
+			}
+			for _, id := range request.DeviceIDs {
+				devices = append(devices, "nvidia.com/gpu="+id)
+			}
+		}
+	}
+	return devices
+}
+
+func hasCapability(capabilities [][]string, name string) bool {
+	for _, set := range capabilities {
+		for _, capability := range set {
+			if capability == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// imageDigest returns the registry digest an image was pulled by, or its
+// local ID for images that were built or loaded.
+func imageDigest(image *Image) string {
+	for _, ref := range image.RepoDigests {
+		if _, digest, ok := strings.Cut(ref, "@"); ok {
+			return digest
+		}
+	}
+	return image.ID
+}
@@ -0,0 +1,388 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/docker"
+	"github.com/lyleclassen/lite-llm/internal/system"
+)
+
+// Catalogue returns every check, in the order they are run and reported.
+func Catalogue() []Check {
+	return []Check{
+		{Name: "requirements", Title: "Minimum hardware requirements", Run: checkRequirements},
+		{Name: "docker-daemon", Title: "Docker daemon reachable", Run: checkDockerDaemon},
+		{Name: "user-groups", Title: "User in render/video groups", Run: checkUserGroups},
+		{Name: "device-permissions", Title: "/dev/kfd and /dev/dri permissions", Run: checkDevicePermissions},
+		{Name: "rocm-version", Title: "ROCm version supports GPU", Run: checkROCmVersion},
+		{Name: "hsa-override", Title: "HSA_OVERRIDE_GFX_VERSION matches GPU", Run: checkHSAOverride},
+		{Name: "ollama-reachable", Title: "Ollama API reachable", Run: checkOllamaReachable},
+		{Name: "ollama-gpu", Title: "Ollama running models on GPU", Run: checkOllamaGPU},
+		{Name: "ports", Title: "Service ports available", Run: checkPorts},
+		{Name: "disk-space", Title: "Disk space for models", Run: checkDiskSpace},
+	}
+}
+
+func pass(format string, args ...interface{}) Result {
+	return Result{Status: StatusPass, Message: fmt.Sprintf(format, args...)}
+}
+
+func skip(format string, args ...interface{}) Result {
+	return Result{Status: StatusSkip, Message: fmt.Sprintf(format, args...)}
+}
+
+func problem(status Status, message, explanation, remediation string) Result {
+	return Result{Status: status, Message: message, Explanation: explanation, Remediation: remediation}
+}
+
+// usesROCm reports whether the AMD/ROCm checks apply to this host.
+func usesROCm(env *Env) bool {
+	return env.Config.GPUType == "amd" || (env.Info != nil && env.Info.HasAMDGPU)
+}
+
+func checkRequirements(ctx context.Context, env *Env) Result {
+	unmet := env.Checker.RequirementErrors(env.Info, env.Config.GPUType)
+	if len(unmet) == 0 {
+		return pass("GPU %s (%s), %d MB system memory", env.Info.GPUModel, system.FormatMemoryMB(env.Info.GPUMemory), env.Info.SystemMemory)
+	}
+	return problem(StatusWarn, strings.Join(unmet, "; "),
+		"Models are sized against available VRAM and RAM; below these minimums most 7B/8B models will not fit and will fall back to CPU.",
+		"lite-llm models recommended   # downloads models sized for 8GB cards; pick smaller quantizations on less")
+}
+
+func checkDockerDaemon(ctx context.Context, env *Env) Result {
+	socket := env.Config.DockerSocket
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := docker.NewClient(socket).Ping(ctx)
+	var apiErr *docker.APIError
+	if errors.As(err, &apiErr) {
+		return problem(StatusFail, fmt.Sprintf("Docker ping returned status %d", apiErr.StatusCode),
+			"The Docker daemon is running but unhealthy.",
+			"sudo systemctl restart docker && sudo journalctl -u docker --since '10 min ago'")
+	}
+	if err != nil {
+		if errors.Is(err, syscall.EACCES) {
+			return problem(StatusFail, fmt.Sprintf("Permission denied on %s", socket),
+				"Your user cannot talk to the Docker daemon, so Portainer stacks and lite-llm's container checks cannot run.",
+				"sudo usermod -aG docker $USER && newgrp docker")
+		}
+		return problem(StatusFail, fmt.Sprintf("Cannot reach Docker at %s: %v", socket, errors.Unwrap(err)),
+			"The Ollama and Open WebUI containers need a running Docker daemon.",
+			"sudo systemctl enable --now docker")
+	}
+
+	return pass("Docker daemon responding on %s", socket)
+}
+
+func checkUserGroups(ctx context.Context, env *Env) Result {
+	if !usesROCm(env) {
+		return skip("Not required for %s GPUs", env.Config.GPUType)
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		return skip("Cannot determine current user: %v", err)
+	}
+	if current.Uid == "0" {
+		return pass("Running as root")
+	}
+
+	groupIDs, err := current.GroupIds()
+	if err != nil {
+		return skip("Cannot list groups: %v", err)
+	}
+
+	member := make(map[string]bool)
+	for _, gid := range groupIDs {
+		if g, err := user.LookupGroupId(gid); err == nil {
+			member[g.Name] = true
+		}
+	}
+
+	var missing []string
+	for _, name := range []string{"render", "video"} {
+		if !member[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return problem(StatusWarn, fmt.Sprintf("%s is not in group(s): %s", current.Username, strings.Join(missing, ", ")),
+			"ROCm tools on the host (rocminfo, rocm-smi) need the render and video groups to open the GPU. Containers are unaffected when run through Docker.",
+			fmt.Sprintf("sudo usermod -aG %s $USER   # then log out and back in", strings.Join(missing, ",")))
+	}
+
+	return pass("%s is in render and video", current.Username)
+}
+
+func checkDevicePermissions(ctx context.Context, env *Env) Result {
+	if !usesROCm(env) {
+		return skip("Not required for %s GPUs", env.Config.GPUType)
+	}
+
+	devices := []string{"/dev/kfd"}
+	renderNodes, _ := filepath.Glob("/dev/dri/renderD*")
+	devices = append(devices, renderNodes...)
+
+	var missing, denied []string
+	for _, dev := range devices {
+		if _, err := os.Stat(dev); err != nil {
+			missing = append(missing, dev)
+			continue
+		}
+		// R_OK|W_OK
+		if err := syscall.Access(dev, 0x4|0x2); err != nil {
+			denied = append(denied, dev)
+		}
+	}
+	if len(renderNodes) == 0 {
+		missing = append(missing, "/dev/dri/renderD*")
+	}
+
+	if len(missing) > 0 {
+		return problem(StatusFail, fmt.Sprintf("Missing device(s): %s", strings.Join(missing, ", ")),
+			"The amdgpu kernel driver creates /dev/kfd (compute) and /dev/dri/renderD* (render nodes). Without them the ROCm container cannot see the GPU.",
+			"sudo modprobe amdgpu && ls -l /dev/kfd /dev/dri")
+	}
+	if len(denied) > 0 {
+		return problem(StatusWarn, fmt.Sprintf("No read/write access to: %s", strings.Join(denied, ", ")),
+			"Your user cannot open the GPU device nodes, so host-side ROCm tools will fail. Docker containers running as root are unaffected.",
+			"sudo usermod -aG render,video $USER   # or check /etc/udev/rules.d/70-rocm.rules")
+	}
+
+	return pass("%s accessible", strings.Join(devices, ", "))
+}
+
+func checkROCmVersion(ctx context.Context, env *Env) Result {
+	if !usesROCm(env) {
+		return skip("Not required for %s GPUs", env.Config.GPUType)
+	}
+
+	target := env.Checker.DetectGFXTarget()
+	if target == "" {
+		return problem(StatusWarn, "No ROCm-capable GPU agent found",
+			"Neither the KFD topology nor rocminfo reported a GPU, so ROCm cannot use the card. The amdgpu driver may not be loaded.",
+			"ls /sys/class/kfd/kfd/topology/nodes && sudo dmesg | grep -i amdgpu")
+	}
+
+	version := env.Checker.ROCmVersion()
+	if version == "" {
+		version = "not installed on host"
+	}
+
+	info, known := system.LookupGFX(target)
+	switch {
+	case !known:
+		return problem(StatusWarn, fmt.Sprintf("GPU target %s is not in lite-llm's support table (ROCm %s)", target, version),
+			"lite-llm does not know whether ROCm supports this GPU or which override it needs.",
+			"Check https://rocm.docs.amd.com/projects/install-on-linux/en/latest/reference/system-requirements.html")
+	case info.Supported:
+		return pass("%s (%s) is supported natively; ROCm %s", target, info.Cards, version)
+	case info.Override != "":
+		return pass("%s (%s) works with HSA_OVERRIDE_GFX_VERSION=%s; ROCm %s", target, info.Cards, info.Override, version)
+	default:
+		return problem(StatusFail, fmt.Sprintf("%s (%s) is not supported by current ROCm", target, info.Cards),
+			info.Notes,
+			"lite-llm stack generate --gpu cpu   # or use an Ollama build with a Vulkan backend")
+	}
+}
+
+func checkHSAOverride(ctx context.Context, env *Env) Result {
+	if !usesROCm(env) {
+		return skip("Not required for %s GPUs", env.Config.GPUType)
+	}
+
+	target := env.Checker.DetectGFXTarget()
+	if target == "" {
+		return skip("GPU target unknown")
+	}
+
+	recommended := system.ROCmEnvFor(target).HSAOverrideGFXVersion
+	override := env.Config.OverrideVersion
+	if override == "none" {
+		if recommended == "" {
+			return pass("No override needed for %s", target)
+		}
+		return problem(StatusWarn, fmt.Sprintf("Override disabled but %s needs HSA_OVERRIDE_GFX_VERSION=%s", target, recommended),
+			"ROCm ships no kernels for this GPU; without the override Ollama falls back to CPU.",
+			"Remove gpu.override_version from ~/.lite-llm.yaml and regenerate the stack: lite-llm stack generate")
+	}
+	if override == "" {
+		if recommended == "" {
+			return pass("No override needed for %s", target)
+		}
+		return pass("Override %s will be applied automatically for %s", recommended, target)
+	}
+
+	native, err := system.GFXVersion(target)
+	if err != nil {
+		return skip("Cannot parse GPU target %s", target)
+	}
+
+	// Overrides only work within an ISA family: forcing gfx1030 kernels
+	// onto a gfx803 card crashes or hangs the GPU.
+	if strings.SplitN(native, ".", 2)[0] != strings.SplitN(override, ".", 2)[0] {
+		fix := "Remove gpu.override_version from ~/.lite-llm.yaml and regenerate the stack: lite-llm stack generate"
+		if recommended != "" {
+			fix = fmt.Sprintf("Set gpu.override_version: \"%s\" in ~/.lite-llm.yaml and regenerate the stack: lite-llm stack generate", recommended)
+		}
+		return problem(StatusFail, fmt.Sprintf("Override %s does not match GPU %s (%s)", override, target, native),
+			"HSA_OVERRIDE_GFX_VERSION makes ROCm load kernels compiled for a different GPU. Across ISA families the kernels are incompatible and inference crashes or silently falls back to CPU.",
+			fix)
+	}
+
+	if recommended != "" && override != recommended {
+		return problem(StatusWarn, fmt.Sprintf("Override %s differs from the known-good %s for %s", override, recommended, target),
+			"The configured override is in the right ISA family but is not the one known to work for this GPU.",
+			fmt.Sprintf("Set gpu.override_version: \"%s\" in ~/.lite-llm.yaml and regenerate the stack", recommended))
+	}
+
+	return pass("Override %s is compatible with %s", override, target)
+}
+
+func checkOllamaReachable(ctx context.Context, env *Env) Result {
+	reqCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := env.Ollama.Health(reqCtx); err != nil {
+		return problem(StatusFail, fmt.Sprintf("Cannot reach Ollama at %s: %v", env.Config.OllamaURL, err),
+			"All model management and the web interfaces go through the Ollama API.",
+			"docker ps --filter name=ollama && docker logs --tail 50 $(docker ps -qf name=ollama)")
+	}
+
+	return pass("Ollama responding at %s", env.Config.OllamaURL)
+}
+
+func checkOllamaGPU(ctx context.Context, env *Env) Result {
+	if env.Config.GPUType == "cpu" {
+		return skip("CPU-only deployment")
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	running, err := env.Ollama.ListRunning(reqCtx)
+	if err != nil {
+		return skip("Ollama unavailable")
+	}
+
+	if len(running) == 0 {
+		return problem(StatusWarn, "No model loaded, cannot verify GPU offload",
+			"Ollama only reports GPU placement for loaded models.",
+			"curl -s http://localhost:11434/api/generate -d '{\"model\":\"<model>\",\"prompt\":\"hi\",\"stream\":false}' && lite-llm doctor")
+	}
+
+	var onCPU, partial []string
+	for _, m := range running {
+		switch {
+		case m.SizeVRAM == 0:
+			onCPU = append(onCPU, m.Name)
+		case m.SizeVRAM < m.Size:
+			partial = append(partial, fmt.Sprintf("%s (%.0f%% GPU)", m.Name, float64(m.SizeVRAM)/float64(m.Size)*100))
+		}
+	}
+
+	if len(onCPU) > 0 {
+		return problem(StatusFail, fmt.Sprintf("Running on CPU: %s", strings.Join(onCPU, ", ")),
+			"Ollama could not initialise the GPU and fell back to CPU inference, which is 10-50x slower. This is usually a missing device mapping or a wrong HSA override.",
+			"docker logs $(docker ps -qf name=ollama) 2>&1 | grep -iE 'rocm|gpu|amdgpu'")
+	}
+	if len(partial) > 0 {
+		return problem(StatusWarn, fmt.Sprintf("Partially offloaded: %s", strings.Join(partial, ", ")),
+			"The model does not fit in VRAM, so some layers run on CPU.",
+			"Use a smaller quantization, e.g. lite-llm models download llama3.1:8b-instruct-q4_K_M")
+	}
+
+	return pass("%d loaded model(s) fully on GPU", len(running))
+}
+
+func checkPorts(ctx context.Context, env *Env) Result {
+	ports := []struct {
+		name     string
+		port     int
+		expected func() bool
+	}{
+		{"Ollama", env.Config.OllamaPort, func() bool { return env.Ollama.Health(ctx) == nil }},
+		{"Open WebUI", env.Config.WebUIPort, func() bool { return respondsHTTP(env.Config.WebUIPort) }},
+		{"lite-llm serve", env.Config.ServePort, func() bool { return respondsHTTP(env.Config.ServePort) }},
+	}
+
+	var conflicts, inUse []string
+	for _, p := range ports {
+		ln, err := net.Listen("tcp", fmt.Sprintf(":%d", p.port))
+		if err == nil {
+			ln.Close()
+			continue
+		}
+		if p.expected() {
+			inUse = append(inUse, fmt.Sprintf("%d (%s)", p.port, p.name))
+		} else {
+			conflicts = append(conflicts, fmt.Sprintf("%d (%s)", p.port, p.name))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return problem(StatusFail, fmt.Sprintf("Port(s) taken by another process: %s", strings.Join(conflicts, ", ")),
+			"Another program is bound to a port the stack needs, so the container will fail to start.",
+			"sudo ss -ltnp | grep -E ':("+joinPorts(env)+")\\b'   # or choose other ports: lite-llm stack generate --ollama-port N --webui-port N")
+	}
+	if len(inUse) > 0 {
+		return pass("In use by the expected services: %s", strings.Join(inUse, ", "))
+	}
+	return pass("Ports %s are free", strings.ReplaceAll(joinPorts(env), "|", ", "))
+}
+
+func joinPorts(env *Env) string {
+	return fmt.Sprintf("%d|%d|%d", env.Config.OllamaPort, env.Config.WebUIPort, env.Config.ServePort)
+}
+
+func respondsHTTP(port int) bool {
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("http://localhost:%d", port))
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return true
+}
+
+func checkDiskSpace(ctx context.Context, env *Env) Result {
+	dir := env.Config.ModelsDir
+	// Walk up to the nearest existing directory, e.g. before the first pull
+	for dir != "/" {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return skip("Cannot stat %s: %v", dir, err)
+	}
+
+	freeGB := float64(stat.Bavail) * float64(stat.Bsize) / (1024 * 1024 * 1024)
+	switch {
+	case freeGB < 5:
+		return problem(StatusFail, fmt.Sprintf("Only %.1f GB free on %s", freeGB, dir),
+			"A single 7B/8B model needs 4-5 GB; pulls will fail part way through.",
+			"lite-llm models list && lite-llm models remove <unused-model>   # or set models.dir to a larger disk")
+	case freeGB < 20:
+		return problem(StatusWarn, fmt.Sprintf("%.1f GB free on %s", freeGB, dir),
+			"There is room for only a few more models.",
+			"lite-llm models list && lite-llm models remove <unused-model>")
+	}
+
+	return pass("%.1f GB free on %s", freeGB, dir)
+}
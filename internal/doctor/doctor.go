@@ -0,0 +1,100 @@
+package doctor
+
+import (
+	"context"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/lyleclassen/lite-llm/internal/system"
+)
+
+// Status is the outcome of a single check.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Result is what a check reports: a short message, a longer explanation
+// of why it matters, and a copy-pasteable remediation when not passing.
+type Result struct {
+	Name        string `json:"name" yaml:"name"`
+	Title       string `json:"title" yaml:"title"`
+	Status      Status `json:"status" yaml:"status"`
+	Message     string `json:"message" yaml:"message"`
+	Explanation string `json:"explanation,omitempty" yaml:"explanation,omitempty"`
+	Remediation string `json:"remediation,omitempty" yaml:"remediation,omitempty"`
+}
+
+// Check is a named diagnostic in the catalogue.
+type Check struct {
+	Name  string
+	Title string
+	Run   func(ctx context.Context, env *Env) Result
+}
+
+// Config holds the settings the checks verify against.
+type Config struct {
+	OllamaURL       string
+	OllamaPort      int
+	WebUIPort       int
+	ServePort       int
+	GPUType         string
+	OverrideVersion string
+	ModelsDir       string
+	DockerSocket    string
+}
+
+// Env is shared by all checks so expensive detection runs only once.
+type Env struct {
+	Config  Config
+	Checker *system.Checker
+	Ollama  *ollama.Client
+	Info    *system.SystemInfo
+}
+
+// Report is the full doctor output, suitable for attaching to bug reports.
+type Report struct {
+	Timestamp time.Time          `json:"timestamp" yaml:"timestamp"`
+	System    *system.SystemInfo `json:"system" yaml:"system"`
+	Results   []Result           `json:"results" yaml:"results"`
+	Summary   map[Status]int     `json:"summary" yaml:"summary"`
+}
+
+// Failed reports whether any check failed.
+func (r *Report) Failed() bool {
+	return r.Summary[StatusFail] > 0
+}
+
+// Run executes every check in the catalogue.
+func Run(ctx context.Context, cfg Config) *Report {
+	checker := system.NewChecker()
+	checker.SetDockerSocket(cfg.DockerSocket)
+	info, _ := checker.GetSystemInfo()
+
+	env := &Env{
+		Config:  cfg,
+		Checker: checker,
+		Ollama:  ollama.NewClient(cfg.OllamaURL),
+		Info:    info,
+	}
+
+	report := &Report{
+		Timestamp: time.Now(),
+		System:    info,
+		Summary:   make(map[Status]int),
+	}
+
+	for _, check := range Catalogue() {
+		result := check.Run(ctx, env)
+		result.Name = check.Name
+		result.Title = check.Title
+		report.Results = append(report.Results, result)
+		report.Summary[result.Status]++
+	}
+
+	return report
+}
@@ -0,0 +1,297 @@
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+)
+
+// Client browses the Ollama library. The website has no JSON API for
+// search and tags, so those pages are parsed; sizes and quantizations the
+// pages don't show are read from the registry's manifests. Both URLs can
+// point at a mirror or a fixture server.
+type Client struct {
+	baseURL     string
+	registryURL string
+	httpClient  *http.Client
+}
+
+func NewClient(baseURL, registryURL string) *Client {
+	return &Client{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		registryURL: strings.TrimSuffix(registryURL, "/"),
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Model is a search result.
+type Model struct {
+	Name         string   `json:"name" yaml:"name"`
+	Description  string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Sizes        []string `json:"sizes,omitempty" yaml:"sizes,omitempty"` // parameter counts, e.g. "8b"
+	Capabilities []string `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+	Pulls        string   `json:"pulls,omitempty" yaml:"pulls,omitempty"`
+	Updated      string   `json:"updated,omitempty" yaml:"updated,omitempty"`
+}
+
+// Tag is one tag of a model.
+type Tag struct {
+	Name         string `json:"name" yaml:"name"` // e.g. "mistral:7b-instruct-q4_K_M"
+	SizeBytes    int64  `json:"size_bytes" yaml:"size_bytes"`
+	Quantization string `json:"quantization,omitempty" yaml:"quantization,omitempty"`
+	Parameters   string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Digest       string `json:"digest,omitempty" yaml:"digest,omitempty"`
+}
+
+var (
+	searchItemMarker = "x-test-model"
+	titlePattern     = regexp.MustCompile(`x-test-search-response-title[^>]*>([^<]+)<`)
+	descPattern      = regexp.MustCompile(`<p[^>]*>([^<]+)</p>`)
+	sizePattern      = regexp.MustCompile(`x-test-size[^>]*>([^<]+)<`)
+	capPattern       = regexp.MustCompile(`x-test-capability[^>]*>([^<]+)<`)
+	pullsPattern     = regexp.MustCompile(`x-test-pull-count[^>]*>([^<]+)<`)
+	updatedPattern   = regexp.MustCompile(`x-test-updated[^>]*>([^<]+)<`)
+	markupPattern    = regexp.MustCompile(`<[^>]*>`)
+	bytesPattern     = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([KMGT]B)\b`)
+	digestPattern    = regexp.MustCompile(`\b[0-9a-f]{12}\b`)
+	quantPattern     = regexp.MustCompile(`(?i)(?:^|-)(q\d(?:_[a-z0-9]+)*|iq\d(?:_[a-z0-9]+)*|fp16|fp32|bf16|f16)$`)
+)
+
+// Search returns the library models matching query, most popular first as
+// the library orders them.
+func (c *Client) Search(ctx context.Context, query string) ([]Model, error) {
+	page, err := c.getPage(ctx, c.baseURL+"/search?q="+url.QueryEscape(query))
+	if err != nil {
+		return nil, err
+	}
+
+	var models []Model
+	items := strings.Split(page, searchItemMarker)
+	for _, item := range items[1:] {
+		title := firstMatch(titlePattern, item)
+		if title == "" {
+			continue
+		}
+		models = append(models, Model{
+			Name:         title,
+			Description:  firstMatch(descPattern, item),
+			Sizes:        allMatches(sizePattern, item),
+			Capabilities: allMatches(capPattern, item),
+			Pulls:        firstMatch(pullsPattern, item),
+			Updated:      firstMatch(updatedPattern, item),
+		})
+	}
+	return models, nil
+}
+
+// Tags returns the tags of a library model such as "mistral" or
+// "user/model".
+func (c *Client) Tags(ctx context.Context, name string) ([]Tag, error) {
+	repo := repository(name)
+	page, err := c.getPage(ctx, c.baseURL+"/"+repo+"/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	// Each tag links to /<repo>:<tag>; its size and digest follow the link.
+	linkPattern := regexp.MustCompile(`href="/` + regexp.QuoteMeta(repo) + `:([^"]+)"`)
+	links := linkPattern.FindAllStringSubmatchIndex(page, -1)
+	short := strings.TrimPrefix(repo, "library/")
+
+	var tags []Tag
+	seen := map[string]int{}
+	for i, link := range links {
+		tag := html.UnescapeString(page[link[2]:link[3]])
+		end := len(page)
+		if i+1 < len(links) {
+			end = links[i+1][0]
+		}
+		text := markupPattern.ReplaceAllString(page[link[1]:end], " ")
+
+		idx, ok := seen[tag]
+		if !ok {
+			idx = len(tags)
+			seen[tag] = idx
+			tags = append(tags, Tag{Name: short + ":" + tag, Quantization: quantization(tag)})
+		}
+		if tags[idx].SizeBytes == 0 {
+			if m := bytesPattern.FindStringSubmatch(text); m != nil {
+				tags[idx].SizeBytes = parseSize(m[1], m[2])
+			}
+		}
+		if tags[idx].Digest == "" {
+			tags[idx].Digest = digestPattern.FindString(text)
+		}
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tags found for %s", name)
+	}
+
+	// Fill in what the page doesn't say from the registry.
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 4)
+	for i := range tags {
+		if tags[i].Quantization != "" && tags[i].SizeBytes != 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(t *Tag) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if details, err := c.Describe(ctx, t.Name); err == nil {
+				if t.Quantization == "" {
+					t.Quantization = details.Quantization
+				}
+				if t.SizeBytes == 0 {
+					t.SizeBytes = details.SizeBytes
+				}
+				t.Parameters = details.Parameters
+			}
+		}(&tags[i])
+	}
+	wg.Wait()
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].SizeBytes < tags[j].SizeBytes })
+	return tags, nil
+}
+
+// Describe reads a tag's exact size, quantization and parameter count
+// from its manifest and config in the registry.
+func (c *Client) Describe(ctx context.Context, name string) (*Tag, error) {
+	parsed, err := ollama.ParseModelName(name)
+	if err != nil {
+		return nil, err
+	}
+	repo := parsed.Namespace + "/" + parsed.Model
+
+	manifest, err := c.Manifest(ctx, repo, parsed.Tag)
+	if err != nil {
+		return nil, err
+	}
+	tag := &Tag{Name: name, SizeBytes: manifest.Size()}
+
+	var config struct {
+		FileType  string `json:"file_type"`
+		ModelType string `json:"model_type"`
+	}
+	if manifest.Config.Digest != "" {
+		body, err := c.get(ctx, c.registryURL+"/v2/"+repo+"/blobs/"+manifest.Config.Digest, "")
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		if err := json.NewDecoder(io.LimitReader(body, 1<<20)).Decode(&config); err != nil {
+			return nil, fmt.Errorf("invalid model config: %w", err)
+		}
+	}
+	tag.Quantization = config.FileType
+	tag.Parameters = config.ModelType
+	return tag, nil
+}
+
+// Manifest fetches a tag's manifest from the registry.
+func (c *Client) Manifest(ctx context.Context, repo, tag string) (*ollama.Manifest, error) {
+	body, err := c.get(ctx, c.registryURL+"/v2/"+repo+"/manifests/"+tag, "application/vnd.docker.distribution.manifest.v2+json")
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var manifest ollama.Manifest
+	if err := json.NewDecoder(io.LimitReader(body, 1<<20)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+func (c *Client) getPage(ctx context.Context, u string) (string, error) {
+	body, err := c.get(ctx, u, "text/html")
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, 8<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", u, err)
+	}
+	return string(data), nil
+}
+
+func (c *Client) get(ctx context.Context, u, accept string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the model library: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("not found in the model library: %s", u)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("model library request failed with status: %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// repository maps "mistral" to "library/mistral" and leaves "user/model"
+// alone; a tag is dropped.
+func repository(name string) string {
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	if !strings.Contains(name, "/") {
+		return "library/" + name
+	}
+	return name
+}
+
+// quantization reads the quantization from a tag such as
+// "7b-instruct-q4_K_M"; tags without one (e.g. "7b") return "".
+func quantization(tag string) string {
+	m := quantPattern.FindStringSubmatch(tag)
+	if m == nil {
+		return ""
+	}
+	return strings.ToUpper(m[1])
+}
+
+func parseSize(value, unit string) int64 {
+	n, _ := strconv.ParseFloat(value, 64)
+	shift := map[string]uint{"KB": 10, "MB": 20, "GB": 30, "TB": 40}[unit]
+	return int64(n * float64(int64(1)<<shift))
+}
+
+func firstMatch(pattern *regexp.Regexp, s string) string {
+	if m := pattern.FindStringSubmatch(s); m != nil {
+		return strings.TrimSpace(html.UnescapeString(m[1]))
+	}
+	return ""
+}
+
+func allMatches(pattern *regexp.Regexp, s string) []string {
+	var values []string
+	for _, m := range pattern.FindAllStringSubmatch(s, -1) {
+		values = append(values, strings.TrimSpace(html.UnescapeString(m[1])))
+	}
+	return values
+}
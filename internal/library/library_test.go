@@ -0,0 +1,150 @@
+package library
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// standIn serves saved library pages and registry responses from testdata
+// by URL path.
+func standIn(t *testing.T, files map[string]string) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Errorf("fixture %s: %v", name, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL+"/", srv.URL)
+}
+
+func TestSearch(t *testing.T) {
+	client := standIn(t, map[string]string{"/search": "search-mistral.html"})
+
+	models, err := client.Search(context.Background(), "mistral")
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	want := []Model{
+		{
+			Name:         "mistral",
+			Description:  "The 7B model released by Mistral AI, updated to version 0.3.",
+			Sizes:        []string{"7b"},
+			Capabilities: []string{"tools"},
+			Pulls:        "17.2M",
+			Updated:      "3 months ago",
+		},
+		{
+			Name:         "mistral-nemo",
+			Description:  "A state-of-the-art 12B model with 128k context length, built by Mistral AI in collaboration with NVIDIA & more.",
+			Sizes:        []string{"12b"},
+			Capabilities: []string{"tools"},
+			Pulls:        "2.6M",
+			Updated:      "3 months ago",
+		},
+		{
+			Name:         "mixtral",
+			Description:  "A set of Mixture of Experts (MoE) model with open weights by Mistral AI in 8x7b and 8x22b parameter sizes.",
+			Sizes:        []string{"8x7b", "8x22b"},
+			Capabilities: []string{"tools"},
+			Pulls:        "1.1M",
+			Updated:      "10 months ago",
+		},
+	}
+	if !reflect.DeepEqual(models, want) {
+		t.Errorf("Search:\n got %+v\nwant %+v", models, want)
+	}
+}
+
+func TestSearchNoResults(t *testing.T) {
+	client := standIn(t, map[string]string{"/search": "tags-mistral.html"})
+
+	models, err := client.Search(context.Background(), "nothing")
+	if err != nil || len(models) != 0 {
+		t.Errorf("Search = %v, %v; want no models", models, err)
+	}
+}
+
+func TestTags(t *testing.T) {
+	client := standIn(t, map[string]string{
+		"/library/mistral/tags":                "tags-mistral.html",
+		"/v2/library/mistral/manifests/latest": "manifest-latest.json",
+		"/v2/library/mistral/blobs/sha256:42347cd80dc868877d7c6eb6a8bb8ec1cf1a8c0cd9e3cdb9cd1c87dd25fc3ed0": "config-latest.json",
+	})
+
+	tags, err := client.Tags(context.Background(), "mistral")
+	if err != nil {
+		t.Fatalf("Tags: %v", err)
+	}
+	// Each tag appears twice (mobile and desktop rows) and is listed
+	// once, smallest first. "latest" names no quantization, so it is
+	// read from the registry.
+	want := []Tag{
+		{Name: "mistral:latest", SizeBytes: parseSize("4.1", "GB"), Quantization: "Q4_0", Parameters: "7.2B", Digest: "f974a74358d6"},
+		{Name: "mistral:7b-instruct-q4_K_M", SizeBytes: parseSize("4.4", "GB"), Quantization: "Q4_K_M", Digest: "1a85656b5d8a"},
+		{Name: "mistral:7b-instruct-v0.3-q8_0", SizeBytes: parseSize("7.7", "GB"), Quantization: "Q8_0", Digest: "5f6e8ae1b8b1"},
+		{Name: "mistral:7b-instruct-fp16", SizeBytes: parseSize("14", "GB"), Quantization: "FP16", Digest: "b4a6c2e3f7d0"},
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("Tags:\n got %+v\nwant %+v", tags, want)
+	}
+}
+
+func TestTagsErrors(t *testing.T) {
+	client := standIn(t, map[string]string{"/library/mistral/tags": "search-mistral.html"})
+
+	if _, err := client.Tags(context.Background(), "mistral"); err == nil || !strings.Contains(err.Error(), "no tags found") {
+		t.Errorf("Tags on a page without tags: %v", err)
+	}
+	if _, err := client.Tags(context.Background(), "someone/missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Tags for a missing model: %v", err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	client := standIn(t, map[string]string{
+		"/v2/library/mistral/manifests/latest": "manifest-latest.json",
+		"/v2/library/mistral/blobs/sha256:42347cd80dc868877d7c6eb6a8bb8ec1cf1a8c0cd9e3cdb9cd1c87dd25fc3ed0": "config-latest.json",
+	})
+
+	tag, err := client.Describe(context.Background(), "mistral")
+	if err != nil {
+		t.Fatalf("Describe: %v", err)
+	}
+	want := &Tag{Name: "mistral", SizeBytes: 4372812000 + 801 + 11356 + 30 + 485, Quantization: "Q4_0", Parameters: "7.2B"}
+	if !reflect.DeepEqual(tag, want) {
+		t.Errorf("Describe = %+v, want %+v", tag, want)
+	}
+}
+
+func TestQuantization(t *testing.T) {
+	tests := map[string]string{
+		"7b":                    "",
+		"latest":                "",
+		"7b-instruct-q4_K_M":    "Q4_K_M",
+		"7b-instruct-v0.3-q8_0": "Q8_0",
+		"8x7b-instruct-iq2_xxs": "IQ2_XXS",
+		"7b-instruct-fp16":      "FP16",
+		"q4_0":                  "Q4_0",
+	}
+	for tag, want := range tests {
+		if got := quantization(tag); got != want {
+			t.Errorf("quantization(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
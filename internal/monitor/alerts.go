@@ -0,0 +1,444 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AlertStatus is the state reported to notifiers.
+type AlertStatus string
+
+const (
+	AlertFiring   AlertStatus = "firing"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// AlertConfig is the "alerts" section of the configuration file.
+type AlertConfig struct {
+	Interval       time.Duration    `mapstructure:"interval"`
+	RepeatInterval time.Duration    `mapstructure:"repeat_interval"`
+	Rules          []RuleConfig     `mapstructure:"rules"`
+	Notifiers      []NotifierConfig `mapstructure:"notifiers"`
+}
+
+type RuleConfig struct {
+	Name     string `mapstructure:"name"`
+	Expr     string `mapstructure:"expr"`
+	Severity string `mapstructure:"severity"`
+}
+
+// Rule is a parsed alert condition of the form
+// "<metric> <op> <value> [for <duration>]", e.g. "gpu.vram_percent > 95 for 2m".
+type Rule struct {
+	Name     string
+	Expr     string
+	Severity string
+	Metric   string
+	Op       string
+	Value    string
+	For      time.Duration
+
+	threshold float64
+	numeric   bool
+}
+
+var ruleOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// numericMetrics are the numbers SnapshotFromMetrics produces.
+var numericMetrics = []string{
+	"cpu.usage", "memory.percent", "memory.used_mb",
+	"gpu.usage", "gpu.temp", "gpu.vram_percent", "gpu.vram_used_mb", "gpu.vram_total_mb",
+}
+
+// stateMetrics are the states of the probes serve and monitor register,
+// with the values each can take.
+var stateMetrics = map[string][]string{
+	"ollama.health": {"up", "down"},
+}
+
+// ParseRule parses an alert expression.
+func ParseRule(cfg RuleConfig) (*Rule, error) {
+	fields := strings.Fields(cfg.Expr)
+	if len(fields) != 3 && len(fields) != 5 {
+		return nil, fmt.Errorf("invalid rule %q: expected '<metric> <op> <value> [for <duration>]'", cfg.Expr)
+	}
+
+	rule := &Rule{
+		Name:     cfg.Name,
+		Expr:     cfg.Expr,
+		Severity: cfg.Severity,
+		Metric:   fields[0],
+		Op:       fields[1],
+		Value:    fields[2],
+	}
+	if rule.Name == "" {
+		rule.Name = cfg.Expr
+	}
+	if rule.Severity == "" {
+		rule.Severity = "warning"
+	}
+
+	validOp := false
+	for _, op := range ruleOperators {
+		if rule.Op == op {
+			validOp = true
+			break
+		}
+	}
+	if !validOp {
+		return nil, fmt.Errorf("invalid rule %q: unknown operator %q", cfg.Expr, rule.Op)
+	}
+
+	if states, ok := stateMetrics[rule.Metric]; ok {
+		if rule.Op != "==" && rule.Op != "!=" {
+			return nil, fmt.Errorf("invalid rule %q: %s is a state and can only be compared with == or !=", cfg.Expr, rule.Metric)
+		}
+		if !containsString(states, rule.Value) {
+			return nil, fmt.Errorf("invalid rule %q: %s is one of %s", cfg.Expr, rule.Metric, strings.Join(states, ", "))
+		}
+	} else if containsString(numericMetrics, rule.Metric) {
+		threshold, err := strconv.ParseFloat(rule.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %s requires a numeric value", cfg.Expr, rule.Metric)
+		}
+		rule.threshold = threshold
+		rule.numeric = true
+	} else {
+		return nil, fmt.Errorf("invalid rule %q: unknown metric %q (valid: %s)", cfg.Expr, rule.Metric, strings.Join(metricNames(), ", "))
+	}
+
+	if len(fields) == 5 {
+		if fields[3] != "for" {
+			return nil, fmt.Errorf("invalid rule %q: expected 'for' before duration", cfg.Expr)
+		}
+		d, err := time.ParseDuration(fields[4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %w", cfg.Expr, err)
+		}
+		rule.For = d
+	}
+
+	return rule, nil
+}
+
+// metricNames lists every metric a rule can test.
+func metricNames() []string {
+	names := append([]string(nil), numericMetrics...)
+	for name := range stateMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Snapshot holds the values alert rules are evaluated against. Numeric
+// metrics such as "gpu.temp" live in Numbers; states such as
+// "ollama.health" ("up" or "down") live in States.
+type Snapshot struct {
+	Time    time.Time
+	Numbers map[string]float64
+	States  map[string]string
+}
+
+// SnapshotFromMetrics converts sampled metrics into a Snapshot, omitting
+// values that are unavailable on this host.
+func SnapshotFromMetrics(m PerformanceMetrics) Snapshot {
+	snap := Snapshot{
+		Time: m.Timestamp,
+		Numbers: map[string]float64{
+			"cpu.usage":         m.CPUUsage,
+			"memory.percent":    m.MemoryUsagePercent,
+			"memory.used_mb":    float64(m.MemoryUsedMB),
+			"gpu.vram_used_mb":  float64(m.GPUMemoryUsedMB),
+			"gpu.vram_total_mb": float64(m.GPUMemoryTotalMB),
+		},
+		States: map[string]string{},
+	}
+
+	if m.GPUUsage >= 0 {
+		snap.Numbers["gpu.usage"] = m.GPUUsage
+	}
+	if m.GPUMemoryTotalMB > 0 {
+		snap.Numbers["gpu.vram_percent"] = float64(m.GPUMemoryUsedMB) / float64(m.GPUMemoryTotalMB) * 100
+	}
+	if m.GPUTemperature >= 0 {
+		snap.Numbers["gpu.temp"] = m.GPUTemperature
+	}
+
+	return snap
+}
+
+// matches reports whether the rule condition holds and the observed value.
+// A metric missing from the snapshot never matches.
+func (r *Rule) matches(snap Snapshot) (bool, string) {
+	if r.numeric {
+		v, ok := snap.Numbers[r.Metric]
+		if !ok {
+			return false, ""
+		}
+		observed := strconv.FormatFloat(v, 'f', 1, 64)
+		switch r.Op {
+		case ">":
+			return v > r.threshold, observed
+		case ">=":
+			return v >= r.threshold, observed
+		case "<":
+			return v < r.threshold, observed
+		case "<=":
+			return v <= r.threshold, observed
+		case "==":
+			return v == r.threshold, observed
+		case "!=":
+			return v != r.threshold, observed
+		}
+		return false, observed
+	}
+
+	v, ok := snap.States[r.Metric]
+	if !ok {
+		return false, ""
+	}
+	if r.Op == "==" {
+		return v == r.Value, v
+	}
+	return v != r.Value, v
+}
+
+// Alert is a firing or resolved notification for a rule.
+type Alert struct {
+	Rule     string      `json:"rule"`
+	Expr     string      `json:"expr"`
+	Severity string      `json:"severity"`
+	Status   AlertStatus `json:"status"`
+	Value    string      `json:"value"`
+	StartsAt time.Time   `json:"starts_at"`
+	EndsAt   *time.Time  `json:"ends_at,omitempty"`
+}
+
+// Summary returns a one-line human readable description of the alert.
+func (a Alert) Summary() string {
+	if a.Status == AlertResolved {
+		return fmt.Sprintf("[RESOLVED] %s (%s)", a.Rule, a.Expr)
+	}
+	return fmt.Sprintf("[%s] %s: %s (value %s)", strings.ToUpper(a.Severity), a.Rule, a.Expr, a.Value)
+}
+
+type ruleState struct {
+	pendingSince time.Time
+	firing       bool
+	firedAt      time.Time
+	notifiedAt   time.Time
+	lastValue    string
+}
+
+// AlertEngine evaluates rules against snapshots and notifies on state
+// changes. A rule notifies once when it starts firing and once when it
+// resolves; while firing it is only re-sent after RepeatInterval, if set.
+type AlertEngine struct {
+	rules          []*Rule
+	notifiers      []Notifier
+	repeatInterval time.Duration
+	probes         map[string]func(ctx context.Context) string
+
+	mu     sync.Mutex
+	states map[string]*ruleState
+}
+
+func NewAlertEngine(rules []*Rule, notifiers []Notifier, repeatInterval time.Duration) *AlertEngine {
+	return &AlertEngine{
+		rules:          rules,
+		notifiers:      notifiers,
+		repeatInterval: repeatInterval,
+		probes:         make(map[string]func(ctx context.Context) string),
+		states:         make(map[string]*ruleState),
+	}
+}
+
+// NewAlertEngineFromConfig parses the configured rules and notifiers.
+func NewAlertEngineFromConfig(cfg AlertConfig) (*AlertEngine, error) {
+	var rules []*Rule
+	names := map[string]bool{}
+	for _, rc := range cfg.Rules {
+		rule, err := ParseRule(rc)
+		if err != nil {
+			return nil, err
+		}
+		// Rule state is kept by name.
+		if names[rule.Name] {
+			return nil, fmt.Errorf("duplicate alert rule name %q", rule.Name)
+		}
+		names[rule.Name] = true
+		rules = append(rules, rule)
+	}
+
+	var notifiers []Notifier
+	for _, nc := range cfg.Notifiers {
+		n, err := NewNotifier(nc)
+		if err != nil {
+			return nil, err
+		}
+		notifiers = append(notifiers, n)
+	}
+
+	return NewAlertEngine(rules, notifiers, cfg.RepeatInterval), nil
+}
+
+// AddStateProbe registers a function whose result is exposed to rules as
+// the named state, e.g. "ollama.health".
+func (e *AlertEngine) AddStateProbe(name string, probe func(ctx context.Context) string) {
+	e.probes[name] = probe
+}
+
+// Rules returns the parsed rules.
+func (e *AlertEngine) Rules() []*Rule {
+	return e.rules
+}
+
+// Evaluate checks every rule against snap and returns the alerts whose
+// state changed (or that are due for a repeat notification).
+func (e *AlertEngine) Evaluate(snap Snapshot) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := snap.Time
+	var alerts []Alert
+
+	for _, rule := range e.rules {
+		state, ok := e.states[rule.Name]
+		if !ok {
+			state = &ruleState{}
+			e.states[rule.Name] = state
+		}
+
+		matched, value := rule.matches(snap)
+		if !matched {
+			state.pendingSince = time.Time{}
+			if state.firing {
+				state.firing = false
+				alerts = append(alerts, Alert{
+					Rule:     rule.Name,
+					Expr:     rule.Expr,
+					Severity: rule.Severity,
+					Status:   AlertResolved,
+					Value:    value,
+					StartsAt: state.firedAt,
+					EndsAt:   &now,
+				})
+			}
+			continue
+		}
+
+		state.lastValue = value
+		if state.pendingSince.IsZero() {
+			state.pendingSince = now
+		}
+		if now.Sub(state.pendingSince) < rule.For {
+			continue
+		}
+
+		notify := false
+		if !state.firing {
+			state.firing = true
+			state.firedAt = now
+			notify = true
+		} else if e.repeatInterval > 0 && now.Sub(state.notifiedAt) >= e.repeatInterval {
+			notify = true
+		}
+
+		if notify {
+			state.notifiedAt = now
+			alerts = append(alerts, Alert{
+				Rule:     rule.Name,
+				Expr:     rule.Expr,
+				Severity: rule.Severity,
+				Status:   AlertFiring,
+				Value:    value,
+				StartsAt: state.firedAt,
+			})
+		}
+	}
+
+	return alerts
+}
+
+// Active returns the currently firing alerts.
+func (e *AlertEngine) Active() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var alerts []Alert
+	for _, rule := range e.rules {
+		state, ok := e.states[rule.Name]
+		if !ok || !state.firing {
+			continue
+		}
+		alerts = append(alerts, Alert{
+			Rule:     rule.Name,
+			Expr:     rule.Expr,
+			Severity: rule.Severity,
+			Status:   AlertFiring,
+			Value:    state.lastValue,
+			StartsAt: state.firedAt,
+		})
+	}
+	return alerts
+}
+
+// Run evaluates rules against the latest sample in history every interval
+// until ctx is cancelled.
+func (e *AlertEngine) Run(ctx context.Context, history *History, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			latest := history.Latest()
+			if latest == nil {
+				continue
+			}
+
+			snap := SnapshotFromMetrics(*latest)
+			snap.Time = time.Now()
+			for name, probe := range e.probes {
+				snap.States[name] = probe(ctx)
+			}
+
+			for _, alert := range e.Evaluate(snap) {
+				e.notify(ctx, alert)
+			}
+		}
+	}
+}
+
+func (e *AlertEngine) notify(ctx context.Context, alert Alert) {
+	if alert.Status == AlertFiring {
+		logrus.Warnf("Alert %s", alert.Summary())
+	} else {
+		logrus.Infof("Alert %s", alert.Summary())
+	}
+
+	for _, n := range e.notifiers {
+		if err := n.Notify(ctx, alert); err != nil {
+			logrus.Errorf("Failed to send alert via %s: %v", n.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package monitor
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Rule
+		wantErr string
+	}{
+		{expr: "gpu.vram_percent > 95 for 2m", want: Rule{Metric: "gpu.vram_percent", Op: ">", Value: "95", For: 2 * time.Minute, threshold: 95, numeric: true}},
+		{expr: "gpu.temp >= 85.5", want: Rule{Metric: "gpu.temp", Op: ">=", Value: "85.5", threshold: 85.5, numeric: true}},
+		{expr: "memory.used_mb == 0", want: Rule{Metric: "memory.used_mb", Op: "==", Value: "0", numeric: true}},
+		{expr: "ollama.health == down for 1m", want: Rule{Metric: "ollama.health", Op: "==", Value: "down", For: time.Minute}},
+		{expr: "ollama.health != up", want: Rule{Metric: "ollama.health", Op: "!=", Value: "up"}},
+
+		{expr: "gpu.vram_pct > 95", wantErr: `unknown metric "gpu.vram_pct" (valid: cpu.usage, gpu.temp,`},
+		{expr: "ollama.health > 1", wantErr: "can only be compared with == or !="},
+		{expr: "ollama.health == Down", wantErr: "ollama.health is one of up, down"},
+		{expr: "gpu.temp > hot", wantErr: "requires a numeric value"},
+		{expr: "gpu.temp == hot", wantErr: "requires a numeric value"},
+		{expr: "gpu.temp => 85", wantErr: `unknown operator "=>"`},
+		{expr: "gpu.temp > 85 during 2m", wantErr: "expected 'for'"},
+		{expr: "gpu.temp > 85 for soon", wantErr: "invalid duration"},
+		{expr: "gpu.temp > 85 for", wantErr: "expected '<metric> <op> <value>"},
+		{expr: "", wantErr: "expected '<metric> <op> <value>"},
+	}
+	for _, tt := range tests {
+		rule, err := ParseRule(RuleConfig{Expr: tt.expr})
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseRule(%q) error = %v, want %q", tt.expr, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRule(%q): %v", tt.expr, err)
+			continue
+		}
+		tt.want.Name, tt.want.Expr, tt.want.Severity = tt.expr, tt.expr, "warning"
+		if *rule != tt.want {
+			t.Errorf("ParseRule(%q) = %+v, want %+v", tt.expr, *rule, tt.want)
+		}
+	}
+}
+
+// TestRuleMetrics keeps the metrics rules accept in step with the ones
+// SnapshotFromMetrics produces.
+func TestRuleMetrics(t *testing.T) {
+	snap := SnapshotFromMetrics(PerformanceMetrics{GPUMemoryTotalMB: 8192})
+	var produced []string
+	for name := range snap.Numbers {
+		produced = append(produced, name)
+	}
+	sort.Strings(produced)
+	accepted := append([]string(nil), numericMetrics...)
+	sort.Strings(accepted)
+	if strings.Join(produced, ",") != strings.Join(accepted, ",") {
+		t.Errorf("snapshot has %v, rules accept %v", produced, accepted)
+	}
+}
+
+func TestNewAlertEngineRejectsDuplicateNames(t *testing.T) {
+	_, err := NewAlertEngineFromConfig(AlertConfig{Rules: []RuleConfig{
+		{Name: "gpu", Expr: "gpu.temp > 85"},
+		{Name: "gpu", Expr: "gpu.vram_percent > 95"},
+	}})
+	if err == nil || !strings.Contains(err.Error(), `duplicate alert rule name "gpu"`) {
+		t.Errorf("error = %v, want a duplicate name error", err)
+	}
+
+	// Unnamed rules are named after their expression.
+	_, err = NewAlertEngineFromConfig(AlertConfig{Rules: []RuleConfig{
+		{Expr: "gpu.temp > 85"},
+		{Expr: "gpu.temp > 85"},
+	}})
+	if err == nil {
+		t.Error("two unnamed rules with the same expression were accepted")
+	}
+}
+
+func mustParseRule(t *testing.T, name, expr string) *Rule {
+	t.Helper()
+	rule, err := ParseRule(RuleConfig{Name: name, Expr: expr, Severity: "critical"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rule
+}
+
+func TestEvaluate(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	temp := func(offset time.Duration, value float64) Snapshot {
+		return Snapshot{Time: start.Add(offset), Numbers: map[string]float64{"gpu.temp": value}}
+	}
+
+	// Each step evaluates a snapshot and lists the alerts expected, as
+	// "<rule> <status>".
+	tests := []struct {
+		name   string
+		rules  []*Rule
+		repeat time.Duration
+		steps  []Snapshot
+		want   [][]string
+	}{
+		{
+			name:  "fires at once without for",
+			rules: []*Rule{mustParseRule(t, "hot", "gpu.temp > 85")},
+			steps: []Snapshot{temp(0, 80), temp(time.Second, 90), temp(2*time.Second, 91), temp(3*time.Second, 70)},
+			want:  [][]string{nil, {"hot firing"}, nil, {"hot resolved"}},
+		},
+		{
+			name:  "pending until for has passed",
+			rules: []*Rule{mustParseRule(t, "hot", "gpu.temp > 85 for 2m")},
+			steps: []Snapshot{temp(0, 90), temp(time.Minute, 90), temp(2*time.Minute, 90), temp(3*time.Minute, 90), temp(4*time.Minute, 70)},
+			want:  [][]string{nil, nil, {"hot firing"}, nil, {"hot resolved"}},
+		},
+		{
+			name:  "a dip resets pending without resolving",
+			rules: []*Rule{mustParseRule(t, "hot", "gpu.temp > 85 for 2m")},
+			steps: []Snapshot{temp(0, 90), temp(90*time.Second, 80), temp(2*time.Minute, 90), temp(3*time.Minute, 90), temp(4*time.Minute, 90)},
+			want:  [][]string{nil, nil, nil, nil, {"hot firing"}},
+		},
+		{
+			name:   "repeats while firing",
+			rules:  []*Rule{mustParseRule(t, "hot", "gpu.temp > 85")},
+			repeat: 10 * time.Minute,
+			steps:  []Snapshot{temp(0, 90), temp(5*time.Minute, 90), temp(10*time.Minute, 90), temp(15*time.Minute, 90), temp(20*time.Minute, 90)},
+			want:   [][]string{{"hot firing"}, nil, {"hot firing"}, nil, {"hot firing"}},
+		},
+		{
+			name:  "missing metric resolves",
+			rules: []*Rule{mustParseRule(t, "hot", "gpu.temp > 85")},
+			steps: []Snapshot{temp(0, 90), {Time: start.Add(time.Minute)}},
+			want:  [][]string{{"hot firing"}, {"hot resolved"}},
+		},
+		{
+			name: "rules keep separate state",
+			rules: []*Rule{
+				mustParseRule(t, "hot", "gpu.temp > 85"),
+				mustParseRule(t, "very-hot", "gpu.temp > 95 for 1m"),
+			},
+			steps: []Snapshot{temp(0, 100), temp(time.Minute, 100), temp(2*time.Minute, 90), temp(3*time.Minute, 80)},
+			want:  [][]string{{"hot firing"}, {"very-hot firing"}, {"very-hot resolved"}, {"hot resolved"}},
+		},
+		{
+			name:  "state rule",
+			rules: []*Rule{mustParseRule(t, "ollama-down", "ollama.health == down")},
+			steps: []Snapshot{
+				{Time: start, States: map[string]string{"ollama.health": "up"}},
+				{Time: start.Add(time.Minute), States: map[string]string{"ollama.health": "down"}},
+				{Time: start.Add(2 * time.Minute), States: map[string]string{"ollama.health": "up"}},
+			},
+			want: [][]string{nil, {"ollama-down firing"}, {"ollama-down resolved"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewAlertEngine(tt.rules, nil, tt.repeat)
+			for i, snap := range tt.steps {
+				var got []string
+				for _, alert := range engine.Evaluate(snap) {
+					got = append(got, alert.Rule+" "+string(alert.Status))
+				}
+				if strings.Join(got, ",") != strings.Join(tt.want[i], ",") {
+					t.Errorf("step %d at +%v: alerts %v, want %v", i, snap.Time.Sub(start), got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEvaluateAlertTimes(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	engine := NewAlertEngine([]*Rule{mustParseRule(t, "hot", "gpu.temp > 85 for 1m")}, nil, 0)
+
+	engine.Evaluate(Snapshot{Time: start, Numbers: map[string]float64{"gpu.temp": 90}})
+	firing := engine.Evaluate(Snapshot{Time: start.Add(time.Minute), Numbers: map[string]float64{"gpu.temp": 92.3}})
+	if len(firing) != 1 || !firing[0].StartsAt.Equal(start.Add(time.Minute)) || firing[0].Value != "92.3" || firing[0].Severity != "critical" {
+		t.Fatalf("firing = %+v", firing)
+	}
+	if active := engine.Active(); len(active) != 1 || active[0].Rule != "hot" {
+		t.Errorf("Active = %+v", active)
+	}
+
+	resolved := engine.Evaluate(Snapshot{Time: start.Add(5 * time.Minute), Numbers: map[string]float64{"gpu.temp": 60}})
+	if len(resolved) != 1 || resolved[0].EndsAt == nil || !resolved[0].EndsAt.Equal(start.Add(5*time.Minute)) ||
+		!resolved[0].StartsAt.Equal(start.Add(time.Minute)) {
+		t.Fatalf("resolved = %+v", resolved)
+	}
+	if active := engine.Active(); len(active) != 0 {
+		t.Errorf("Active after resolving = %+v", active)
+	}
+}
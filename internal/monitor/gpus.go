@@ -0,0 +1,190 @@
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GPUMetrics describes a single GPU as exposed by the DRM subsystem.
+type GPUMetrics struct {
+	Card            string  `json:"card"`
+	Vendor          string  `json:"vendor"` // "amd" or "intel"
+	Usage           float64 `json:"usage"`  // -1 if unavailable
+	MemoryUsedMB    int     `json:"memory_used_mb"`
+	MemoryTotalMB   int     `json:"memory_total_mb"`
+	Temperature     float64 `json:"temperature"`       // -1 if unavailable
+	FrequencyMHz    float64 `json:"frequency_mhz"`     // -1 if unavailable
+	MaxFrequencyMHz float64 `json:"max_frequency_mhz"` // -1 if unavailable
+}
+
+// GPUSampler reads GPU metrics repeatedly. Intel usage is measured between
+// consecutive samples, so each periodic caller keeps its own sampler.
+type GPUSampler struct {
+	mu        sync.Mutex
+	intelIdle map[string]idleSample // by card path
+}
+
+type idleSample struct {
+	residencyMs float64
+	at          time.Time
+}
+
+func NewGPUSampler() *GPUSampler {
+	return &GPUSampler{intelIdle: make(map[string]idleSample)}
+}
+
+// GetGPUMetrics takes a single reading. Intel usage needs two, so it is
+// reported as unavailable; use a GPUSampler to track it.
+func GetGPUMetrics() []GPUMetrics {
+	return NewGPUSampler().Sample()
+}
+
+// Sample reads per-card usage from /sys/class/drm. AMD cards that don't
+// report VRAM (e.g. display-only outputs) are skipped; Intel cards are
+// reported with frequency and, from the sampler's second call, usage.
+func (s *GPUSampler) Sample() []GPUMetrics {
+	cards, err := filepath.Glob("/sys/class/drm/card[0-9]*")
+	if err != nil {
+		return nil
+	}
+	sort.Strings(cards)
+
+	var gpus []GPUMetrics
+	for _, card := range cards {
+		name := filepath.Base(card)
+		if strings.Contains(name, "-") {
+			continue // connector, e.g. card0-DP-1
+		}
+
+		device := filepath.Join(card, "device")
+		if vendor, _ := os.ReadFile(filepath.Join(device, "vendor")); strings.TrimSpace(string(vendor)) == "0x8086" {
+			gpus = append(gpus, s.intelGPUMetrics(card))
+			continue
+		}
+
+		total := getAMDGPUMemory(filepath.Join(device, "mem_info_vram_total"))
+		if total <= 0 {
+			continue
+		}
+
+		gpu := GPUMetrics{
+			Card:            name,
+			Vendor:          "amd",
+			Usage:           -1,
+			MemoryUsedMB:    int(getAMDGPUMemory(filepath.Join(device, "mem_info_vram_used")) / (1024 * 1024)),
+			MemoryTotalMB:   int(total / (1024 * 1024)),
+			Temperature:     -1,
+			FrequencyMHz:    -1,
+			MaxFrequencyMHz: -1,
+		}
+
+		if v, ok := readSysfsFloat(filepath.Join(device, "gpu_busy_percent")); ok {
+			gpu.Usage = v
+		}
+
+		gpu.Temperature = readHwmonTemperature(device)
+
+		gpus = append(gpus, gpu)
+	}
+
+	return gpus
+}
+
+// readHwmonTemperature returns the first hwmon temperature of a device in
+// degrees Celsius, or -1.
+func readHwmonTemperature(device string) float64 {
+	temps, _ := filepath.Glob(filepath.Join(device, "hwmon", "hwmon*", "temp1_input"))
+	for _, path := range temps {
+		if milli, ok := readSysfsFloat(path); ok {
+			return milli / 1000
+		}
+	}
+	return -1
+}
+
+// Sysfs locations of Intel GPU frequency and idle residency. i915 exposes
+// them on the card, xe per tile and GT.
+var (
+	intelFreqPaths = [][2]string{
+		{"gt_act_freq_mhz", "gt_max_freq_mhz"},
+		{"device/tile0/gt0/freq0/act_freq", "device/tile0/gt0/freq0/max_freq"},
+	}
+	intelIdlePaths = []string{
+		"gt/gt0/rc6_residency_ms",
+		"device/tile0/gt0/gtidle/idle_residency_ms",
+	}
+)
+
+// intelGPUMetrics reads an i915 or xe card. Neither driver reports a busy
+// percentage, so usage is derived from how much of the time since the
+// sampler's previous call the GT spent in its idle (RC6) state.
+func (s *GPUSampler) intelGPUMetrics(card string) GPUMetrics {
+	gpu := GPUMetrics{
+		Card:            filepath.Base(card),
+		Vendor:          "intel",
+		Usage:           -1,
+		Temperature:     readHwmonTemperature(filepath.Join(card, "device")),
+		FrequencyMHz:    -1,
+		MaxFrequencyMHz: -1,
+	}
+
+	for _, paths := range intelFreqPaths {
+		if v, ok := readSysfsFloat(filepath.Join(card, paths[0])); ok {
+			gpu.FrequencyMHz = v
+			if maxFreq, ok := readSysfsFloat(filepath.Join(card, paths[1])); ok {
+				gpu.MaxFrequencyMHz = maxFreq
+			}
+			break
+		}
+	}
+
+	for _, path := range intelIdlePaths {
+		residency, ok := readSysfsFloat(filepath.Join(card, path))
+		if !ok {
+			continue
+		}
+		now := time.Now()
+
+		s.mu.Lock()
+		last, seen := s.intelIdle[card]
+		s.intelIdle[card] = idleSample{residencyMs: residency, at: now}
+		s.mu.Unlock()
+
+		if elapsed := now.Sub(last.at).Seconds() * 1000; seen && elapsed > 0 {
+			usage := 100 - (residency-last.residencyMs)/elapsed*100
+			gpu.Usage = clampPercent(usage)
+		}
+		break
+	}
+
+	return gpu
+}
+
+func clampPercent(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 100 {
+		return 100
+	}
+	return v
+}
+
+func readSysfsFloat(path string) (float64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
@@ -0,0 +1,287 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// History is a fixed-size, thread-safe ring buffer of metric samples.
+// Once full, the oldest sample is overwritten by each new one.
+type History struct {
+	mu      sync.RWMutex
+	samples []PerformanceMetrics
+	next    int
+	full    bool
+}
+
+func NewHistory(capacity int) *History {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &History{
+		samples: make([]PerformanceMetrics, capacity),
+	}
+}
+
+// Add appends a sample, evicting the oldest one if the buffer is full.
+func (h *History) Add(m PerformanceMetrics) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.samples[h.next] = m
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Len returns the number of samples currently stored.
+func (h *History) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.full {
+		return len(h.samples)
+	}
+	return h.next
+}
+
+// Latest returns the most recent sample, or nil if the history is empty.
+func (h *History) Latest() *PerformanceMetrics {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.full && h.next == 0 {
+		return nil
+	}
+	idx := (h.next - 1 + len(h.samples)) % len(h.samples)
+	latest := h.samples[idx]
+	return &latest
+}
+
+// Since returns the samples taken at or after t, oldest first.
+func (h *History) Since(t time.Time) []PerformanceMetrics {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var result []PerformanceMetrics
+	for _, m := range h.ordered() {
+		if !m.Timestamp.Before(t) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// ordered returns the stored samples oldest first. Callers must hold mu.
+func (h *History) ordered() []PerformanceMetrics {
+	if !h.full {
+		return append([]PerformanceMetrics(nil), h.samples[:h.next]...)
+	}
+	result := make([]PerformanceMetrics, 0, len(h.samples))
+	result = append(result, h.samples[h.next:]...)
+	result = append(result, h.samples[:h.next]...)
+	return result
+}
+
+// Save writes the history to path as JSON, replacing the file atomically.
+func (h *History) Save(path string) error {
+	h.mu.RLock()
+	data, err := json.Marshal(h.ordered())
+	h.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode metrics history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metrics history: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load reads samples previously written by Save. A missing file is not an error.
+func (h *History) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read metrics history: %w", err)
+	}
+
+	var samples []PerformanceMetrics
+	if err := json.Unmarshal(data, &samples); err != nil {
+		return fmt.Errorf("failed to decode metrics history: %w", err)
+	}
+
+	for _, m := range samples {
+		h.Add(m)
+	}
+	return nil
+}
+
+// Downsample averages samples into buckets of the given step, returning one
+// sample per non-empty bucket stamped with the bucket start time. GPU usage
+// and temperature are averaged only over samples where they were available.
+func Downsample(samples []PerformanceMetrics, step time.Duration) []PerformanceMetrics {
+	if step <= 0 || len(samples) == 0 {
+		return samples
+	}
+
+	var result []PerformanceMetrics
+	var bucket []PerformanceMetrics
+	var bucketStart time.Time
+
+	flush := func() {
+		if len(bucket) > 0 {
+			result = append(result, averageMetrics(bucket, bucketStart))
+		}
+		bucket = bucket[:0]
+	}
+
+	for _, m := range samples {
+		start := m.Timestamp.Truncate(step)
+		if !start.Equal(bucketStart) {
+			flush()
+			bucketStart = start
+		}
+		bucket = append(bucket, m)
+	}
+	flush()
+
+	return result
+}
+
+func averageMetrics(samples []PerformanceMetrics, timestamp time.Time) PerformanceMetrics {
+	var avg PerformanceMetrics
+	var memUsed, memTotal, gpuMemUsed, gpuMemTotal int
+	var gpuUsage, gpuTemp float64
+	var gpuSamples, tempSamples int
+
+	for _, m := range samples {
+		avg.CPUUsage += m.CPUUsage
+		avg.MemoryUsagePercent += m.MemoryUsagePercent
+		memUsed += m.MemoryUsedMB
+		memTotal += m.MemoryTotalMB
+		gpuMemUsed += m.GPUMemoryUsedMB
+		gpuMemTotal += m.GPUMemoryTotalMB
+		if m.GPUUsage >= 0 {
+			gpuUsage += m.GPUUsage
+			gpuSamples++
+		}
+		if m.GPUTemperature >= 0 {
+			gpuTemp += m.GPUTemperature
+			tempSamples++
+		}
+	}
+
+	n := len(samples)
+	avg.CPUUsage /= float64(n)
+	avg.MemoryUsagePercent /= float64(n)
+	avg.MemoryUsedMB = memUsed / n
+	avg.MemoryTotalMB = memTotal / n
+	avg.GPUMemoryUsedMB = gpuMemUsed / n
+	avg.GPUMemoryTotalMB = gpuMemTotal / n
+	avg.GPUUsage = -1
+	if gpuSamples > 0 {
+		avg.GPUUsage = gpuUsage / float64(gpuSamples)
+	}
+	avg.GPUTemperature = -1
+	if tempSamples > 0 {
+		avg.GPUTemperature = gpuTemp / float64(tempSamples)
+	}
+	avg.Timestamp = timestamp
+
+	return avg
+}
+
+// Sampler periodically collects PerformanceMetrics into a History.
+type Sampler struct {
+	history  *History
+	interval time.Duration
+	path     string
+	gpus     *GPUSampler
+
+	// Previous /proc/stat reading, used to report CPU usage over the
+	// sampling interval rather than since boot.
+	prevIdle  uint64
+	prevTotal uint64
+}
+
+// NewSampler creates a sampler that records into history every interval.
+// If path is non-empty, the history is loaded from and persisted to it.
+func NewSampler(history *History, interval time.Duration, path string) *Sampler {
+	return &Sampler{
+		history:  history,
+		interval: interval,
+		path:     path,
+		gpus:     NewGPUSampler(),
+	}
+}
+
+// Run samples until ctx is cancelled.
+func (s *Sampler) Run(ctx context.Context) {
+	if s.path != "" {
+		if err := s.history.Load(s.path); err != nil {
+			logrus.Warnf("Failed to load metrics history: %v", err)
+		}
+	}
+
+	// Persist roughly once a minute rather than on every sample
+	saveEvery := int(time.Minute / s.interval)
+	if saveEvery < 1 {
+		saveEvery = 1
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.sample()
+	for n := 1; ; n++ {
+		select {
+		case <-ctx.Done():
+			s.save()
+			return
+		case <-ticker.C:
+			s.sample()
+			if n%saveEvery == 0 {
+				s.save()
+			}
+		}
+	}
+}
+
+func (s *Sampler) sample() {
+	metrics := PerformanceMetricsFor(s.gpus.Sample())
+
+	if idle, total, err := readCPUTimes(); err == nil {
+		if s.prevTotal > 0 && total > s.prevTotal {
+			busy := (total - s.prevTotal) - (idle - s.prevIdle)
+			metrics.CPUUsage = float64(busy) / float64(total-s.prevTotal) * 100
+		}
+		s.prevIdle, s.prevTotal = idle, total
+	}
+
+	s.history.Add(*metrics)
+}
+
+func (s *Sampler) save() {
+	if s.path == "" {
+		return
+	}
+	if err := s.history.Save(s.path); err != nil {
+		logrus.Warnf("Failed to persist metrics history: %v", err)
+	}
+}
@@ -2,7 +2,9 @@ package monitor
 
 import (
 	"bufio"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -11,20 +13,29 @@ import (
 )
 
 type PerformanceMetrics struct {
-	CPUUsage            float64
-	MemoryUsedMB        int
-	MemoryTotalMB       int
-	MemoryUsagePercent  float64
-	GPUUsage            float64
-	GPUMemoryUsedMB     int
-	GPUMemoryTotalMB    int
-	Timestamp           time.Time
+	CPUUsage            float64   `json:"cpu_usage" yaml:"cpu_usage"`
+	MemoryUsedMB        int       `json:"memory_used_mb" yaml:"memory_used_mb"`
+	MemoryTotalMB       int       `json:"memory_total_mb" yaml:"memory_total_mb"`
+	MemoryUsagePercent  float64   `json:"memory_usage_percent" yaml:"memory_usage_percent"`
+	GPUUsage            float64   `json:"gpu_usage" yaml:"gpu_usage"`
+	GPUMemoryUsedMB     int       `json:"gpu_memory_used_mb" yaml:"gpu_memory_used_mb"`
+	GPUMemoryTotalMB    int       `json:"gpu_memory_total_mb" yaml:"gpu_memory_total_mb"`
+	GPUTemperature      float64   `json:"gpu_temperature" yaml:"gpu_temperature"`
+	Timestamp           time.Time `json:"timestamp" yaml:"timestamp"`
 }
 
+// GetPerformanceMetrics takes a single reading of the host's metrics.
 func GetPerformanceMetrics() *PerformanceMetrics {
+	return PerformanceMetricsFor(GetGPUMetrics())
+}
+
+// PerformanceMetricsFor reads the host's metrics, taking Intel GPU usage
+// from gpus, a reading the caller already has.
+func PerformanceMetricsFor(gpus []GPUMetrics) *PerformanceMetrics {
 	metrics := &PerformanceMetrics{
 		Timestamp: time.Now(),
-		GPUUsage:  -1, // -1 indicates unavailable
+		GPUUsage:       -1, // -1 indicates unavailable
+		GPUTemperature: -1,
 	}
 
 	// Get CPU usage
@@ -55,26 +66,57 @@ func GetPerformanceMetrics() *PerformanceMetrics {
 		metrics.GPUMemoryTotalMB = gpuMemTotal
 	}
 
+	if temp, ok := getAMDGPUTemperature(); ok {
+		metrics.GPUTemperature = temp
+	}
+
+	// Intel GPUs have no VRAM counters; report busy and temperature only.
+	if metrics.GPUUsage < 0 {
+		for _, gpu := range gpus {
+			if gpu.Vendor == "intel" && gpu.Usage >= 0 {
+				metrics.GPUUsage = gpu.Usage
+				metrics.GPUTemperature = gpu.Temperature
+				break
+			}
+		}
+	}
+
 	return metrics
 }
 
 func getCPUUsage() (float64, error) {
-	// Read /proc/stat for CPU usage
-	file, err := os.Open("/proc/stat")
+	idle, total, err := readCPUTimes()
 	if err != nil {
 		return 0, err
 	}
+
+	if total == 0 {
+		return 0, nil
+	}
+
+	// Calculate usage percentage
+	usage := float64(total-idle) / float64(total) * 100
+	return usage, nil
+}
+
+// readCPUTimes returns the cumulative idle and total jiffies from the
+// aggregate "cpu" line of /proc/stat.
+func readCPUTimes() (uint64, uint64, error) {
+	file, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, 0, err
+	}
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
 	if !scanner.Scan() {
-		return 0, err
+		return 0, 0, fmt.Errorf("failed to read /proc/stat: %w", scanner.Err())
 	}
 
 	line := scanner.Text()
 	fields := strings.Fields(line)
 	if len(fields) < 8 || fields[0] != "cpu" {
-		return 0, err
+		return 0, 0, fmt.Errorf("unexpected /proc/stat format")
 	}
 
 	// Parse CPU times
@@ -82,7 +124,7 @@ func getCPUUsage() (float64, error) {
 	for i := 1; i < len(fields); i++ {
 		val, err := strconv.ParseUint(fields[i], 10, 64)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 		total += val
 		if i == 4 { // idle time is the 4th field
@@ -90,13 +132,7 @@ func getCPUUsage() (float64, error) {
 		}
 	}
 
-	if total == 0 {
-		return 0, nil
-	}
-
-	// Calculate usage percentage
-	usage := float64(total-idle) / float64(total) * 100
-	return usage, nil
+	return idle, total, nil
 }
 
 func getMemoryUsage() (int, int, error) {
@@ -199,4 +235,27 @@ func getAMDGPUUsagePercent() float64 {
 	// If we can't get usage directly, estimate based on memory usage
 	// This is not accurate but provides some indication
 	return -1
-}
\ No newline at end of file
+}
+func getAMDGPUTemperature() (float64, bool) {
+	// amdgpu exposes the edge temperature through hwmon in millidegrees
+	paths, err := filepath.Glob("/sys/class/drm/card0/device/hwmon/hwmon*/temp1_input")
+	if err != nil {
+		return 0, false
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		milli, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		if err != nil {
+			continue
+		}
+
+		return milli / 1000, true
+	}
+
+	return 0, false
+}
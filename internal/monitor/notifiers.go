@@ -0,0 +1,269 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notifier delivers alerts to an external system.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// NotifierConfig is one entry of "alerts.notifiers". Type selects the
+// notifier: "webhook", "ntfy", "gotify" or "smtp".
+type NotifierConfig struct {
+	Type     string            `mapstructure:"type"`
+	URL      string            `mapstructure:"url"`
+	Headers  map[string]string `mapstructure:"headers"`
+	Token    string            `mapstructure:"token"`
+	Host     string            `mapstructure:"host"`
+	Port     int               `mapstructure:"port"`
+	Username string            `mapstructure:"username"`
+	Password string            `mapstructure:"password"`
+	From     string            `mapstructure:"from"`
+	To       []string          `mapstructure:"to"`
+}
+
+func NewNotifier(cfg NotifierConfig) (Notifier, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	switch cfg.Type {
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook notifier requires url")
+		}
+		return &WebhookNotifier{url: cfg.URL, headers: cfg.Headers, client: client}, nil
+	case "ntfy", "gotify":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("%s notifier requires url", cfg.Type)
+		}
+		return &PushNotifier{flavor: cfg.Type, url: cfg.URL, token: cfg.Token, client: client}, nil
+	case "smtp":
+		if cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, fmt.Errorf("smtp notifier requires host, from and to")
+		}
+		port := cfg.Port
+		if port == 0 {
+			port = 587
+		}
+		return &SMTPNotifier{
+			addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+			host:     cfg.Host,
+			username: cfg.Username,
+			password: cfg.Password,
+			from:     cfg.From,
+			to:       cfg.To,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type: %s", cfg.Type)
+	}
+}
+
+// WebhookNotifier POSTs the alert as JSON to a URL.
+type WebhookNotifier struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.headers {
+		req.Header.Set(k, v)
+	}
+
+	return doNotifyRequest(n.client, req)
+}
+
+// PushNotifier sends alerts to an ntfy topic URL or a Gotify server.
+type PushNotifier struct {
+	flavor string
+	url    string
+	token  string
+	client *http.Client
+}
+
+func (n *PushNotifier) Name() string {
+	return n.flavor
+}
+
+func (n *PushNotifier) Notify(ctx context.Context, alert Alert) error {
+	title := fmt.Sprintf("lite-llm: %s %s", alert.Rule, alert.Status)
+	message := alert.Summary()
+
+	var req *http.Request
+	var err error
+
+	if n.flavor == "gotify" {
+		priority := 5
+		if alert.Severity == "critical" && alert.Status == AlertFiring {
+			priority = 8
+		}
+		body, err := json.Marshal(map[string]interface{}{
+			"title":    title,
+			"message":  message,
+			"priority": priority,
+		})
+		if err != nil {
+			return err
+		}
+		req, err = http.NewRequestWithContext(ctx, "POST", strings.TrimSuffix(n.url, "/")+"/message", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Gotify-Key", n.token)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, "POST", n.url, strings.NewReader(message))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Title", title)
+		if alert.Status == AlertFiring {
+			req.Header.Set("Tags", "warning")
+			if alert.Severity == "critical" {
+				req.Header.Set("Priority", "urgent")
+			}
+		} else {
+			req.Header.Set("Tags", "white_check_mark")
+		}
+		if n.token != "" {
+			req.Header.Set("Authorization", "Bearer "+n.token)
+		}
+	}
+
+	return doNotifyRequest(n.client, req)
+}
+
+func doNotifyRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("request failed with status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SMTPNotifier emails alerts.
+type SMTPNotifier struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+func (n *SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, alert Alert) error {
+	var auth smtp.Auth
+	if n.username != "" {
+		auth = smtp.PlainAuth("", n.username, n.password, n.host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&msg, "Subject: lite-llm alert: %s %s\r\n", alert.Rule, alert.Status)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\n", alert.Summary())
+	fmt.Fprintf(&msg, "Rule: %s\r\nCondition: %s\r\nStatus: %s\r\nStarted: %s\r\n",
+		alert.Rule, alert.Expr, alert.Status, alert.StartsAt.Format(time.RFC3339))
+	if alert.EndsAt != nil {
+		fmt.Fprintf(&msg, "Resolved: %s\r\n", alert.EndsAt.Format(time.RFC3339))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+	return n.send(ctx, auth, []byte(msg.String()))
+}
+
+// smtpTimeout bounds a whole SMTP exchange, so a server that stops
+// answering can't hold up the alert loop.
+const smtpTimeout = 30 * time.Second
+
+// send is smtp.SendMail, but dialled and bounded by ctx.
+func (n *SMTPNotifier) send(ctx context.Context, auth smtp.Auth, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	// Cancelling ctx interrupts a read or write in progress.
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("smtp server %s doesn't support authentication", n.addr)
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.from); err != nil {
+		return err
+	}
+	for _, to := range n.to {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,104 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP accepts one connection and runs serve on it.
+func fakeSMTP(t *testing.T, serve func(conn net.Conn)) *SMTPNotifier {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		serve(conn)
+	}()
+
+	n, err := NewNotifier(NotifierConfig{Type: "smtp", Host: "127.0.0.1", From: "llm@home.lan", To: []string{"ops@home.lan"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	smtpNotifier := n.(*SMTPNotifier)
+	smtpNotifier.addr = ln.Addr().String()
+	return smtpNotifier
+}
+
+var testAlert = Alert{Rule: "hot", Expr: "gpu.temp > 85", Severity: "critical", Status: AlertFiring, Value: "90.0",
+	StartsAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+
+func TestSMTPNotify(t *testing.T) {
+	received := make(chan string, 1)
+	n := fakeSMTP(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 fake ESMTP")
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250 fake")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				reply("250 ok")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 unknown")
+			}
+		}
+	})
+
+	if err := n.Notify(context.Background(), testAlert); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	msg := <-received
+	for _, want := range []string{"To: ops@home.lan\r\n", "Subject: lite-llm alert: hot firing\r\n", "[CRITICAL] hot: gpu.temp > 85 (value 90.0)"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message lacks %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestSMTPNotifyGivesUpOnSilentServer(t *testing.T) {
+	n := fakeSMTP(t, func(conn net.Conn) {
+		// Never greet; hold the connection until the client leaves.
+		conn.Read(make([]byte, 1))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := n.Notify(ctx, testAlert); err == nil {
+		t.Fatal("Notify to a silent server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Notify took %v to give up", elapsed)
+	}
+}
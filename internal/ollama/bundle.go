@@ -0,0 +1,358 @@
+package ollama
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Layer media types Ollama stores in a manifest.
+const (
+	MediaTypeModel     = "application/vnd.ollama.image.model"
+	MediaTypeProjector = "application/vnd.ollama.image.projector"
+	MediaTypeAdapter   = "application/vnd.ollama.image.adapter"
+	MediaTypeTemplate  = "application/vnd.ollama.image.template"
+	MediaTypeSystem    = "application/vnd.ollama.image.system"
+	MediaTypeParams    = "application/vnd.ollama.image.params"
+	MediaTypeMessages  = "application/vnd.ollama.image.messages"
+	MediaTypeLicense   = "application/vnd.ollama.image.license"
+)
+
+const (
+	bundleVersion   = 1
+	bundleIndexName = "bundle.json"
+
+	// maxInlineBlob bounds the blobs kept in memory while reading a
+	// bundle: configs, templates, parameters and the like.
+	maxInlineBlob = 1 << 20
+)
+
+// A bundle is an uncompressed tar laid out like a model store, so it can
+// also be unpacked into OLLAMA_MODELS by hand:
+//
+//	bundle.json
+//	manifests/<host>/<namespace>/<model>/<tag>
+//	blobs/sha256-<hex>
+//
+// bundle.json comes first and the manifests before the blobs, so a reader
+// knows what to expect while streaming it.
+
+// BundleIndex describes a bundle's contents.
+type BundleIndex struct {
+	Version   int           `json:"version" yaml:"version"`
+	CreatedAt time.Time     `json:"created_at" yaml:"created_at"`
+	Models    []BundleModel `json:"models" yaml:"models"`
+	Blobs     []Layer       `json:"blobs" yaml:"blobs"`
+}
+
+type BundleModel struct {
+	Name string `json:"name" yaml:"name"`
+	Size int64  `json:"size" yaml:"size"`
+}
+
+// Size is the total size of the bundle's blobs.
+func (i *BundleIndex) Size() int64 {
+	var size int64
+	for _, blob := range i.Blobs {
+		size += blob.Size
+	}
+	return size
+}
+
+// ExportBundle writes the named models and their blobs to w as a bundle.
+// Blobs are checked against their digests as they are copied, so a corrupt
+// store doesn't produce a bundle that only fails on the other side.
+func (s *Store) ExportBundle(w io.Writer, names []ModelName, progress func(Layer)) (*BundleIndex, error) {
+	index := &BundleIndex{Version: bundleVersion, CreatedAt: time.Now().UTC()}
+	manifests := make([][]byte, len(names))
+	seen := map[string]bool{}
+	for i, name := range names {
+		data, err := os.ReadFile(s.ManifestPath(name))
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("model %s not found in %s", name, s.Dir)
+		} else if err != nil {
+			return nil, err
+		}
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("invalid manifest for %s: %w", name, err)
+		}
+		manifests[i] = data
+		index.Models = append(index.Models, BundleModel{Name: name.String(), Size: manifest.Size()})
+		for _, blob := range manifest.Blobs() {
+			if !seen[blob.Digest] {
+				seen[blob.Digest] = true
+				index.Blobs = append(index.Blobs, blob)
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	tw := tar.NewWriter(w)
+	if err := writeTarBytes(tw, bundleIndexName, data); err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		if err := writeTarBytes(tw, "manifests/"+path.Join(name.Host, name.Namespace, name.Model, name.Tag), manifests[i]); err != nil {
+			return nil, err
+		}
+	}
+	for _, blob := range index.Blobs {
+		if progress != nil {
+			progress(blob)
+		}
+		if err := s.exportBlob(tw, blob); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func (s *Store) exportBlob(tw *tar.Writer, blob Layer) error {
+	blobPath, err := s.BlobPath(blob.Digest)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(blobPath)
+	if err != nil {
+		return fmt.Errorf("blob %s is missing from the store: %w", blob.Digest, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() != blob.Size {
+		return fmt.Errorf("blob %s is %d bytes, the manifest says %d", blob.Digest, info.Size(), blob.Size)
+	}
+
+	header := &tar.Header{
+		Name:    "blobs/" + path.Base(blobPath),
+		Mode:    0644,
+		Size:    blob.Size,
+		ModTime: info.ModTime(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(tw, io.TeeReader(f, h)); err != nil {
+		return fmt.Errorf("failed to copy blob %s: %w", blob.Digest, err)
+	}
+	if actual := "sha256:" + hex.EncodeToString(h.Sum(nil)); actual != blob.Digest {
+		return fmt.Errorf("blob %s is corrupt (content hashes to %s)", blob.Digest, actual)
+	}
+	return nil
+}
+
+func writeTarBytes(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Bundle is a bundle file whose contents have been verified by OpenBundle.
+type Bundle struct {
+	Path      string
+	Index     BundleIndex
+	Manifests map[string]*Manifest // by model name
+
+	inline map[string][]byte // small blobs, by digest
+}
+
+// OpenBundle reads a bundle end to end, checking that every manifest
+// listed is present and every blob they reference is there and matches its
+// digest. Nothing is installed from a bundle that fails this.
+func OpenBundle(bundlePath string) (*Bundle, error) {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := &Bundle{Path: bundlePath, Manifests: map[string]*Manifest{}, inline: map[string][]byte{}}
+	expected := map[string]int64{}
+	verified := map[string]bool{}
+	tr := tar.NewReader(f)
+	for first := true; ; first = false {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if first {
+			if header.Name != bundleIndexName {
+				return nil, fmt.Errorf("%s is not a model bundle", bundlePath)
+			}
+			if err := json.NewDecoder(io.LimitReader(tr, maxInlineBlob)).Decode(&b.Index); err != nil {
+				return nil, fmt.Errorf("invalid bundle index: %w", err)
+			}
+			if b.Index.Version != bundleVersion {
+				return nil, fmt.Errorf("unsupported bundle version %d", b.Index.Version)
+			}
+			for _, blob := range b.Index.Blobs {
+				expected[blob.Digest] = blob.Size
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(header.Name, "manifests/"):
+			parts := strings.Split(strings.TrimPrefix(header.Name, "manifests/"), "/")
+			if len(parts) != 4 {
+				return nil, fmt.Errorf("unexpected bundle entry %s", header.Name)
+			}
+			name := ModelName{Host: parts[0], Namespace: parts[1], Model: parts[2], Tag: parts[3]}
+			var manifest Manifest
+			if err := json.NewDecoder(io.LimitReader(tr, maxInlineBlob)).Decode(&manifest); err != nil {
+				return nil, fmt.Errorf("invalid manifest for %s: %w", name, err)
+			}
+			for _, blob := range manifest.Blobs() {
+				if size, ok := expected[blob.Digest]; !ok || size != blob.Size {
+					return nil, fmt.Errorf("bundle index doesn't list blob %s of %s", blob.Digest, name)
+				}
+			}
+			b.Manifests[name.String()] = &manifest
+
+		case strings.HasPrefix(header.Name, "blobs/sha256-"):
+			digest := "sha256:" + strings.TrimPrefix(header.Name, "blobs/sha256-")
+			size, ok := expected[digest]
+			if !ok {
+				return nil, fmt.Errorf("unexpected blob %s in bundle", digest)
+			}
+			if header.Size != size {
+				return nil, fmt.Errorf("blob %s is %d bytes, the manifest says %d", digest, header.Size, size)
+			}
+			var data []byte
+			var actual string
+			if size <= maxInlineBlob {
+				if data, err = io.ReadAll(tr); err == nil {
+					sum := sha256.Sum256(data)
+					actual = "sha256:" + hex.EncodeToString(sum[:])
+				}
+			} else {
+				actual, _, err = DigestReader(tr)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read blob %s: %w", digest, err)
+			}
+			if actual != digest {
+				return nil, fmt.Errorf("blob %s is corrupt (content hashes to %s)", digest, actual)
+			}
+			if data != nil {
+				b.inline[digest] = data
+			}
+			verified[digest] = true
+
+		default:
+			return nil, fmt.Errorf("unexpected bundle entry %s", header.Name)
+		}
+	}
+
+	for _, model := range b.Index.Models {
+		if b.Manifests[model.Name] == nil {
+			return nil, fmt.Errorf("bundle is missing the manifest for %s", model.Name)
+		}
+	}
+	for _, blob := range b.Index.Blobs {
+		if !verified[blob.Digest] {
+			return nil, fmt.Errorf("bundle is missing blob %s; it may be truncated", blob.Digest)
+		}
+	}
+	return b, nil
+}
+
+// WalkBlobs calls fn with each blob's content, in bundle order.
+func (b *Bundle) WalkBlobs(fn func(blob Layer, r io.Reader) error) error {
+	f, err := os.Open(b.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sizes := map[string]int64{}
+	for _, blob := range b.Index.Blobs {
+		sizes[blob.Digest] = blob.Size
+	}
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if !strings.HasPrefix(header.Name, "blobs/sha256-") {
+			continue
+		}
+		digest := "sha256:" + strings.TrimPrefix(header.Name, "blobs/sha256-")
+		if err := fn(Layer{Digest: digest, Size: sizes[digest]}, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// CreateRequest rebuilds the create request that recreates a model from
+// its layers once the blobs are in Ollama. The config is regenerated by
+// Ollama; every other layer is reproduced with the same digest.
+func (b *Bundle) CreateRequest(modelName, as string) (*CreateRequest, error) {
+	manifest := b.Manifests[modelName]
+	if manifest == nil {
+		return nil, fmt.Errorf("model %s is not in the bundle", modelName)
+	}
+
+	req := &CreateRequest{Model: as, Files: map[string]string{}}
+	for i, layer := range manifest.Layers {
+		switch layer.MediaType {
+		case MediaTypeModel, MediaTypeProjector:
+			req.Files[fmt.Sprintf("layer-%d.gguf", i)] = layer.Digest
+			continue
+		case MediaTypeAdapter:
+			if req.Adapters == nil {
+				req.Adapters = map[string]string{}
+			}
+			req.Adapters[fmt.Sprintf("adapter-%d.gguf", i)] = layer.Digest
+			continue
+		}
+
+		data, ok := b.inline[layer.Digest]
+		if !ok {
+			return nil, fmt.Errorf("layer %s of %s (%s) is too large to recreate", layer.Digest, modelName, layer.MediaType)
+		}
+		switch layer.MediaType {
+		case MediaTypeTemplate:
+			req.Template = string(data)
+		case MediaTypeSystem:
+			req.System = string(data)
+		case MediaTypeLicense:
+			req.License = append(req.License, string(data))
+		case MediaTypeParams:
+			req.Parameters = json.RawMessage(data)
+		case MediaTypeMessages:
+			req.Messages = json.RawMessage(data)
+		default:
+			return nil, fmt.Errorf("model %s has a layer of unsupported type %s", modelName, layer.MediaType)
+		}
+	}
+	if len(req.Files) == 0 {
+		return nil, fmt.Errorf("model %s has no weights", modelName)
+	}
+	return req, nil
+}
@@ -37,6 +37,18 @@ type PullProgress struct {
 	Completed int64  `json:"completed,omitempty"`
 }
 
+type RunningModel struct {
+	Name      string    `json:"name"`
+	Model     string    `json:"model"`
+	Size      int64     `json:"size"`
+	SizeVRAM  int64     `json:"size_vram"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type ListRunningResponse struct {
+	Models []RunningModel `json:"models"`
+}
+
 type DeleteRequest struct {
 	Name string `json:"name"`
 }
@@ -46,13 +58,30 @@ type GenerateRequest struct {
 	Prompt   string `json:"prompt"`
 	Stream   bool   `json:"stream"`
 	Options  map[string]interface{} `json:"options,omitempty"`
+	KeepAlive string                `json:"keep_alive,omitempty"`
+}
+
+// CreateRequest creates a model from blobs already uploaded with PushBlob.
+// Files and Adapters map file names to blob digests.
+type CreateRequest struct {
+	Model      string            `json:"model"`
+	Files      map[string]string `json:"files,omitempty"`
+	Adapters   map[string]string `json:"adapters,omitempty"`
+	Template   string            `json:"template,omitempty"`
+	System     string            `json:"system,omitempty"`
+	License    []string          `json:"license,omitempty"`
+	Parameters json.RawMessage   `json:"parameters,omitempty"`
+	Messages   json.RawMessage   `json:"messages,omitempty"`
+	Stream     bool              `json:"stream"`
 }
 
 type GenerateResponse struct {
-	Model     string    `json:"model"`
-	Response  string    `json:"response"`
-	Done      bool      `json:"done"`
-	CreatedAt time.Time `json:"created_at"`
+	Model           string    `json:"model"`
+	Response        string    `json:"response"`
+	Done            bool      `json:"done"`
+	CreatedAt       time.Time `json:"created_at"`
+	PromptEvalCount int       `json:"prompt_eval_count,omitempty"`
+	EvalCount       int       `json:"eval_count,omitempty"`
 }
 
 func NewClient(baseURL string) *Client {
@@ -79,6 +108,48 @@ func (c *Client) ListModels(ctx context.Context) ([]Model, error) {
 	return listResp.Models, nil
 }
 
+// ListRunning returns the models currently loaded into memory.
+func (c *Client) ListRunning(ctx context.Context) ([]RunningModel, error) {
+	resp, err := c.get(ctx, "/api/ps")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var listResp ListRunningResponse
+	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return listResp.Models, nil
+}
+
+// UnloadModel evicts a loaded model from memory by sending an empty
+// generate request with a zero keep-alive.
+func (c *Client) UnloadModel(ctx context.Context, name string) error {
+	req := GenerateRequest{
+		Model:     name,
+		KeepAlive: "0",
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(ctx, "/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unload request failed with status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
 func (c *Client) PullModel(ctx context.Context, name string, progressCallback func(PullProgress)) error {
 	req := PullRequest{
 		Name:   name,
@@ -140,6 +211,83 @@ func (c *Client) DeleteModel(ctx context.Context, name string) error {
 	return nil
 }
 
+// HasBlob reports whether Ollama already has a blob.
+func (c *Client) HasBlob(ctx context.Context, digest string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", c.baseURL+"/api/blobs/"+digest, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, fmt.Errorf("blob check failed with status: %d", resp.StatusCode)
+}
+
+// PushBlob uploads a blob; Ollama rejects it if the content doesn't match
+// the digest.
+func (c *Client) PushBlob(ctx context.Context, digest string, size int64, r io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/blobs/"+digest, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	// Model weights take longer than the client timeout to upload.
+	uploader := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := uploader.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("blob upload failed with status: %d%s", resp.StatusCode, errorMessage(resp.Body))
+	}
+	return nil
+}
+
+// CreateModel creates a model from uploaded blobs.
+func (c *Client) CreateModel(ctx context.Context, create CreateRequest) error {
+	create.Stream = false
+	body, err := json.Marshal(create)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(ctx, "/api/create", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("create request failed with status: %d%s", resp.StatusCode, errorMessage(resp.Body))
+	}
+	return nil
+}
+
+// errorMessage returns Ollama's {"error": ...} message as ": message", or
+// "" if the body has none.
+func errorMessage(body io.Reader) string {
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(body, 64<<10)).Decode(&resp); err != nil || resp.Error == "" {
+		return ""
+	}
+	return ": " + resp.Error
+}
+
 func (c *Client) Generate(ctx context.Context, model, prompt string, options map[string]interface{}) (*GenerateResponse, error) {
 	req := GenerateRequest{
 		Model:   model,
@@ -0,0 +1,285 @@
+package ollama
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Defaults Ollama fills in for short model names.
+const (
+	DefaultRegistry  = "registry.ollama.ai"
+	DefaultNamespace = "library"
+	DefaultTag       = "latest"
+)
+
+// ModelName is a fully qualified model reference,
+// <host>/<namespace>/<model>:<tag>.
+type ModelName struct {
+	Host      string
+	Namespace string
+	Model     string
+	Tag       string
+}
+
+// ParseModelName fills in Ollama's defaults for short names such as
+// "llama3.1:8b" or "user/model".
+func ParseModelName(name string) (ModelName, error) {
+	n := ModelName{Host: DefaultRegistry, Namespace: DefaultNamespace, Tag: DefaultTag}
+	rest := name
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		rest, n.Tag = rest[:i], rest[i+1:]
+	}
+	parts := strings.Split(rest, "/")
+	switch len(parts) {
+	case 1:
+		n.Model = parts[0]
+	case 2:
+		n.Namespace, n.Model = parts[0], parts[1]
+	case 3:
+		n.Host, n.Namespace, n.Model = parts[0], parts[1], parts[2]
+	default:
+		return ModelName{}, fmt.Errorf("invalid model name %q", name)
+	}
+	for _, part := range []string{n.Host, n.Namespace, n.Model, n.Tag} {
+		if part == "" || part == "." || part == ".." {
+			return ModelName{}, fmt.Errorf("invalid model name %q", name)
+		}
+	}
+	return n, nil
+}
+
+// String is the name as Ollama lists it, without default host and
+// namespace.
+func (n ModelName) String() string {
+	name := n.Model + ":" + n.Tag
+	switch {
+	case n.Host != DefaultRegistry:
+		return n.Host + "/" + n.Namespace + "/" + name
+	case n.Namespace != DefaultNamespace:
+		return n.Namespace + "/" + name
+	}
+	return name
+}
+
+// Path is the manifest's path below the manifests directory.
+func (n ModelName) Path() string {
+	return filepath.Join(n.Host, n.Namespace, n.Model, n.Tag)
+}
+
+// Manifest is an OCI image manifest as Ollama stores it.
+type Manifest struct {
+	SchemaVersion int     `json:"schemaVersion"`
+	MediaType     string  `json:"mediaType"`
+	Config        Layer   `json:"config"`
+	Layers        []Layer `json:"layers"`
+}
+
+type Layer struct {
+	MediaType string `json:"mediaType" yaml:"media_type"`
+	Digest    string `json:"digest" yaml:"digest"`
+	Size      int64  `json:"size" yaml:"size"`
+}
+
+// Blobs returns the config and layers, which are all stored as blobs.
+func (m *Manifest) Blobs() []Layer {
+	blobs := make([]Layer, 0, len(m.Layers)+1)
+	if m.Config.Digest != "" {
+		blobs = append(blobs, m.Config)
+	}
+	return append(blobs, m.Layers...)
+}
+
+// Size is the total size of the model's blobs.
+func (m *Manifest) Size() int64 {
+	var size int64
+	for _, blob := range m.Blobs() {
+		size += blob.Size
+	}
+	return size
+}
+
+// StoredModel is a model manifest found in a Store.
+type StoredModel struct {
+	Name       ModelName
+	Manifest   Manifest
+	ModifiedAt time.Time
+}
+
+// BlobFile is a blob on disk.
+type BlobFile struct {
+	Digest     string
+	Size       int64
+	ModifiedAt time.Time
+}
+
+// Store reads an Ollama model directory (OLLAMA_MODELS, /root/.ollama/models
+// in the container), which holds manifests/ and content-addressed blobs/.
+type Store struct {
+	Dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+func (s *Store) ManifestsDir() string {
+	return filepath.Join(s.Dir, "manifests")
+}
+
+func (s *Store) BlobsDir() string {
+	return filepath.Join(s.Dir, "blobs")
+}
+
+// ManifestPath is where the manifest of name is stored.
+func (s *Store) ManifestPath(name ModelName) string {
+	return filepath.Join(s.ManifestsDir(), name.Path())
+}
+
+// BlobPath is where a blob is stored; Ollama names blob files
+// sha256-<hex> for digest sha256:<hex>.
+func (s *Store) BlobPath(digest string) (string, error) {
+	algorithm, hexDigest, ok := strings.Cut(digest, ":")
+	if !ok || algorithm != "sha256" || len(hexDigest) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid digest %q", digest)
+	}
+	if _, err := hex.DecodeString(hexDigest); err != nil {
+		return "", fmt.Errorf("invalid digest %q", digest)
+	}
+	return filepath.Join(s.BlobsDir(), "sha256-"+hexDigest), nil
+}
+
+// Models returns every model in the store, sorted by name.
+func (s *Store) Models() ([]StoredModel, error) {
+	root := s.ManifestsDir()
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("no Ollama model store at %s: %w", s.Dir, err)
+	}
+
+	var models []StoredModel
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) != 4 {
+			return nil // not a manifest
+		}
+		manifest, err := readManifest(path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		models = append(models, StoredModel{
+			Name:       ModelName{Host: parts[0], Namespace: parts[1], Model: parts[2], Tag: parts[3]},
+			Manifest:   *manifest,
+			ModifiedAt: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifests: %w", err)
+	}
+
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Name.String() < models[j].Name.String()
+	})
+	return models, nil
+}
+
+// ReadManifest returns the manifest of one model.
+func (s *Store) ReadManifest(name ModelName) (*Manifest, error) {
+	manifest, err := readManifest(s.ManifestPath(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("model %s not found in %s", name, s.Dir)
+	}
+	return manifest, err
+}
+
+func readManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// Blobs lists the complete blobs on disk; partial downloads are skipped.
+func (s *Store) Blobs() ([]BlobFile, error) {
+	entries, err := os.ReadDir(s.BlobsDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read blobs: %w", err)
+	}
+
+	var blobs []BlobFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "sha256-") || strings.Contains(name, "-partial") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, BlobFile{
+			Digest:     "sha256:" + strings.TrimPrefix(name, "sha256-"),
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+		})
+	}
+	return blobs, nil
+}
+
+// DigestReader returns the sha256 digest of everything read from r, in
+// Ollama's sha256:<hex> form.
+func DigestReader(r io.Reader) (string, int64, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return "", n, err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// VerifyBlob checks that a blob file's content matches its digest.
+func (s *Store) VerifyBlob(digest string) error {
+	path, err := s.BlobPath(digest)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	actual, _, err := DigestReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read blob %s: %w", digest, err)
+	}
+	if actual != digest {
+		return fmt.Errorf("blob %s is corrupt (content hashes to %s)", digest, actual)
+	}
+	return nil
+}
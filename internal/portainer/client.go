@@ -0,0 +1,257 @@
+package portainer
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client talks to the Portainer REST API for one Docker environment
+// (endpoint), authenticating with an access token.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	endpointID int
+	httpClient *http.Client
+}
+
+// Stack is a Portainer stack as returned by /api/stacks.
+type Stack struct {
+	ID         int      `json:"Id" yaml:"id"`
+	Name       string   `json:"Name" yaml:"name"`
+	EndpointID int      `json:"EndpointId" yaml:"endpoint_id"`
+	Type       int      `json:"Type" yaml:"type"`
+	Status     int      `json:"Status" yaml:"status"`
+	Env        []EnvVar `json:"Env" yaml:"-"`
+	CreatedAt  int64    `json:"CreationDate" yaml:"created_at"`
+	UpdatedAt  int64    `json:"UpdateDate" yaml:"updated_at"`
+}
+
+const (
+	StackTypeCompose = 2
+
+	StackStatusActive   = 1
+	StackStatusInactive = 2
+)
+
+// Active reports whether Portainer considers the stack running.
+func (s *Stack) Active() bool {
+	return s.Status == StackStatusActive
+}
+
+// EnvVar is a stack environment variable.
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Container is a container from the Docker API proxied by Portainer.
+type Container struct {
+	ID     string            `json:"Id" yaml:"id"`
+	Names  []string          `json:"Names" yaml:"names"`
+	Image  string            `json:"Image" yaml:"image"`
+	State  string            `json:"State" yaml:"state"`
+	Status string            `json:"Status" yaml:"status"`
+	Labels map[string]string `json:"Labels" yaml:"-"`
+}
+
+// Name returns the container name without Docker's leading slash.
+func (c Container) Name() string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// Service returns the compose service the container belongs to.
+func (c Container) Service() string {
+	return c.Labels["com.docker.compose.service"]
+}
+
+// APIError is an error response from Portainer.
+type APIError struct {
+	StatusCode int
+	Message    string `json:"message"`
+	Details    string `json:"details"`
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.Details != "" && e.Details != e.Message {
+		msg += ": " + e.Details
+	}
+	return fmt.Sprintf("portainer API error (%d): %s", e.StatusCode, msg)
+}
+
+// NewClient returns a client for the Portainer instance at baseURL (e.g.
+// https://portainer.local:9443). insecure skips TLS verification for
+// Portainer's default self-signed certificate.
+func NewClient(baseURL, apiKey string, endpointID int, insecure bool) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		endpointID: endpointID,
+		httpClient: &http.Client{
+			Timeout:   10 * time.Minute, // deploys pull images synchronously
+			Transport: transport,
+		},
+	}
+}
+
+// ListStacks returns every stack visible to the API key.
+func (c *Client) ListStacks(ctx context.Context) ([]Stack, error) {
+	var stacks []Stack
+	if err := c.do(ctx, "GET", "/api/stacks", nil, &stacks); err != nil {
+		return nil, err
+	}
+	return stacks, nil
+}
+
+// FindStack returns the stack called name on the client's endpoint, or nil
+// if there is none.
+func (c *Client) FindStack(ctx context.Context, name string) (*Stack, error) {
+	stacks, err := c.ListStacks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stacks {
+		if stacks[i].Name == name && stacks[i].EndpointID == c.endpointID {
+			return &stacks[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateStack deploys a standalone compose stack from content.
+func (c *Client) CreateStack(ctx context.Context, name, content string, env []EnvVar) (*Stack, error) {
+	body := map[string]interface{}{
+		"name":             name,
+		"stackFileContent": content,
+		"env":              nonNilEnv(env),
+	}
+
+	var stack Stack
+	err := c.do(ctx, "POST", c.endpointPath("/api/stacks/create/standalone/string"), body, &stack)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusMethodNotAllowed) {
+		// Portainer before 2.19 only has the combined create endpoint.
+		path := fmt.Sprintf("/api/stacks?type=%d&method=string&endpointId=%d", StackTypeCompose, c.endpointID)
+		err = c.do(ctx, "POST", path, body, &stack)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+// UpdateStack replaces a stack's compose file and environment and
+// redeploys it, optionally pulling newer images first.
+func (c *Client) UpdateStack(ctx context.Context, id int, content string, env []EnvVar, pull bool) (*Stack, error) {
+	body := map[string]interface{}{
+		"stackFileContent": content,
+		"env":              nonNilEnv(env),
+		"prune":            true,
+		"pullImage":        pull,
+	}
+
+	var stack Stack
+	if err := c.do(ctx, "PUT", c.endpointPath(fmt.Sprintf("/api/stacks/%d", id)), body, &stack); err != nil {
+		return nil, err
+	}
+	return &stack, nil
+}
+
+// DeleteStack stops and removes a stack's containers and networks. Named
+// volumes are kept.
+func (c *Client) DeleteStack(ctx context.Context, id int) error {
+	return c.do(ctx, "DELETE", c.endpointPath(fmt.Sprintf("/api/stacks/%d", id)), nil, nil)
+}
+
+// StackContainers lists the containers of a compose stack, running or not.
+func (c *Client) StackContainers(ctx context.Context, name string) ([]Container, error) {
+	filters, err := json.Marshal(map[string][]string{
+		"label": {"com.docker.compose.project=" + name},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/api/endpoints/%d/docker/containers/json?all=1&filters=%s", c.endpointID, url.QueryEscape(string(filters)))
+	var containers []Container
+	if err := c.do(ctx, "GET", path, nil, &containers); err != nil {
+		return nil, err
+	}
+	return containers, nil
+}
+
+func (c *Client) endpointPath(path string) string {
+	return fmt.Sprintf("%s?endpointId=%d", path, c.endpointID)
+}
+
+// Portainer rejects a null env list.
+func nonNilEnv(env []EnvVar) []EnvVar {
+	if env == nil {
+		return []EnvVar{}
+	}
+	return env
+}
+
+// do sends a JSON request and decodes a JSON response into out, if set.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-API-Key", c.apiKey)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Portainer: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(data, apiErr) != nil {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
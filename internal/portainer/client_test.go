@@ -0,0 +1,200 @@
+package portainer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// request is what the stand-in Portainer saw.
+type request struct {
+	Method string
+	URI    string
+	APIKey string
+	Body   map[string]interface{}
+}
+
+// standIn serves canned responses by "METHOD path" (path without query)
+// and records the requests it received.
+func standIn(t *testing.T, routes map[string]func(w http.ResponseWriter)) (*Client, *[]request) {
+	t.Helper()
+	var seen []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := request{Method: r.Method, URI: r.URL.RequestURI(), APIKey: r.Header.Get("X-API-Key")}
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			if err := json.Unmarshal(data, &req.Body); err != nil {
+				t.Errorf("%s %s: request body is not JSON: %v", r.Method, r.URL, err)
+			}
+		}
+		seen = append(seen, req)
+
+		route, ok := routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		route(w)
+	}))
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL+"/", "ptr_test", 3, false), &seen
+}
+
+func fixture(t *testing.T, status int, name string) func(w http.ResponseWriter) {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(data)
+	}
+}
+
+func TestFindStack(t *testing.T) {
+	client, seen := standIn(t, map[string]func(http.ResponseWriter){
+		"GET /api/stacks": fixture(t, http.StatusOK, "stacks.json"),
+	})
+
+	stack, err := client.FindStack(context.Background(), "llm-stack")
+	if err != nil {
+		t.Fatalf("FindStack: %v", err)
+	}
+	// Stack 4 has the same name on another environment.
+	want := &Stack{ID: 7, Name: "llm-stack", EndpointID: 3, Type: StackTypeCompose, Status: StackStatusInactive,
+		Env: []EnvVar{{Name: "WEBUI_SECRET_KEY", Value: "old"}}, CreatedAt: 1728100000}
+	if !reflect.DeepEqual(stack, want) {
+		t.Errorf("FindStack = %+v, want %+v", stack, want)
+	}
+	if stack.Active() {
+		t.Error("stack with status 2 reported active")
+	}
+	if (*seen)[0].APIKey != "ptr_test" {
+		t.Errorf("X-API-Key = %q", (*seen)[0].APIKey)
+	}
+
+	if stack, err := client.FindStack(context.Background(), "missing"); err != nil || stack != nil {
+		t.Errorf("FindStack(missing) = %v, %v; want nil, nil", stack, err)
+	}
+}
+
+func TestCreateStack(t *testing.T) {
+	client, seen := standIn(t, map[string]func(http.ResponseWriter){
+		"POST /api/stacks/create/standalone/string": fixture(t, http.StatusOK, "stack_create.json"),
+	})
+
+	stack, err := client.CreateStack(context.Background(), "llm-stack", "services: {}\n", nil)
+	if err != nil {
+		t.Fatalf("CreateStack: %v", err)
+	}
+	if stack.ID != 12 || !stack.Active() {
+		t.Errorf("CreateStack = %+v", stack)
+	}
+
+	got := (*seen)[0]
+	if got.Method != "POST" || got.URI != "/api/stacks/create/standalone/string?endpointId=3" {
+		t.Errorf("request = %s %s", got.Method, got.URI)
+	}
+	want := map[string]interface{}{
+		"name":             "llm-stack",
+		"stackFileContent": "services: {}\n",
+		"env":              []interface{}{}, // Portainer rejects null
+	}
+	if !reflect.DeepEqual(got.Body, want) {
+		t.Errorf("body = %v, want %v", got.Body, want)
+	}
+}
+
+func TestCreateStackLegacyEndpoint(t *testing.T) {
+	client, seen := standIn(t, map[string]func(http.ResponseWriter){
+		"POST /api/stacks": fixture(t, http.StatusOK, "stack_create.json"),
+	})
+
+	env := []EnvVar{{Name: "WEBUI_SECRET_KEY", Value: "s3cret"}}
+	stack, err := client.CreateStack(context.Background(), "llm-stack", "services: {}\n", env)
+	if err != nil {
+		t.Fatalf("CreateStack: %v", err)
+	}
+	if stack.ID != 12 {
+		t.Errorf("CreateStack = %+v", stack)
+	}
+	if len(*seen) != 2 || (*seen)[1].URI != "/api/stacks?type=2&method=string&endpointId=3" {
+		t.Fatalf("requests = %+v, want a retry on the legacy endpoint", *seen)
+	}
+	if !reflect.DeepEqual((*seen)[1].Body["env"], []interface{}{map[string]interface{}{"name": "WEBUI_SECRET_KEY", "value": "s3cret"}}) {
+		t.Errorf("env = %v", (*seen)[1].Body["env"])
+	}
+}
+
+func TestCreateStackConflict(t *testing.T) {
+	client, seen := standIn(t, map[string]func(http.ResponseWriter){
+		"POST /api/stacks/create/standalone/string": fixture(t, http.StatusConflict, "error_conflict.json"),
+	})
+
+	_, err := client.CreateStack(context.Background(), "llm-stack", "services: {}\n", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Fatalf("CreateStack error = %v, want a 409 APIError", err)
+	}
+	if want := "portainer API error (409): A stack with the normalized name 'llm-stack' already exists"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+	if len(*seen) != 1 {
+		t.Errorf("a conflict must not fall back to the legacy endpoint: %+v", *seen)
+	}
+}
+
+func TestUpdateStack(t *testing.T) {
+	client, seen := standIn(t, map[string]func(http.ResponseWriter){
+		"PUT /api/stacks/7": fixture(t, http.StatusOK, "stack_update.json"),
+	})
+
+	env := []EnvVar{{Name: "WEBUI_SECRET_KEY", Value: "new"}}
+	stack, err := client.UpdateStack(context.Background(), 7, "services: {}\n", env, true)
+	if err != nil {
+		t.Fatalf("UpdateStack: %v", err)
+	}
+	if stack.UpdatedAt != 1729250000 || !stack.Active() {
+		t.Errorf("UpdateStack = %+v", stack)
+	}
+
+	got := (*seen)[0]
+	if got.URI != "/api/stacks/7?endpointId=3" {
+		t.Errorf("URI = %s", got.URI)
+	}
+	if got.Body["prune"] != true || got.Body["pullImage"] != true || got.Body["stackFileContent"] != "services: {}\n" {
+		t.Errorf("body = %v", got.Body)
+	}
+}
+
+func TestStackContainers(t *testing.T) {
+	client, seen := standIn(t, map[string]func(http.ResponseWriter){
+		"GET /api/endpoints/3/docker/containers/json": fixture(t, http.StatusOK, "containers.json"),
+	})
+
+	containers, err := client.StackContainers(context.Background(), "llm-stack")
+	if err != nil {
+		t.Fatalf("StackContainers: %v", err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("got %d containers, want 2", len(containers))
+	}
+	if c := containers[0]; c.Name() != "llm-stack-ollama" || c.Service() != "ollama" || c.State != "running" {
+		t.Errorf("containers[0] = %+v", c)
+	}
+	if c := containers[1]; c.Name() != "llm-stack-webui" || c.Service() != "open-webui" || c.State != "exited" {
+		t.Errorf("containers[1] = %+v", c)
+	}
+
+	want := `/api/endpoints/3/docker/containers/json?all=1&filters=%7B%22label%22%3A%5B%22com.docker.compose.project%3Dllm-stack%22%5D%7D`
+	if got := (*seen)[0].URI; got != want {
+		t.Errorf("URI = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,334 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/sirupsen/logrus"
+)
+
+const manifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+
+// ErrNotFound is returned when the upstream registry has no such manifest
+// or blob.
+var ErrNotFound = errors.New("not found upstream")
+
+// Mirror is a pull-through cache of the registry Ollama pulls from. The
+// cache is laid out like an Ollama model store, with manifests filed under
+// the upstream's host, so it can also be used as OLLAMA_MODELS.
+type Mirror struct {
+	store    *ollama.Store
+	upstream string
+	host     string
+	client   *http.Client
+
+	mu    sync.Mutex
+	fills map[string]*fill // in-progress blob downloads, by digest
+}
+
+// fill is a blob download into the cache. Requests for the blob while it
+// runs read the partial file as it grows rather than downloading again.
+type fill struct {
+	done    chan struct{} // closed when the download ends
+	err     error
+	started chan struct{} // closed once upstream has answered and file is set
+
+	mu      sync.Mutex
+	file    string // the partial file, then the blob once it is in place
+	size    int64  // from upstream; -1 if unknown
+	written int64
+	grew    chan struct{} // closed and replaced whenever written grows
+}
+
+func newFill() *fill {
+	return &fill{
+		done:    make(chan struct{}),
+		started: make(chan struct{}),
+		size:    -1,
+		grew:    make(chan struct{}),
+	}
+}
+
+func (f *fill) begin(file string, size int64) {
+	f.mu.Lock()
+	f.file, f.size = file, size
+	f.mu.Unlock()
+	close(f.started)
+}
+
+// Write counts bytes already written to the partial file.
+func (f *fill) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	f.written += int64(len(p))
+	close(f.grew)
+	f.grew = make(chan struct{})
+	f.mu.Unlock()
+	return len(p), nil
+}
+
+// progress returns how much of the blob can be read, and a channel closed
+// when that changes.
+func (f *fill) progress() (int64, <-chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.written, f.grew
+}
+
+// open opens the partial file, or the blob if it has been moved into place.
+func (f *fill) open() (*os.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return os.Open(f.file)
+}
+
+// finished reports whether the download has ended.
+func (f *fill) finished() bool {
+	select {
+	case <-f.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func NewMirror(dir, upstream string) (*Mirror, error) {
+	u, err := url.Parse(upstream)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid upstream registry %q", upstream)
+	}
+	return &Mirror{
+		store:    ollama.NewStore(dir),
+		upstream: strings.TrimSuffix(upstream, "/"),
+		host:     u.Host,
+		// Blobs run to gigabytes, so requests are bounded by their
+		// contexts rather than a client timeout.
+		client: &http.Client{},
+		fills:  map[string]*fill{},
+	}, nil
+}
+
+// Store is the mirror's cache.
+func (m *Mirror) Store() *ollama.Store {
+	return m.store
+}
+
+// Manifest returns a manifest, fetched from upstream so that tags stay
+// current. The cached copy is used when upstream can't be reached.
+func (m *Mirror) Manifest(ctx context.Context, repo, reference string) ([]byte, error) {
+	name, cacheable := m.modelName(repo, reference)
+
+	data, err := m.fetchManifest(ctx, repo, reference)
+	if err == nil {
+		if cacheable {
+			if err := writeFileAtomic(m.store.ManifestPath(name), data); err != nil {
+				logrus.Warnf("Failed to cache manifest %s: %v", name, err)
+			}
+		}
+		return data, nil
+	}
+	if errors.Is(err, ErrNotFound) || !cacheable {
+		return nil, err
+	}
+
+	cached, cacheErr := os.ReadFile(m.store.ManifestPath(name))
+	if cacheErr != nil {
+		return nil, err
+	}
+	logrus.Warnf("Serving cached manifest for %s: %v", name, err)
+	return cached, nil
+}
+
+// modelName maps a repository and tag to where the manifest is cached;
+// manifests requested by digest aren't cached.
+func (m *Mirror) modelName(repo, reference string) (ollama.ModelName, bool) {
+	if strings.HasPrefix(reference, "sha256:") {
+		return ollama.ModelName{}, false
+	}
+	name, err := ollama.ParseModelName(m.host + "/" + repo + ":" + reference)
+	return name, err == nil
+}
+
+func (m *Mirror) fetchManifest(ctx context.Context, repo, reference string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	resp, err := m.upstreamRequest(ctx, "GET", "/v2/"+repo+"/manifests/"+reference, http.Header{"Accept": {manifestMediaType}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, upstreamError(resp)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return data, nil
+}
+
+// FillBlob downloads a blob into the cache unless it is already there.
+// Concurrent calls for one blob share a single download.
+func (m *Mirror) FillBlob(ctx context.Context, repo, digest string) error {
+	f, err := m.startFill(ctx, repo, digest)
+	if err != nil || f == nil {
+		return err
+	}
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startFill returns the running download of a blob, starting one under ctx
+// if there is none. It returns nil if the blob is already cached.
+func (m *Mirror) startFill(ctx context.Context, repo, digest string) (*fill, error) {
+	path, err := m.store.BlobPath(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if f, running := m.fills[digest]; running {
+		return f, nil
+	}
+	// Checked under the lock: a finished fill moves its blob into place
+	// before it is removed from fills.
+	if _, err := os.Stat(path); err == nil {
+		return nil, nil
+	}
+
+	f := newFill()
+	m.fills[digest] = f
+	go func() {
+		f.err = m.downloadBlob(ctx, repo, digest, path, f)
+		m.mu.Lock()
+		delete(m.fills, digest)
+		m.mu.Unlock()
+		close(f.done)
+	}()
+	return f, nil
+}
+
+func (m *Mirror) downloadBlob(ctx context.Context, repo, digest, path string, f *fill) error {
+	resp, err := m.upstreamRequest(ctx, "GET", "/v2/"+repo+"/blobs/"+digest, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return upstreamError(resp)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Ollama and Store.Blobs both ignore -partial files.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+"-partial-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	f.begin(tmp.Name(), resp.ContentLength)
+
+	h := sha256.New()
+	// f counts bytes only once they are in the file.
+	_, err = io.Copy(io.MultiWriter(tmp, h, f), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download blob %s: %w", digest, err)
+	}
+	if actual := "sha256:" + hex.EncodeToString(h.Sum(nil)); actual != digest {
+		return fmt.Errorf("upstream sent corrupt blob %s (content hashes to %s)", digest, actual)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	f.file = path
+	return nil
+}
+
+// Warm fetches a model's manifest and every blob it references, so that
+// hosts pulling it are served from the cache. progress is called before
+// each blob with whether it was already cached.
+func (m *Mirror) Warm(ctx context.Context, name ollama.ModelName, progress func(blob ollama.Layer, cached bool)) (*ollama.Manifest, error) {
+	repo := name.Namespace + "/" + name.Model
+	if _, err := m.Manifest(ctx, repo, name.Tag); err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest for %s: %w", name, err)
+	}
+	manifest, err := m.store.ReadManifest(ollama.ModelName{Host: m.host, Namespace: name.Namespace, Model: name.Model, Tag: name.Tag})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, blob := range manifest.Blobs() {
+		path, err := m.store.BlobPath(blob.Digest)
+		if err != nil {
+			return nil, err
+		}
+		_, statErr := os.Stat(path)
+		if progress != nil {
+			progress(blob, statErr == nil)
+		}
+		if err := m.FillBlob(ctx, repo, blob.Digest); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+func (m *Mirror) upstreamRequest(ctx context.Context, method, path string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, m.upstream+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach upstream registry: %w", err)
+	}
+	return resp, nil
+}
+
+func upstreamError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("upstream registry returned status: %d", resp.StatusCode)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
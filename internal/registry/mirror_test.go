@@ -0,0 +1,238 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// upstream is a stand-in registry serving fixed manifests and blobs.
+type upstream struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	manifests map[string]string // by repo:reference
+	blobs     map[string][]byte // by digest
+	down      bool
+	// gate, if set, holds full blob downloads until it is closed.
+	gate chan struct{}
+	// failFills makes full blob downloads fail while ranges still work.
+	failFills bool
+
+	blobGets  atomic.Int32 // full blob downloads
+	rangeGets atomic.Int32
+}
+
+func newUpstream(t *testing.T) *upstream {
+	t.Helper()
+	u := &upstream{manifests: map[string]string{}, blobs: map[string][]byte{}}
+	u.Server = httptest.NewServer(http.HandlerFunc(u.serve))
+	t.Cleanup(u.Close)
+	return u
+}
+
+func (u *upstream) serve(w http.ResponseWriter, r *http.Request) {
+	u.mu.Lock()
+	down, gate, failFills := u.down, u.gate, u.failFills
+	u.mu.Unlock()
+	if down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/"), "/")
+	if len(parts) != 4 {
+		http.NotFound(w, r)
+		return
+	}
+	repo, kind, ref := parts[0]+"/"+parts[1], parts[2], parts[3]
+	switch kind {
+	case "manifests":
+		u.mu.Lock()
+		manifest, ok := u.manifests[repo+":"+ref]
+		u.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", manifestMediaType)
+		io.WriteString(w, manifest)
+	case "blobs":
+		u.mu.Lock()
+		data, ok := u.blobs[ref]
+		u.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Range") != "" {
+			u.rangeGets.Add(1)
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+			return
+		}
+		if r.Method == http.MethodGet {
+			u.blobGets.Add(1)
+			if failFills {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if gate != nil {
+				<-gate
+			}
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Write(data)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (u *upstream) addBlob(data []byte) string {
+	sum := sha256.Sum256(data)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	u.mu.Lock()
+	u.blobs[digest] = data
+	u.mu.Unlock()
+	return digest
+}
+
+func (u *upstream) set(change func(u *upstream)) {
+	u.mu.Lock()
+	change(u)
+	u.mu.Unlock()
+}
+
+func newTestMirror(t *testing.T, u *upstream) *Mirror {
+	t.Helper()
+	m, err := NewMirror(t.TempDir(), u.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func blobData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+func TestManifestPassthroughAndFallback(t *testing.T) {
+	u := newUpstream(t)
+	u.manifests["library/mistral:latest"] = `{"schemaVersion":2,"layers":[]}`
+	m := newTestMirror(t, u)
+	ctx := context.Background()
+
+	data, err := m.Manifest(ctx, "library/mistral", "latest")
+	if err != nil || string(data) != `{"schemaVersion":2,"layers":[]}` {
+		t.Fatalf("Manifest = %q, %v", data, err)
+	}
+
+	// Tags move upstream, so the mirror asks every time.
+	u.set(func(u *upstream) { u.manifests["library/mistral:latest"] = `{"schemaVersion":2,"layers":[{}]}` })
+	if data, _ := m.Manifest(ctx, "library/mistral", "latest"); string(data) != `{"schemaVersion":2,"layers":[{}]}` {
+		t.Errorf("Manifest after a tag moved = %q", data)
+	}
+
+	u.set(func(u *upstream) { u.down = true })
+	if data, err := m.Manifest(ctx, "library/mistral", "latest"); err != nil || string(data) != `{"schemaVersion":2,"layers":[{}]}` {
+		t.Errorf("Manifest with upstream down = %q, %v; want the cached copy", data, err)
+	}
+	if _, err := m.Manifest(ctx, "library/llama3", "latest"); err == nil {
+		t.Error("Manifest of an uncached model with upstream down succeeded")
+	}
+
+	u.set(func(u *upstream) {
+		u.down = false
+		delete(u.manifests, "library/mistral:latest")
+	})
+	if _, err := m.Manifest(ctx, "library/mistral", "latest"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Manifest removed upstream: %v, want ErrNotFound", err)
+	}
+}
+
+func TestFillBlobRejectsBadDigest(t *testing.T) {
+	u := newUpstream(t)
+	m := newTestMirror(t, u)
+	digest := u.addBlob([]byte("the real blob"))
+	u.set(func(u *upstream) { u.blobs[digest] = []byte("something else") })
+
+	err := m.FillBlob(context.Background(), "library/mistral", digest)
+	if err == nil || !strings.Contains(err.Error(), "corrupt") {
+		t.Fatalf("FillBlob = %v, want a corrupt blob error", err)
+	}
+	path, _ := m.Store().BlobPath(digest)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("corrupt blob was cached: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 0 {
+		t.Errorf("partial files left behind: %v", entries)
+	}
+}
+
+func TestFillBlobSharesDownload(t *testing.T) {
+	u := newUpstream(t)
+	m := newTestMirror(t, u)
+	data := blobData(1 << 20)
+	digest := u.addBlob(data)
+	u.set(func(u *upstream) { u.gate = make(chan struct{}) })
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = m.FillBlob(context.Background(), "library/mistral", digest)
+		}(i)
+	}
+	waitFor(t, func() bool { return u.blobGets.Load() > 0 })
+	close(u.gate)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("FillBlob %d: %v", i, err)
+		}
+	}
+	if n := u.blobGets.Load(); n != 1 {
+		t.Errorf("upstream served the blob %d times, want 1", n)
+	}
+	path, _ := m.Store().BlobPath(digest)
+	if cached, _ := os.ReadFile(path); !bytes.Equal(cached, data) {
+		t.Error("cached blob differs from upstream")
+	}
+
+	if err := m.FillBlob(context.Background(), "library/mistral", digest); err != nil || u.blobGets.Load() != 1 {
+		t.Errorf("FillBlob of a cached blob: %v, %d downloads", err, u.blobGets.Load())
+	}
+}
+
+// waitFor polls cond, failing the test if it doesn't hold within a few
+// seconds.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
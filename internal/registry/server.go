@@ -0,0 +1,270 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// SetupRoutes serves the part of the OCI distribution API Ollama pulls
+// with. Repositories are <namespace>/<model>, as in Ollama's registry.
+func (m *Mirror) SetupRoutes() *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+
+	r := gin.Default()
+	r.GET("/v2/", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) })
+	r.GET("/v2/:namespace/:model/manifests/:reference", m.handleManifest)
+	r.HEAD("/v2/:namespace/:model/manifests/:reference", m.handleManifest)
+	r.GET("/v2/:namespace/:model/blobs/:digest", m.handleBlob)
+	r.HEAD("/v2/:namespace/:model/blobs/:digest", m.handleBlob)
+	return r
+}
+
+func (m *Mirror) handleManifest(c *gin.Context) {
+	repo := c.Param("namespace") + "/" + c.Param("model")
+	data, err := m.Manifest(c.Request.Context(), repo, c.Param("reference"))
+	if err != nil {
+		registryError(c, err)
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	c.Header("Docker-Content-Digest", "sha256:"+hex.EncodeToString(sum[:]))
+	c.Header("Content-Length", strconv.Itoa(len(data)))
+	if c.Request.Method == http.MethodHead {
+		c.Header("Content-Type", manifestMediaType)
+		c.Status(http.StatusOK)
+		return
+	}
+	c.Data(http.StatusOK, manifestMediaType, data)
+}
+
+// handleBlob serves cached blobs from disk. A blob that isn't cached yet
+// is fetched into the cache once, and requests for it, ranges included,
+// are streamed from that download as it lands. Upstream is proxied
+// directly only for HEAD requests and when the download can't start.
+func (m *Mirror) handleBlob(c *gin.Context) {
+	repo := c.Param("namespace") + "/" + c.Param("model")
+	digest := c.Param("digest")
+	path, err := m.store.BlobPath(digest)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"errors": []gin.H{{"code": "DIGEST_INVALID", "message": err.Error()}}})
+		return
+	}
+
+	if m.serveFile(c, path, digest) {
+		return
+	}
+	if c.Request.Method == http.MethodHead {
+		m.proxyBlob(c, repo, digest)
+		return
+	}
+
+	// The download outlives this request so other hosts can share it.
+	f, err := m.startFill(context.Background(), repo, digest)
+	if err != nil {
+		registryError(c, err)
+		return
+	}
+	if f == nil {
+		// Cached since we looked.
+		if !m.serveFile(c, path, digest) {
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+	m.serveFill(c, f, repo, digest, path)
+}
+
+// serveFile serves a cached blob, reporting false if it isn't cached.
+func (m *Mirror) serveFile(c *gin.Context, path, digest string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return true
+	}
+	c.Header("Docker-Content-Digest", digest)
+	c.Header("Content-Type", "application/octet-stream")
+	http.ServeContent(c.Writer, c.Request, "", info.ModTime(), f)
+	return true
+}
+
+// serveFill streams a blob, or the requested range of it, from a download
+// in progress.
+func (m *Mirror) serveFill(c *gin.Context, f *fill, repo, digest, path string) {
+	ctx := c.Request.Context()
+	select {
+	case <-f.started:
+	case <-f.done:
+		switch {
+		case f.err == nil:
+			if !m.serveFile(c, path, digest) {
+				c.Status(http.StatusInternalServerError)
+			}
+		case errors.Is(f.err, ErrNotFound):
+			registryError(c, f.err)
+		default:
+			logrus.Warnf("Failed to cache blob %s, proxying it: %v", digest, f.err)
+			m.proxyBlob(c, repo, digest)
+		}
+		return
+	case <-ctx.Done():
+		return
+	}
+
+	size := f.size
+	start, end := int64(0), size-1
+	status := http.StatusOK
+	if rng := c.GetHeader("Range"); rng != "" {
+		if size < 0 {
+			// Upstream sent no length, so ranges can't be placed.
+			m.proxyBlob(c, repo, digest)
+			return
+		}
+		var ok bool
+		if start, end, ok = parseRange(rng, size); !ok {
+			c.Header("Content-Range", fmt.Sprintf("bytes */%d", size))
+			c.Status(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		if start != 0 || end != size-1 {
+			status = http.StatusPartialContent
+			c.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+		}
+	}
+
+	file, err := f.open()
+	if err != nil {
+		logrus.Warnf("Failed to read blob %s while caching it, proxying it: %v", digest, err)
+		m.proxyBlob(c, repo, digest)
+		return
+	}
+	defer file.Close()
+
+	c.Header("Docker-Content-Digest", digest)
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Accept-Ranges", "bytes")
+	if size >= 0 {
+		c.Header("Content-Length", strconv.FormatInt(end-start+1, 10))
+	}
+	c.Status(status)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	for offset := start; size < 0 || offset <= end; {
+		written, grew := f.progress()
+		if size >= 0 && written > end+1 {
+			written = end + 1
+		}
+		if offset < written {
+			n, err := io.Copy(c.Writer, io.NewSectionReader(file, offset, written-offset))
+			offset += n
+			if err != nil {
+				return
+			}
+			c.Writer.Flush()
+			continue
+		}
+		if f.finished() {
+			// Either everything was sent or the download failed, in which
+			// case the client sees a short body and retries.
+			return
+		}
+		select {
+		case <-grew:
+		case <-f.done:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// parseRange parses a single-range Range header against a blob of size
+// bytes. Ollama asks for one range per request; anything else gets the
+// whole blob.
+func parseRange(header string, size int64) (start, end int64, ok bool) {
+	spec, found := strings.CutPrefix(header, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, size - 1, true
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, size - 1, true
+	}
+	if first == "" {
+		// bytes=-n is the last n bytes.
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end = size - 1
+	if last != "" {
+		if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+			return 0, 0, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end, true
+}
+
+func (m *Mirror) proxyBlob(c *gin.Context, repo, digest string) {
+	header := http.Header{}
+	if rng := c.GetHeader("Range"); rng != "" {
+		header.Set("Range", rng)
+	}
+	resp, err := m.upstreamRequest(c.Request.Context(), c.Request.Method, "/v2/"+repo+"/blobs/"+digest, header)
+	if err != nil {
+		registryError(c, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		registryError(c, upstreamError(resp))
+		return
+	}
+	for _, key := range []string{"Content-Length", "Content-Range", "Accept-Ranges"} {
+		if value := resp.Header.Get(key); value != "" {
+			c.Header(key, value)
+		}
+	}
+	c.Header("Docker-Content-Digest", digest)
+	c.Header("Content-Type", "application/octet-stream")
+	c.Status(resp.StatusCode)
+	io.Copy(c.Writer, resp.Body)
+}
+
+func registryError(c *gin.Context, err error) {
+	if errors.Is(err, ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"errors": []gin.H{{"code": "NOT_FOUND", "message": "not found"}}})
+		return
+	}
+	logrus.Warnf("Registry request %s failed: %v", c.Request.URL.Path, err)
+	c.JSON(http.StatusBadGateway, gin.H{"errors": []gin.H{{"code": "UNAVAILABLE", "message": err.Error()}}})
+}
@@ -0,0 +1,177 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func newTestServer(t *testing.T, m *Mirror) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(m.SetupRoutes())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type blobResponse struct {
+	status       int
+	contentRange string
+	body         []byte
+}
+
+func getBlob(t *testing.T, srv *httptest.Server, method, digest, rng string) blobResponse {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+"/v2/library/mistral/blobs/"+digest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rng != "" {
+		req.Header.Set("Range", rng)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Errorf("%s %s: %v", method, rng, err)
+		return blobResponse{}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Errorf("%s %s: reading body: %v", method, rng, err)
+	}
+	return blobResponse{resp.StatusCode, resp.Header.Get("Content-Range"), body}
+}
+
+func TestBlobRangesShareFill(t *testing.T) {
+	u := newUpstream(t)
+	m := newTestMirror(t, u)
+	srv := newTestServer(t, m)
+	data := blobData(3 << 20)
+	digest := u.addBlob(data)
+	u.set(func(u *upstream) { u.gate = make(chan struct{}) })
+
+	// Ollama pulls in parallel ranges; a second host pulls the whole blob.
+	ranges := []struct {
+		header     string
+		start, end int
+	}{
+		{"bytes=0-1048575", 0, 1048575},
+		{"bytes=1048576-2097151", 1048576, 2097151},
+		{"bytes=2097152-", 2097152, len(data) - 1},
+		{"bytes=-100", len(data) - 100, len(data) - 1},
+		{"", 0, len(data) - 1},
+	}
+	responses := make([]blobResponse, len(ranges))
+	var wg sync.WaitGroup
+	for i, r := range ranges {
+		wg.Add(1)
+		go func(i int, header string) {
+			defer wg.Done()
+			responses[i] = getBlob(t, srv, "GET", digest, header)
+		}(i, r.header)
+	}
+	waitFor(t, func() bool { return u.blobGets.Load() > 0 })
+	close(u.gate)
+	wg.Wait()
+
+	for i, r := range ranges {
+		resp := responses[i]
+		wantStatus, wantRange := http.StatusPartialContent, fmt.Sprintf("bytes %d-%d/%d", r.start, r.end, len(data))
+		if r.header == "" {
+			wantStatus, wantRange = http.StatusOK, ""
+		}
+		if resp.status != wantStatus || resp.contentRange != wantRange {
+			t.Errorf("Range %q: status %d, Content-Range %q; want %d, %q", r.header, resp.status, resp.contentRange, wantStatus, wantRange)
+		}
+		if !bytes.Equal(resp.body, data[r.start:r.end+1]) {
+			t.Errorf("Range %q: got %d bytes that differ from the blob", r.header, len(resp.body))
+		}
+	}
+	if n := u.blobGets.Load(); n != 1 {
+		t.Errorf("upstream served the blob %d times, want 1", n)
+	}
+	if n := u.rangeGets.Load(); n != 0 {
+		t.Errorf("upstream was asked for %d ranges, want none", n)
+	}
+
+	// Now cached, ranges are served from disk.
+	u.set(func(u *upstream) { u.down = true })
+	resp := getBlob(t, srv, "GET", digest, "bytes=10-19")
+	if resp.status != http.StatusPartialContent || !bytes.Equal(resp.body, data[10:20]) {
+		t.Errorf("cached range: status %d, body %v", resp.status, resp.body)
+	}
+}
+
+func TestBlobProxiesRangeWhenFillFails(t *testing.T) {
+	u := newUpstream(t)
+	m := newTestMirror(t, u)
+	srv := newTestServer(t, m)
+	data := blobData(4096)
+	digest := u.addBlob(data)
+	u.set(func(u *upstream) { u.failFills = true })
+
+	resp := getBlob(t, srv, "GET", digest, "bytes=100-199")
+	if resp.status != http.StatusPartialContent || resp.contentRange != "bytes 100-199/4096" || !bytes.Equal(resp.body, data[100:200]) {
+		t.Errorf("status %d, Content-Range %q, %d bytes; want the range proxied", resp.status, resp.contentRange, len(resp.body))
+	}
+	if n := u.rangeGets.Load(); n != 1 {
+		t.Errorf("upstream was asked for %d ranges, want 1", n)
+	}
+}
+
+func TestBlobHeadAndNotFound(t *testing.T) {
+	u := newUpstream(t)
+	m := newTestMirror(t, u)
+	srv := newTestServer(t, m)
+	digest := u.addBlob(blobData(4096))
+
+	req, _ := http.NewRequest("HEAD", srv.URL+"/v2/library/mistral/blobs/"+digest, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.ContentLength != 4096 {
+		t.Errorf("HEAD: status %d, length %d", resp.StatusCode, resp.ContentLength)
+	}
+	if n := u.blobGets.Load(); n != 0 {
+		t.Errorf("HEAD downloaded the blob %d times", n)
+	}
+
+	missing := "sha256:" + fmt.Sprintf("%064x", 1)
+	if resp := getBlob(t, srv, "GET", missing, ""); resp.status != http.StatusNotFound {
+		t.Errorf("missing blob: status %d, want 404", resp.status)
+	}
+	if resp := getBlob(t, srv, "GET", "sha256:nothex", ""); resp.status != http.StatusBadRequest {
+		t.Errorf("invalid digest: status %d, want 400", resp.status)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header     string
+		start, end int64
+		ok         bool
+	}{
+		{"bytes=0-99", 0, 99, true},
+		{"bytes=100-", 100, 999, true},
+		{"bytes=900-5000", 900, 999, true},
+		{"bytes=-10", 990, 999, true},
+		{"bytes=-5000", 0, 999, true},
+		{"bytes=0-9,20-29", 0, 999, true},
+		{"items=0-9", 0, 999, true},
+		{"bytes=1000-", 0, 0, false},
+		{"bytes=50-10", 0, 0, false},
+		{"bytes=-0", 0, 0, false},
+		{"bytes=x-", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, end, ok := parseRange(tt.header, 1000)
+		if start != tt.start || end != tt.end || ok != tt.ok {
+			t.Errorf("parseRange(%q) = %d, %d, %v; want %d, %d, %v", tt.header, start, end, ok, tt.start, tt.end, tt.ok)
+		}
+	}
+}
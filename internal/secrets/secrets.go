@@ -0,0 +1,132 @@
+package secrets
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Generate returns a URL-safe random secret with n bytes of entropy.
+func Generate(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// EnvFile is a dotenv file that preserves the order of existing keys and
+// any comments when rewritten.
+type EnvFile struct {
+	lines  []string
+	values map[string]string
+	index  map[string]int // key -> line
+}
+
+// LoadEnvFile reads path, returning an empty file if it does not exist.
+func LoadEnvFile(path string) (*EnvFile, error) {
+	env := &EnvFile{values: make(map[string]string), index: make(map[string]int)}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return env, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		env.lines = append(env.lines, line)
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(trimmed, "export "), "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		env.values[key] = strings.Trim(strings.TrimSpace(value), `"'`)
+		env.index[key] = len(env.lines) - 1
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return env, nil
+}
+
+// Get returns the value of key and whether it is set.
+func (e *EnvFile) Get(key string) (string, bool) {
+	v, ok := e.values[key]
+	return v, ok && v != ""
+}
+
+// Set updates key in place or appends it.
+func (e *EnvFile) Set(key, value string) {
+	line := fmt.Sprintf("%s=%s", key, value)
+	if i, ok := e.index[key]; ok {
+		e.lines[i] = line
+	} else {
+		e.lines = append(e.lines, line)
+		e.index[key] = len(e.lines) - 1
+	}
+	e.values[key] = value
+}
+
+// Keys returns the keys set in the file, sorted.
+func (e *EnvFile) Keys() []string {
+	keys := make([]string, 0, len(e.values))
+	for k := range e.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Save writes the file atomically with owner-only permissions.
+func (e *EnvFile) Save(path string) error {
+	return WriteFile(path, []byte(strings.Join(e.lines, "\n")+"\n"))
+}
+
+// WriteFile writes a secret file with mode 0600 via a temporary file and
+// rename, so readers never see a partial secret and the file is never
+// briefly world-readable.
+func WriteFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
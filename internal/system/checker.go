@@ -2,46 +2,70 @@ package system
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
-	"regexp"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/lyleclassen/lite-llm/internal/docker"
 	"github.com/sirupsen/logrus"
 )
 
-type Checker struct{}
+// Checker inspects the host. Filesystem probes are resolved relative to
+// root so detection can run against a captured sysfs tree.
+type Checker struct {
+	root         string
+	dockerSocket string
+}
 
 type SystemInfo struct {
-	HasDocker     bool
-	HasROCm       bool
-	HasNVIDIA     bool
-	HasAMDGPU     bool
-	GPUMemory     int // in MB
-	SystemMemory  int // in MB
-	GPUModel      string
-	GPUType       string // "nvidia", "amd", or "unknown"
-	KernelVersion string
+	HasDocker     bool   `json:"has_docker" yaml:"has_docker"`
+	HasROCm       bool   `json:"has_rocm" yaml:"has_rocm"`
+	HasNVIDIA     bool   `json:"has_nvidia" yaml:"has_nvidia"`
+	HasAMDGPU     bool   `json:"has_amd_gpu" yaml:"has_amd_gpu"`
+	HasIntelGPU   bool   `json:"has_intel_gpu" yaml:"has_intel_gpu"`
+	GPUMemory     int    `json:"gpu_memory_mb" yaml:"gpu_memory_mb"`       // in MB, -1 if unknown
+	SystemMemory  int    `json:"system_memory_mb" yaml:"system_memory_mb"` // in MB
+	GPUModel      string `json:"gpu_model" yaml:"gpu_model"`
+	GPUType       string `json:"gpu_type" yaml:"gpu_type"` // "nvidia", "amd", "intel", or "unknown"
+	GFXTarget     string `json:"gfx_target,omitempty" yaml:"gfx_target,omitempty"`
+	KernelVersion string `json:"kernel_version" yaml:"kernel_version"`
+	GPUs          []GPU    `json:"gpus" yaml:"gpus"`
+	CPU           *CPUInfo `json:"cpu,omitempty" yaml:"cpu,omitempty"`
 }
 
 func NewChecker() *Checker {
-	return &Checker{}
+	return &Checker{root: "/", dockerSocket: docker.DefaultSocket}
 }
 
-func (c *Checker) CheckAll() error {
-	info, err := c.GetSystemInfo()
-	if err != nil {
-		return fmt.Errorf("failed to get system info: %w", err)
-	}
+// NewCheckerWithRoot returns a Checker that reads /sys, /proc and /opt/rocm
+// under root. Tools such as nvidia-smi and rocminfo are only run for the
+// real root.
+func NewCheckerWithRoot(root string) *Checker {
+	return &Checker{root: root, dockerSocket: docker.DefaultSocket}
+}
+
+// SetDockerSocket sets the Docker daemon socket probed for HasDocker.
+func (c *Checker) SetDockerSocket(socket string) {
+	c.dockerSocket = socket
+}
 
-	if err := c.validateRequirements(info); err != nil {
-		return err
+// path resolves an absolute host path against the checker's root.
+func (c *Checker) path(p string) string {
+	if c.root == "" {
+		return p
 	}
+	return filepath.Join(c.root, p)
+}
 
-	c.printSystemInfo(info)
-	return nil
+// onHost reports whether the checker inspects the live system, in which
+// case external tools may be run as well.
+func (c *Checker) onHost() bool {
+	return c.root == "" || c.root == "/"
 }
 
 func (c *Checker) GetSystemInfo() (*SystemInfo, error) {
@@ -51,193 +75,62 @@ func (c *Checker) GetSystemInfo() (*SystemInfo, error) {
 	info.HasDocker = c.checkDocker()
 
 	// Check GPUs
-	info.HasNVIDIA, nvidiaModel, nvidiaMemory := c.checkNVIDIAGPU()
-	info.HasAMDGPU, amdModel, amdMemory := c.checkAMDGPU()
-	
+	gpus, err := c.DetectGPUs()
+	if err != nil {
+		logrus.Warnf("GPU detection failed: %v", err)
+	}
+	info.GPUs = gpus
+	for _, gpu := range gpus {
+		switch gpu.Vendor {
+		case "nvidia":
+			info.HasNVIDIA = true
+		case "amd":
+			info.HasAMDGPU = true
+		case "intel":
+			info.HasIntelGPU = true
+		}
+	}
+
 	// Set primary GPU info
-	if info.HasNVIDIA {
-		info.GPUType = "nvidia"
-		info.GPUModel = nvidiaModel
-		info.GPUMemory = nvidiaMemory
-	} else if info.HasAMDGPU {
-		info.GPUType = "amd"
-		info.GPUModel = amdModel
-		info.GPUMemory = amdMemory
-	} else {
-		info.GPUType = "unknown"
+	info.GPUType = "unknown"
+	info.GPUMemory = -1
+	if gpu := primaryGPU(gpus); gpu != nil {
+		info.GPUType = gpu.Vendor
+		info.GPUModel = gpu.Name
+		info.GPUMemory = gpu.MemoryMB
 	}
 
 	// Check ROCm
 	info.HasROCm = c.checkROCm()
+	if info.HasAMDGPU {
+		info.GFXTarget = c.DetectGFXTarget()
+	}
 
 	// Get system memory
 	info.SystemMemory = c.getSystemMemory()
 
+	// Get CPU features and topology
+	if cpu, err := c.GetCPUInfo(); err == nil {
+		info.CPU = cpu
+	} else {
+		logrus.Warnf("CPU detection failed: %v", err)
+	}
+
 	// Get kernel version
 	info.KernelVersion = c.getKernelVersion()
 
 	return info, nil
 }
 
+// checkDocker reports whether the Docker daemon answers on its socket;
+// an installed CLI alone can't run the stack.
 func (c *Checker) checkDocker() bool {
-	cmd := exec.Command("docker", "--version")
-	err := cmd.Run()
-	return err == nil
-}
-
-func (c *Checker) checkNVIDIAGPU() (bool, string, int) {
-	// Check if NVIDIA GPU is present using lspci
-	cmd := exec.Command("lspci", "-v")
-	output, err := cmd.Output()
-	if err != nil {
-		logrus.Warnf("Failed to run lspci: %v", err)
-		return false, "", 0
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var hasNVIDIA bool
-	var gpuModel string
-	var memory int
-
-	// Look for NVIDIA graphics cards
-	nvidiaRegex := regexp.MustCompile(`(?i)NVIDIA.*(GeForce|RTX|GTX|Tesla|Quadro)`)
-	
-	for i, line := range lines {
-		if strings.Contains(line, "VGA compatible controller") && nvidiaRegex.MatchString(line) {
-			hasNVIDIA = true
-			
-			// Extract GPU model
-			parts := strings.Split(line, ": ")
-			if len(parts) > 1 {
-				gpuModel = strings.TrimSpace(parts[1])
-			}
-
-			// Look for memory information in subsequent lines
-			for j := i + 1; j < len(lines) && j < i+20; j++ {
-				if strings.TrimSpace(lines[j]) == "" {
-					break
-				}
-				
-				// Look for memory size in various formats
-				memRegex := regexp.MustCompile(`(?i)memory.*?(\d+)([MG])B`)
-				matches := memRegex.FindStringSubmatch(lines[j])
-				if len(matches) >= 3 {
-					size, _ := strconv.Atoi(matches[1])
-					if matches[2] == "G" {
-						memory = size * 1024
-					} else {
-						memory = size
-					}
-					break
-				}
-			}
-			break
-		}
-	}
-
-	// If we couldn't get memory from lspci, try nvidia-smi
-	if hasNVIDIA && memory == 0 {
-		memory = c.getNVIDIAMemoryFromSMI()
-	}
-
-	return hasNVIDIA, gpuModel, memory
-}
-
-func (c *Checker) getNVIDIAMemoryFromSMI() int {
-	// Try to get GPU memory from nvidia-smi
-	cmd := exec.Command("nvidia-smi", "--query-gpu=memory.total", "--format=csv,noheader,nounits")
-	output, err := cmd.Output()
-	if err != nil {
-		// Default assumption for RTX 3070 if we can't detect
-		return 8192 // 8GB
-	}
-
-	memStr := strings.TrimSpace(string(output))
-	if mem, err := strconv.Atoi(memStr); err == nil {
-		return mem
-	}
-
-	return 8192 // Default
-}
-
-func (c *Checker) checkAMDGPU() (bool, string, int) {
-	// Check if AMD GPU is present using lspci
-	cmd := exec.Command("lspci", "-v")
-	output, err := cmd.Output()
-	if err != nil {
-		logrus.Warnf("Failed to run lspci: %v", err)
-		return false, "", 0
-	}
-
-	lines := strings.Split(string(output), "\n")
-	var hasAMD bool
-	var gpuModel string
-	var memory int
-
-	// Look for AMD/ATI graphics cards
-	amdRegex := regexp.MustCompile(`(?i)(AMD|ATI).*(Radeon|RX|Ellesmere|Polaris)`)
-	
-	for i, line := range lines {
-		if strings.Contains(line, "VGA compatible controller") && amdRegex.MatchString(line) {
-			hasAMD = true
-			
-			// Extract GPU model
-			parts := strings.Split(line, ": ")
-			if len(parts) > 1 {
-				gpuModel = strings.TrimSpace(parts[1])
-			}
-
-			// Look for memory information in subsequent lines
-			for j := i + 1; j < len(lines) && j < i+20; j++ {
-				if strings.TrimSpace(lines[j]) == "" {
-					break
-				}
-				
-				// Look for memory size in various formats
-				memRegex := regexp.MustCompile(`(?i)memory.*?(\d+)([MG])B`)
-				matches := memRegex.FindStringSubmatch(lines[j])
-				if len(matches) >= 3 {
-					size, _ := strconv.Atoi(matches[1])
-					if matches[2] == "G" {
-						memory = size * 1024
-					} else {
-						memory = size
-					}
-					break
-				}
-			}
-			break
-		}
-	}
-
-	// If we couldn't get memory from lspci, try alternative methods
-	if hasAMD && memory == 0 {
-		memory = c.getGPUMemoryAlternative()
-	}
-
-	return hasAMD, gpuModel, memory
-}
-
-func (c *Checker) getGPUMemoryAlternative() int {
-	// Try to get GPU memory from /sys/class/drm
-	files, err := os.ReadDir("/sys/class/drm")
-	if err != nil {
-		return 0
-	}
-
-	for _, file := range files {
-		if strings.HasPrefix(file.Name(), "card") && !strings.Contains(file.Name(), "-") {
-			memPath := fmt.Sprintf("/sys/class/drm/%s/device/mem_info_vram_total", file.Name())
-			if data, err := os.ReadFile(memPath); err == nil {
-				if size, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64); err == nil {
-					return int(size / (1024 * 1024)) // Convert bytes to MB
-				}
-			}
-		}
+	if !c.onHost() {
+		return false
 	}
-
-	// Default assumption for RX 570/580 if we can't detect
-	return 8192 // 8GB
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return docker.NewClient(c.dockerSocket).Ping(ctx) == nil
 }
 
 func (c *Checker) checkROCm() bool {
@@ -248,7 +141,10 @@ func (c *Checker) checkROCm() bool {
 	}
 
 	for _, path := range paths {
-		if _, err := os.Stat(path); err == nil {
+		if _, err := os.Stat(c.path(path)); err == nil {
+			if !c.onHost() {
+				return true
+			}
 			// Try running rocminfo
 			cmd := exec.Command(path)
 			err := cmd.Run()
@@ -256,18 +152,37 @@ func (c *Checker) checkROCm() bool {
 		}
 	}
 
-	// Check if ROCm modules are loaded
-	cmd := exec.Command("lsmod")
-	output, err := cmd.Output()
+	// Check if the amdgpu kernel module is loaded
+	data, err := os.ReadFile(c.path("/proc/modules"))
 	if err != nil {
 		return false
 	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "amdgpu ") {
+			return true
+		}
+	}
+	return false
+}
 
-	return strings.Contains(string(output), "amdgpu")
+// HasIntelComputeRuntime reports whether the Level Zero GPU driver used by
+// oneAPI and IPEX-LLM is installed on the host.
+func (c *Checker) HasIntelComputeRuntime() bool {
+	patterns := []string{
+		"/usr/lib/x86_64-linux-gnu/libze_intel_gpu.so*",
+		"/usr/lib64/libze_intel_gpu.so*",
+		"/usr/lib/libze_intel_gpu.so*",
+	}
+	for _, pattern := range patterns {
+		if matches, _ := filepath.Glob(c.path(pattern)); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *Checker) getSystemMemory() int {
-	file, err := os.Open("/proc/meminfo")
+	file, err := os.Open(c.path("/proc/meminfo"))
 	if err != nil {
 		return 0
 	}
@@ -290,26 +205,38 @@ func (c *Checker) getSystemMemory() int {
 }
 
 func (c *Checker) getKernelVersion() string {
-	cmd := exec.Command("uname", "-r")
-	output, err := cmd.Output()
+	data, err := os.ReadFile(c.path("/proc/sys/kernel/osrelease"))
 	if err != nil {
 		return "unknown"
 	}
-	return strings.TrimSpace(string(output))
+	return strings.TrimSpace(string(data))
 }
 
-func (c *Checker) validateRequirements(info *SystemInfo) error {
+// RequirementErrors returns the minimum hardware and software requirements
+// that info does not meet for the given deployment type; empty uses the
+// detected GPU. CPU-only deployments are judged on RAM and SIMD support.
+func (c *Checker) RequirementErrors(info *SystemInfo, gpuType string) []string {
 	var errors []string
 
 	if !info.HasDocker {
 		errors = append(errors, "Docker is not installed or not accessible")
 	}
 
-	if !info.HasNVIDIA && !info.HasAMDGPU {
-		errors = append(errors, "No supported GPU detected (NVIDIA or AMD)")
+	if gpuType == "cpu" {
+		if info.CPU != nil && !info.CPU.AVX {
+			errors = append(errors, "CPU does not support AVX, which CPU inference requires")
+		}
+		if info.SystemMemory < 8192 { // 8GB minimum
+			errors = append(errors, fmt.Sprintf("System memory (%dMB) is below recommended minimum for CPU inference (8GB)", info.SystemMemory))
+		}
+		return errors
+	}
+
+	if !info.HasNVIDIA && !info.HasAMDGPU && !info.HasIntelGPU {
+		errors = append(errors, "No supported GPU detected (NVIDIA, AMD or Intel); use CPU-only mode with --gpu cpu")
 	}
 
-	if info.GPUMemory < 6144 { // 6GB minimum
+	if info.GPUMemory >= 0 && info.GPUMemory < 6144 { // 6GB minimum
 		errors = append(errors, fmt.Sprintf("GPU memory (%dMB) is below recommended minimum (6GB)", info.GPUMemory))
 	}
 
@@ -317,47 +244,5 @@ func (c *Checker) validateRequirements(info *SystemInfo) error {
 		errors = append(errors, fmt.Sprintf("System memory (%dMB) is below recommended minimum (8GB)", info.SystemMemory))
 	}
 
-	if info.GPUType == "amd" && !info.HasROCm {
-		logrus.Warn("ROCm not detected - AMD GPU acceleration may not work properly")
-		logrus.Warn("Install ROCm for optimal performance: https://rocm.docs.amd.com/projects/install-on-linux/en/latest/")
-	}
-	
-	if info.GPUType == "nvidia" {
-		// Check for NVIDIA Container Toolkit
-		cmd := exec.Command("docker", "run", "--rm", "--gpus", "all", "nvidia/cuda:11.0-base", "nvidia-smi")
-		err := cmd.Run()
-		if err != nil {
-			logrus.Warn("NVIDIA Container Toolkit not detected - GPU acceleration may not work properly")
-			logrus.Warn("Install NVIDIA Container Toolkit: https://docs.nvidia.com/datacenter/cloud-native/container-toolkit/install-guide.html")
-		}
-	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("system requirements not met:\n  - %s", strings.Join(errors, "\n  - "))
-	}
-
-	return nil
+	return errors
 }
-
-func (c *Checker) printSystemInfo(info *SystemInfo) {
-	logrus.Info("=== System Information ===")
-	logrus.Infof("Kernel Version: %s", info.KernelVersion)
-	logrus.Infof("Docker: %v", info.HasDocker)
-	logrus.Infof("GPU Type: %s", info.GPUType)
-	
-	if info.HasNVIDIA {
-		logrus.Infof("NVIDIA GPU: %v", info.HasNVIDIA)
-		logrus.Infof("GPU Model: %s", info.GPUModel)
-		logrus.Infof("GPU Memory: %d MB", info.GPUMemory)
-	}
-	
-	if info.HasAMDGPU {
-		logrus.Infof("AMD GPU: %v", info.HasAMDGPU)
-		logrus.Infof("GPU Model: %s", info.GPUModel)
-		logrus.Infof("GPU Memory: %d MB", info.GPUMemory)
-		logrus.Infof("ROCm: %v", info.HasROCm)
-	}
-	
-	logrus.Infof("System Memory: %d MB", info.SystemMemory)
-	logrus.Info("=========================")
-}
\ No newline at end of file
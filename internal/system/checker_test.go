@@ -0,0 +1,145 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// unpackFixture expands a txtar-style host capture from testdata into a
+// temporary root. A section named "path -> target" is a symlink. Captures
+// are archives rather than trees because sysfs names contain colons, which
+// module zips do not allow.
+func unpackFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name+".txtar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := t.TempDir()
+	var file string
+	var content strings.Builder
+	flush := func() {
+		if file == "" {
+			return
+		}
+		path, target, isLink := strings.Cut(file, " -> ")
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if isLink {
+			err = os.Symlink(target, path)
+		} else {
+			err = os.WriteFile(path, []byte(content.String()), 0644)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content.Reset()
+	}
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		if strings.HasPrefix(line, "-- ") && strings.HasSuffix(line, " --\n") {
+			flush()
+			file = strings.TrimSuffix(strings.TrimPrefix(line, "-- "), " --\n")
+			continue
+		}
+		if file != "" {
+			content.WriteString(line)
+		}
+	}
+	flush()
+	return root
+}
+
+func TestGetSystemInfoFromFixture(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    SystemInfo
+		rocm    string
+	}{
+		{
+			fixture: "amd-rx6700xt",
+			want: SystemInfo{
+				HasROCm:       true,
+				HasAMDGPU:     true,
+				GPUMemory:     12272,
+				SystemMemory:  32000,
+				GPUModel:      "AMD Navi 22 [Radeon RX 6700/6700 XT/6750 XT / 6800M/6850M XT]",
+				GPUType:       "amd",
+				GFXTarget:     "gfx1031",
+				KernelVersion: "6.8.0-45-generic",
+				GPUs: []GPU{{
+					Address: "0000:03:00.0", Vendor: "amd", VendorID: "1002", DeviceID: "73df",
+					Name:   "AMD Navi 22 [Radeon RX 6700/6700 XT/6750 XT / 6800M/6850M XT]",
+					Driver: "amdgpu", MemoryMB: 12272,
+				}},
+				CPU: &CPUInfo{
+					Model: "Test CPU", Sockets: 1, Cores: 8, Threads: 16, AVX: true, AVX2: true,
+					NUMANodes: []NUMANode{{ID: 0, CPUs: "0-15"}},
+				},
+			},
+			rocm: "6.1.2",
+		},
+		{
+			fixture: "nvidia-intel",
+			want: SystemInfo{
+				HasNVIDIA:     true,
+				HasIntelGPU:   true,
+				GPUMemory:     -1, // nvidia-smi is not run against a fixture
+				SystemMemory:  64000,
+				GPUModel:      "NVIDIA AD102 [GeForce RTX 4090]",
+				GPUType:       "nvidia",
+				KernelVersion: "6.1.0-25-amd64",
+				GPUs: []GPU{
+					{
+						Address: "0000:00:02.0", Vendor: "intel", VendorID: "8086", DeviceID: "a780",
+						Name:   "Intel Raptor Lake-S GT1 [UHD Graphics 770]",
+						Driver: "i915", MemoryMB: -1,
+					},
+					{
+						Address: "0000:01:00.0", Vendor: "nvidia", VendorID: "10de", DeviceID: "2684",
+						Name:   "NVIDIA AD102 [GeForce RTX 4090]",
+						Driver: "nvidia", MemoryMB: -1,
+					},
+				},
+				CPU: &CPUInfo{
+					Model: "Test CPU", Sockets: 2, Cores: 16, Threads: 32, AVX: true, AVX2: true, AVX512: true,
+					NUMANodes: []NUMANode{{ID: 0, CPUs: "0-15"}, {ID: 1, CPUs: "16-31"}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			checker := NewCheckerWithRoot(unpackFixture(t, tt.fixture))
+			info, err := checker.GetSystemInfo()
+			if err != nil {
+				t.Fatalf("GetSystemInfo: %v", err)
+			}
+			if !reflect.DeepEqual(*info, tt.want) {
+				t.Errorf("GetSystemInfo:\n got %+v\nwant %+v", *info, tt.want)
+				if info.CPU != nil {
+					t.Errorf("CPU: got %+v, want %+v", *info.CPU, *tt.want.CPU)
+				}
+			}
+			if got := checker.ROCmVersion(); got != tt.rocm {
+				t.Errorf("ROCmVersion = %q, want %q", got, tt.rocm)
+			}
+		})
+	}
+}
+
+func TestGetSystemInfoEmptyRoot(t *testing.T) {
+	info, err := NewCheckerWithRoot(t.TempDir()).GetSystemInfo()
+	if err != nil {
+		t.Fatalf("GetSystemInfo: %v", err)
+	}
+	if info.GPUType != "unknown" || info.HasROCm || info.KernelVersion != "unknown" || info.SystemMemory != 0 {
+		t.Errorf("expected nothing detected, got %+v", *info)
+	}
+}
@@ -0,0 +1,171 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CPUInfo describes the host CPU as it matters for CPU-only inference:
+// llama.cpp needs AVX and runs markedly faster with AVX2 and AVX-512.
+type CPUInfo struct {
+	Model     string     `json:"model" yaml:"model"`
+	Sockets   int        `json:"sockets" yaml:"sockets"`
+	Cores     int        `json:"cores" yaml:"cores"`     // physical cores
+	Threads   int        `json:"threads" yaml:"threads"` // logical CPUs
+	AVX       bool       `json:"avx" yaml:"avx"`
+	AVX2      bool       `json:"avx2" yaml:"avx2"`
+	AVX512    bool       `json:"avx512" yaml:"avx512"`
+	NUMANodes []NUMANode `json:"numa_nodes,omitempty" yaml:"numa_nodes,omitempty"`
+}
+
+// NUMANode is a memory node and the CPUs local to it.
+type NUMANode struct {
+	ID   int    `json:"id" yaml:"id"`
+	CPUs string `json:"cpus" yaml:"cpus"` // cpulist format, e.g. "0-7,16-23"
+}
+
+// GetCPUInfo parses /proc/cpuinfo and the NUMA topology from sysfs.
+func (c *Checker) GetCPUInfo() (*CPUInfo, error) {
+	file, err := os.Open(c.path("/proc/cpuinfo"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cpuinfo: %w", err)
+	}
+	defer file.Close()
+
+	info := &CPUInfo{}
+	cores := make(map[string]bool)   // "physical id/core id"
+	sockets := make(map[string]bool) // "physical id"
+	var physicalID string
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "processor":
+			info.Threads++
+		case "model name":
+			if info.Model == "" {
+				info.Model = value
+			}
+		case "physical id":
+			physicalID = value
+			sockets[value] = true
+		case "core id":
+			cores[physicalID+"/"+value] = true
+		case "flags":
+			if info.Threads > 1 {
+				continue
+			}
+			for _, flag := range strings.Fields(value) {
+				switch flag {
+				case "avx":
+					info.AVX = true
+				case "avx2":
+					info.AVX2 = true
+				case "avx512f":
+					info.AVX512 = true
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cpuinfo: %w", err)
+	}
+
+	// Some VMs and ARM kernels omit topology fields.
+	info.Sockets = len(sockets)
+	if info.Sockets == 0 {
+		info.Sockets = 1
+	}
+	info.Cores = len(cores)
+	if info.Cores == 0 {
+		info.Cores = info.Threads
+	}
+
+	info.NUMANodes = c.numaNodes()
+
+	return info, nil
+}
+
+func (c *Checker) numaNodes() []NUMANode {
+	paths, _ := filepath.Glob(c.path("/sys/devices/system/node/node[0-9]*/cpulist"))
+
+	var nodes []NUMANode
+	for _, path := range paths {
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(path)), "node"))
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		cpus := strings.TrimSpace(string(data))
+		if cpus == "" {
+			continue // memory-only node
+		}
+		nodes = append(nodes, NUMANode{ID: id, CPUs: cpus})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+// RecommendedThreads is the inference thread count to use. llama.cpp is
+// memory-bandwidth bound, so hyperthreads don't help, and on multi-node
+// systems it runs fastest pinned to a single node's cores.
+func (ci *CPUInfo) RecommendedThreads() int {
+	threads := ci.Cores
+	if n := len(ci.NUMANodes); n > 1 {
+		threads = ci.Cores / n
+	}
+	if threads < 1 {
+		threads = 1
+	}
+	return threads
+}
+
+// PinnedCPUs returns the cpuset to pin inference to on multi-node systems,
+// or "" when the host has a single NUMA node.
+func (ci *CPUInfo) PinnedCPUs() string {
+	if len(ci.NUMANodes) < 2 {
+		return ""
+	}
+	return ci.NUMANodes[0].CPUs
+}
+
+// Recommendations returns thread and NUMA tuning advice for CPU inference.
+func (ci *CPUInfo) Recommendations() []string {
+	var recs []string
+
+	switch {
+	case ci.AVX512:
+		recs = append(recs, "AVX-512 available: llama.cpp will use its fastest CPU kernels")
+	case ci.AVX2:
+		recs = append(recs, "AVX2 available: expect roughly 5-10 tokens/s on 3B Q4 models")
+	case ci.AVX:
+		recs = append(recs, "Only AVX available (no AVX2): stick to 1B-3B models")
+	}
+
+	recs = append(recs, fmt.Sprintf("Use %d inference threads (PARAMETER num_thread %d in a Modelfile); hyperthreads don't speed up inference",
+		ci.RecommendedThreads(), ci.RecommendedThreads()))
+
+	if cpus := ci.PinnedCPUs(); cpus != "" {
+		recs = append(recs, fmt.Sprintf("%d NUMA nodes: pin Ollama to node %d (cpuset %s) so weights stay in local memory",
+			len(ci.NUMANodes), ci.NUMANodes[0].ID, cpus))
+	}
+
+	return recs
+}
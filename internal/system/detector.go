@@ -0,0 +1,46 @@
+package system
+
+import (
+	"sync"
+	"time"
+)
+
+// Detector caches the result of Checker.GetSystemInfo so long-running
+// callers such as the status dashboard don't rescan sysfs and re-run rocminfo on
+// every refresh. Hardware rarely changes, so a TTL of minutes is fine.
+type Detector struct {
+	checker *Checker
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	info      *SystemInfo
+	err       error
+	fetchedAt time.Time
+}
+
+func NewDetector(checker *Checker, ttl time.Duration) *Detector {
+	return &Detector{
+		checker: checker,
+		ttl:     ttl,
+	}
+}
+
+// Info returns the cached system info, refreshing it once the TTL expires.
+func (d *Detector) Info() (*SystemInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.info == nil || time.Since(d.fetchedAt) > d.ttl {
+		d.info, d.err = d.checker.GetSystemInfo()
+		d.fetchedAt = time.Now()
+	}
+
+	return d.info, d.err
+}
+
+// Invalidate forces the next call to Info to re-detect.
+func (d *Detector) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.info = nil
+}
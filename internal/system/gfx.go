@@ -0,0 +1,133 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GFXInfo describes how well ROCm supports a GPU ISA target and which
+// HSA_OVERRIDE_GFX_VERSION, if any, is known to work for it.
+type GFXInfo struct {
+	Target    string `json:"target" yaml:"target"`
+	Family    string `json:"family" yaml:"family"`
+	Cards     string `json:"cards" yaml:"cards"`
+	Supported bool   `json:"supported" yaml:"supported"` // officially supported by current ROCm
+	// Override is the known-good HSA_OVERRIDE_GFX_VERSION for targets
+	// that work by masquerading as a supported sibling; empty when the
+	// target is supported natively or no override is known to work.
+	Override string `json:"override,omitempty" yaml:"override,omitempty"`
+	Notes    string `json:"notes,omitempty" yaml:"notes,omitempty"`
+}
+
+// gfxTable is the maintained list of AMD GPU targets. Overrides only work
+// within an ISA family, so RDNA2 parts borrow gfx1030 and RDNA3 parts
+// borrow gfx1100.
+var gfxTable = []GFXInfo{
+	{Target: "gfx803", Family: "GCN 4 (Polaris)", Cards: "RX 470/480/570/580/590",
+		Notes: "Dropped after ROCm 4.x; no override works. Use the Vulkan or CPU backend, or a community gfx803 build."},
+	{Target: "gfx900", Family: "GCN 5 (Vega 10)", Cards: "Vega 56/64, MI25",
+		Notes: "Dropped in ROCm 6.0; use ROCm 5.7 images."},
+	{Target: "gfx906", Family: "GCN 5 (Vega 20)", Cards: "Radeon VII, MI50/60", Supported: true,
+		Notes: "Maintenance support only."},
+	{Target: "gfx908", Family: "CDNA 1", Cards: "MI100", Supported: true},
+	{Target: "gfx90a", Family: "CDNA 2", Cards: "MI210/250", Supported: true},
+	{Target: "gfx90c", Family: "GCN 5 (Renoir/Cezanne APU)", Cards: "Ryzen 4000/5000 APU graphics", Override: "9.0.0",
+		Notes: "Shares system memory; set a large UMA frame buffer in BIOS."},
+	{Target: "gfx942", Family: "CDNA 3", Cards: "MI300", Supported: true},
+	{Target: "gfx1010", Family: "RDNA 1 (Navi 10)", Cards: "RX 5600/5700",
+		Notes: "Not supported by ROCm; RDNA2 overrides do not work."},
+	{Target: "gfx1012", Family: "RDNA 1 (Navi 14)", Cards: "RX 5500",
+		Notes: "Not supported by ROCm; RDNA2 overrides do not work."},
+	{Target: "gfx1030", Family: "RDNA 2 (Navi 21)", Cards: "RX 6800/6900, PRO W6800", Supported: true},
+	{Target: "gfx1031", Family: "RDNA 2 (Navi 22)", Cards: "RX 6700/6750 XT", Override: "10.3.0"},
+	{Target: "gfx1032", Family: "RDNA 2 (Navi 23)", Cards: "RX 6600/6650 XT", Override: "10.3.0"},
+	{Target: "gfx1034", Family: "RDNA 2 (Navi 24)", Cards: "RX 6400/6500 XT", Override: "10.3.0"},
+	{Target: "gfx1035", Family: "RDNA 2 (Rembrandt APU)", Cards: "Radeon 660M/680M", Override: "10.3.0"},
+	{Target: "gfx1036", Family: "RDNA 2 (Raphael iGPU)", Cards: "Ryzen 7000 desktop graphics", Override: "10.3.0"},
+	{Target: "gfx1100", Family: "RDNA 3 (Navi 31)", Cards: "RX 7900, PRO W7900", Supported: true},
+	{Target: "gfx1101", Family: "RDNA 3 (Navi 32)", Cards: "RX 7700/7800 XT", Supported: true},
+	{Target: "gfx1102", Family: "RDNA 3 (Navi 33)", Cards: "RX 7600", Override: "11.0.0"},
+	{Target: "gfx1103", Family: "RDNA 3 (Phoenix APU)", Cards: "Radeon 760M/780M", Override: "11.0.0"},
+	{Target: "gfx1150", Family: "RDNA 3.5 (Strix Point)", Cards: "Radeon 880M/890M", Override: "11.0.0"},
+	{Target: "gfx1151", Family: "RDNA 3.5 (Strix Halo)", Cards: "Radeon 8060S", Supported: true},
+	{Target: "gfx1200", Family: "RDNA 4 (Navi 44)", Cards: "RX 9060", Supported: true},
+	{Target: "gfx1201", Family: "RDNA 4 (Navi 48)", Cards: "RX 9070", Supported: true},
+}
+
+// LookupGFX returns the table entry for a gfx target.
+func LookupGFX(target string) (GFXInfo, bool) {
+	for _, info := range gfxTable {
+		if info.Target == target {
+			return info, true
+		}
+	}
+	return GFXInfo{Target: target}, false
+}
+
+// ROCmEnv is the ROCm environment to configure for a GPU.
+type ROCmEnv struct {
+	// GFXTarget is the GPU's native target, used for HCC_AMDGPU_TARGET.
+	GFXTarget string
+	// HSAOverrideGFXVersion is empty when no override should be set.
+	HSAOverrideGFXVersion string
+	Info                  GFXInfo
+	Known                 bool
+}
+
+// ROCmEnvFor returns the recommended ROCm environment for a gfx target.
+func ROCmEnvFor(target string) ROCmEnv {
+	info, known := LookupGFX(target)
+	return ROCmEnv{
+		GFXTarget:             target,
+		HSAOverrideGFXVersion: info.Override,
+		Info:                  info,
+		Known:                 known,
+	}
+}
+
+// DetectGFXTarget returns the gfx target of the first GPU, read from the
+// KFD topology in sysfs and falling back to rocminfo. Returns "" if no
+// ROCm-capable GPU is found.
+func (c *Checker) DetectGFXTarget() string {
+	if target := gfxTargetFromKFD(c.path("/sys/class/kfd/kfd/topology/nodes")); target != "" {
+		return target
+	}
+	if !c.onHost() {
+		return ""
+	}
+	return gfxTargetFromROCmInfo()
+}
+
+// gfxTargetFromKFD reads gfx_target_version from each KFD topology node.
+// CPU nodes report 0. The value encodes major*10000 + minor*100 + stepping,
+// e.g. 100301 for gfx1031.
+func gfxTargetFromKFD(nodesDir string) string {
+	nodes, err := filepath.Glob(filepath.Join(nodesDir, "*", "properties"))
+	if err != nil {
+		return ""
+	}
+	sort.Strings(nodes)
+
+	for _, path := range nodes {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 || fields[0] != "gfx_target_version" {
+				continue
+			}
+			v, err := strconv.Atoi(fields[1])
+			if err != nil || v == 0 {
+				continue
+			}
+			return fmt.Sprintf("gfx%d%x%x", v/10000, (v/100)%100, v%100)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,275 @@
+package system
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PCI vendor IDs of the GPU vendors lite-llm supports.
+const (
+	pciVendorAMD    = "1002"
+	pciVendorNVIDIA = "10de"
+	pciVendorIntel  = "8086"
+)
+
+// pciVendors maps PCI vendor IDs to GPUType values.
+var pciVendors = map[string]string{
+	pciVendorAMD:    "amd",
+	pciVendorNVIDIA: "nvidia",
+	pciVendorIntel:  "intel",
+}
+
+// GPU is a display controller found on the PCI bus.
+type GPU struct {
+	Address  string `json:"address" yaml:"address"` // e.g. "0000:03:00.0"
+	Vendor   string `json:"vendor" yaml:"vendor"`   // "amd", "nvidia" or "intel"
+	VendorID string `json:"vendor_id" yaml:"vendor_id"`
+	DeviceID string `json:"device_id" yaml:"device_id"`
+	Name     string `json:"name" yaml:"name"`
+	Driver   string `json:"driver,omitempty" yaml:"driver,omitempty"`
+	MemoryMB int    `json:"memory_mb" yaml:"memory_mb"` // -1 if unknown
+}
+
+//go:embed pci.ids
+var pciIDsData string
+
+var (
+	pciNamesOnce sync.Once
+	pciNames     map[string]string // "vvvv:dddd" -> device name, "vvvv" -> vendor name
+)
+
+// pciName returns the name of a device from the embedded PCI ID table,
+// falling back to the raw IDs.
+func pciName(vendorID, deviceID string) string {
+	pciNamesOnce.Do(func() {
+		pciNames = parsePCIIDs(pciIDsData)
+	})
+
+	vendor, ok := pciNames[vendorID]
+	if !ok {
+		vendor = "Vendor " + vendorID
+	}
+	if device, ok := pciNames[vendorID+":"+deviceID]; ok {
+		return vendor + " " + device
+	}
+	return fmt.Sprintf("%s device %s", vendor, deviceID)
+}
+
+// parsePCIIDs parses the vendor and device lines of the pci.ids format.
+// Subsystem lines (two tabs) and device classes are ignored.
+func parsePCIIDs(data string) map[string]string {
+	names := make(map[string]string)
+	var vendor string
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "\t\t") {
+			continue
+		}
+		if strings.HasPrefix(line, "C ") {
+			break
+		}
+
+		id, name, ok := strings.Cut(strings.TrimPrefix(line, "\t"), "  ")
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			if vendor != "" {
+				names[vendor+":"+id] = strings.TrimSpace(name)
+			}
+			continue
+		}
+		vendor = id
+		names[vendor] = shortVendorName(strings.TrimSpace(name))
+	}
+
+	return names
+}
+
+func shortVendorName(name string) string {
+	switch {
+	case strings.HasPrefix(name, "Advanced Micro Devices"):
+		return "AMD"
+	case strings.HasPrefix(name, "NVIDIA"):
+		return "NVIDIA"
+	case strings.HasPrefix(name, "Intel"):
+		return "Intel"
+	}
+	return name
+}
+
+// DetectGPUs lists the display controllers (PCI class 0x03xx) from
+// supported vendors, ordered by PCI address.
+func (c *Checker) DetectGPUs() ([]GPU, error) {
+	devicesDir := c.path("/sys/bus/pci/devices")
+	entries, err := os.ReadDir(devicesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PCI devices: %w", err)
+	}
+
+	var gpus []GPU
+	for _, entry := range entries {
+		dir := filepath.Join(devicesDir, entry.Name())
+
+		// VGA (0x0300), 3D (0x0302) and other (0x0380) display controllers.
+		if !strings.HasPrefix(readSysfsHex(filepath.Join(dir, "class")), "03") {
+			continue
+		}
+		vendorID := readSysfsHex(filepath.Join(dir, "vendor"))
+		vendor, ok := pciVendors[vendorID]
+		if !ok {
+			continue
+		}
+		deviceID := readSysfsHex(filepath.Join(dir, "device"))
+
+		gpu := GPU{
+			Address:  entry.Name(),
+			Vendor:   vendor,
+			VendorID: vendorID,
+			DeviceID: deviceID,
+			Name:     pciName(vendorID, deviceID),
+			MemoryMB: -1,
+		}
+		if link, err := os.Readlink(filepath.Join(dir, "driver")); err == nil {
+			gpu.Driver = filepath.Base(link)
+		}
+		if vendor == "amd" {
+			gpu.MemoryMB = readVRAMTotalMB(filepath.Join(dir, "mem_info_vram_total"))
+		}
+
+		gpus = append(gpus, gpu)
+	}
+
+	sort.Slice(gpus, func(i, j int) bool { return gpus[i].Address < gpus[j].Address })
+
+	if c.onHost() {
+		fillNVIDIAMemory(gpus)
+	}
+
+	return gpus, nil
+}
+
+// primaryGPU picks the GPU lite-llm deploys against: NVIDIA first (the
+// CUDA path is the most mature), then AMD, then Intel, preferring the card
+// with the most VRAM so a discrete card wins over an APU. Intel does not
+// report VRAM, so Arc cards win over integrated graphics by device ID.
+func primaryGPU(gpus []GPU) *GPU {
+	for _, vendor := range []string{"nvidia", "amd", "intel"} {
+		var best *GPU
+		for i := range gpus {
+			if gpus[i].Vendor != vendor {
+				continue
+			}
+			if best == nil || gpus[i].MemoryMB > best.MemoryMB ||
+				(vendor == "intel" && intelDiscrete(gpus[i].DeviceID) && !intelDiscrete(best.DeviceID)) {
+				best = &gpus[i]
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
+
+// intelDiscrete reports whether an Intel device ID belongs to a discrete
+// Arc card: Alchemist (DG2, 0x56xx) or Battlemage (0xe2xx).
+func intelDiscrete(deviceID string) bool {
+	return strings.HasPrefix(deviceID, "56") || strings.HasPrefix(deviceID, "e2")
+}
+
+// readSysfsHex reads a sysfs ID file such as "0x1002" and returns the
+// lower-case hex digits without the prefix.
+func readSysfsHex(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(string(data)), "0x"))
+}
+
+// readVRAMTotalMB reads the amdgpu VRAM size in bytes, returning -1 if it
+// is unavailable.
+func readVRAMTotalMB(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return -1
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil || size <= 0 {
+		return -1
+	}
+	return int(size / (1024 * 1024))
+}
+
+// fillNVIDIAMemory asks nvidia-smi for the memory of each NVIDIA GPU,
+// matching rows by PCI bus ID. The proprietary driver does not expose VRAM
+// in sysfs.
+func fillNVIDIAMemory(gpus []GPU) {
+	hasNVIDIA := false
+	for _, gpu := range gpus {
+		if gpu.Vendor == "nvidia" {
+			hasNVIDIA = true
+			break
+		}
+	}
+	if !hasNVIDIA {
+		return
+	}
+
+	output, err := exec.Command("nvidia-smi", "--query-gpu=pci.bus_id,memory.total", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		busID, mem, ok := strings.Cut(line, ",")
+		if !ok {
+			continue
+		}
+		mb, err := strconv.Atoi(strings.TrimSpace(mem))
+		if err != nil {
+			continue
+		}
+		// nvidia-smi reports "00000000:01:00.0"; sysfs uses "0000:01:00.0".
+		busID = strings.ToLower(strings.TrimSpace(busID))
+		for i := range gpus {
+			_, bdf, _ := strings.Cut(gpus[i].Address, ":")
+			if gpus[i].Vendor == "nvidia" && strings.HasSuffix(busID, ":"+bdf) {
+				gpus[i].MemoryMB = mb
+			}
+		}
+	}
+}
+
+// FormatMemoryMB renders a memory size that may be unknown (-1).
+func FormatMemoryMB(mb int) string {
+	if mb < 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d MB", mb)
+}
+
+// FormatBytes renders a size in binary units, e.g. "4.4 GB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
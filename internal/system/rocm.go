@@ -0,0 +1,63 @@
+package system
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var gfxNameRegex = regexp.MustCompile(`(?m)^\s*Name:\s+(gfx[0-9a-f]+)\s*$`)
+
+// ROCmVersion returns the installed ROCm version, or "" if ROCm is not
+// installed.
+func (c *Checker) ROCmVersion() string {
+	data, err := os.ReadFile(c.path("/opt/rocm/.info/version"))
+	if err != nil {
+		return ""
+	}
+	// e.g. "6.1.2-119"
+	return strings.SplitN(strings.TrimSpace(string(data)), "-", 2)[0]
+}
+
+// gfxTargetFromROCmInfo returns the gfx target of the first GPU agent
+// reported by rocminfo (e.g. "gfx803"), or "" if it cannot be determined.
+func gfxTargetFromROCmInfo() string {
+	for _, path := range []string{"/opt/rocm/bin/rocminfo", "rocminfo"} {
+		output, err := exec.Command(path).Output()
+		if err != nil {
+			continue
+		}
+		if m := gfxNameRegex.FindStringSubmatch(string(output)); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// GFXVersion converts a gfx target into the dotted version form used by
+// HSA_OVERRIDE_GFX_VERSION, e.g. "gfx1030" -> "10.3.0", "gfx90c" -> "9.0.12".
+// The last two characters are the minor and stepping digits in hex.
+func GFXVersion(target string) (string, error) {
+	digits := strings.TrimPrefix(target, "gfx")
+	if digits == target || len(digits) < 3 {
+		return "", fmt.Errorf("invalid gfx target: %s", target)
+	}
+
+	major, err := strconv.Atoi(digits[:len(digits)-2])
+	if err != nil {
+		return "", fmt.Errorf("invalid gfx target: %s", target)
+	}
+	minor, err := strconv.ParseInt(digits[len(digits)-2:len(digits)-1], 16, 0)
+	if err != nil {
+		return "", fmt.Errorf("invalid gfx target: %s", target)
+	}
+	stepping, err := strconv.ParseInt(digits[len(digits)-1:], 16, 0)
+	if err != nil {
+		return "", fmt.Errorf("invalid gfx target: %s", target)
+	}
+
+	return fmt.Sprintf("%d.%d.%d", major, minor, stepping), nil
+}
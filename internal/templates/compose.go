@@ -0,0 +1,330 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeFile is the subset of the Compose specification lite-llm
+// generates. Unknown keys are rejected by ParseCompose, so every field the
+// generators emit must be modelled here.
+type ComposeFile struct {
+	// Header is written as a comment block above the YAML.
+	Header []string `yaml:"-"`
+
+	Version  string              `yaml:"version,omitempty"`
+	Services map[string]*Service `yaml:"services"`
+	Volumes  map[string]*Volume  `yaml:"volumes,omitempty"`
+	Networks map[string]*Network `yaml:"networks,omitempty"`
+	Configs  map[string]*Config  `yaml:"configs,omitempty"`
+	Secrets  map[string]*Secret  `yaml:"secrets,omitempty"`
+
+	// Notes is written as a comment block below the YAML.
+	Notes []string `yaml:"-"`
+}
+
+type Service struct {
+	Image         string                `yaml:"image"`
+	ContainerName string                `yaml:"container_name,omitempty"`
+	Restart       string                `yaml:"restart,omitempty"`
+	Command       []string              `yaml:"command,omitempty"`
+	CPUSet        string                `yaml:"cpuset,omitempty"`
+	ShmSize       string                `yaml:"shm_size,omitempty"`
+	Ports         []Port                `yaml:"ports,omitempty"`
+	Volumes       []string              `yaml:"volumes,omitempty"`
+	Devices       []string              `yaml:"devices,omitempty"`
+	ExtraHosts    []string              `yaml:"extra_hosts,omitempty"`
+	Configs       []ServiceConfig       `yaml:"configs,omitempty"`
+	Secrets       []string              `yaml:"secrets,omitempty"`
+	Environment   []string              `yaml:"environment,omitempty"`
+	Labels        []string              `yaml:"labels,omitempty"`
+	Deploy        *Deploy               `yaml:"deploy,omitempty"`
+	DependsOn     map[string]Dependency `yaml:"depends_on,omitempty"`
+	Healthcheck   *Healthcheck          `yaml:"healthcheck,omitempty"`
+	Networks      []string              `yaml:"networks,omitempty"`
+}
+
+// Port is a "host:container" mapping. It is always quoted so YAML 1.1
+// parsers don't read values like 22:22 as base-60 integers.
+type Port string
+
+func (p Port) MarshalYAML() (interface{}, error) {
+	return &yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: string(p)}, nil
+}
+
+type Dependency struct {
+	Condition string `yaml:"condition"`
+}
+
+type Healthcheck struct {
+	Test        []string `yaml:"test,flow"`
+	Interval    string   `yaml:"interval,omitempty"`
+	Timeout     string   `yaml:"timeout,omitempty"`
+	Retries     int      `yaml:"retries,omitempty"`
+	StartPeriod string   `yaml:"start_period,omitempty"`
+}
+
+type Deploy struct {
+	Resources Resources `yaml:"resources"`
+}
+
+type Resources struct {
+	Limits       *ResourceSpec `yaml:"limits,omitempty"`
+	Reservations *ResourceSpec `yaml:"reservations,omitempty"`
+}
+
+type ResourceSpec struct {
+	CPUs    string          `yaml:"cpus,omitempty"`
+	Memory  string          `yaml:"memory,omitempty"`
+	Devices []DeviceRequest `yaml:"devices,omitempty"`
+}
+
+type DeviceRequest struct {
+	Driver       string   `yaml:"driver"`
+	Count        int      `yaml:"count,omitempty"`
+	Capabilities []string `yaml:"capabilities,flow"`
+}
+
+// ServiceConfig mounts a top-level config into a container.
+type ServiceConfig struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+}
+
+// Config is a top-level config. Content inlines the file so a stack stays
+// a single deployable document.
+type Config struct {
+	Content string `yaml:"content,omitempty"`
+	File    string `yaml:"file,omitempty"`
+}
+
+// Secret is a top-level secret read from a file on the deploying host and
+// mounted at /run/secrets/<name>.
+type Secret struct {
+	File string `yaml:"file"`
+}
+
+type Volume struct {
+	Driver string   `yaml:"driver,omitempty"`
+	Labels []string `yaml:"labels,omitempty"`
+}
+
+type Network struct {
+	Driver string   `yaml:"driver,omitempty"`
+	Labels []string `yaml:"labels,omitempty"`
+}
+
+// Marshal renders the compose file with its header and notes comments.
+func (c *ComposeFile) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	writeComments(&buf, c.Header)
+	if len(c.Header) > 0 {
+		buf.WriteString("\n")
+	}
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return nil, fmt.Errorf("failed to marshal compose file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal compose file: %w", err)
+	}
+
+	if len(c.Notes) > 0 {
+		buf.WriteString("\n")
+		writeComments(&buf, c.Notes)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeComments(buf *bytes.Buffer, lines []string) {
+	for _, line := range lines {
+		if line == "" {
+			buf.WriteString("#\n")
+			continue
+		}
+		buf.WriteString("# " + line + "\n")
+	}
+}
+
+// ParseCompose decodes a compose file, rejecting keys lite-llm does not
+// model, and validates it.
+func ParseCompose(data []byte) (*ComposeFile, error) {
+	var c ComposeFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid compose file: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+var (
+	serviceNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	portRegex        = regexp.MustCompile(`^(\d{1,3}(\.\d{1,3}){3}:)?\d{1,5}:\d{1,5}(/(tcp|udp))?$`)
+	durationRegex    = regexp.MustCompile(`^(\d+(\.\d+)?(us|ms|s|m|h))+$`)
+)
+
+var restartPolicies = map[string]bool{
+	"": true, "no": true, "always": true, "on-failure": true, "unless-stopped": true,
+}
+
+var dependencyConditions = map[string]bool{
+	"service_started": true, "service_healthy": true, "service_completed_successfully": true,
+}
+
+// Validate checks the compose file against the rules of the Compose
+// specification that apply to the fields lite-llm uses: required images,
+// port and duration syntax, restart policies, and that every referenced
+// service, named volume, network, config and secret is declared.
+func (c *ComposeFile) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if len(c.Services) == 0 {
+		add("no services defined")
+	}
+
+	names := make([]string, 0, len(c.Services))
+	for name := range c.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		svc := c.Services[name]
+		if !serviceNameRegex.MatchString(name) {
+			add("service %q: invalid name", name)
+		}
+		if svc == nil {
+			add("service %q: empty definition", name)
+			continue
+		}
+		if svc.Image == "" {
+			add("service %q: image is required", name)
+		}
+		if !restartPolicies[svc.Restart] && !strings.HasPrefix(svc.Restart, "on-failure:") {
+			add("service %q: invalid restart policy %q", name, svc.Restart)
+		}
+		for _, port := range svc.Ports {
+			if !portRegex.MatchString(string(port)) {
+				add("service %q: invalid port mapping %q", name, port)
+			}
+		}
+		for _, mount := range svc.Volumes {
+			source, _, ok := strings.Cut(mount, ":")
+			if !ok {
+				add("service %q: volume %q has no target", name, mount)
+				continue
+			}
+			if isNamedVolume(source) {
+				if _, declared := c.Volumes[source]; !declared {
+					add("service %q: volume %q is not declared", name, source)
+				}
+			}
+		}
+		for _, env := range svc.Environment {
+			if key, _, _ := strings.Cut(env, "="); key == "" || strings.ContainsAny(key, " \t") {
+				add("service %q: invalid environment entry %q", name, env)
+			}
+		}
+		for _, cfg := range svc.Configs {
+			if _, declared := c.Configs[cfg.Source]; !declared {
+				add("service %q: config %q is not declared", name, cfg.Source)
+			}
+			if !strings.HasPrefix(cfg.Target, "/") {
+				add("service %q: config %q target must be an absolute path", name, cfg.Source)
+			}
+		}
+		for _, secret := range svc.Secrets {
+			if _, declared := c.Secrets[secret]; !declared {
+				add("service %q: secret %q is not declared", name, secret)
+			}
+		}
+		for _, network := range svc.Networks {
+			if _, declared := c.Networks[network]; !declared {
+				add("service %q: network %q is not declared", name, network)
+			}
+		}
+		for dep, d := range svc.DependsOn {
+			if _, ok := c.Services[dep]; !ok {
+				add("service %q: depends on unknown service %q", name, dep)
+			}
+			if !dependencyConditions[d.Condition] {
+				add("service %q: invalid depends_on condition %q", name, d.Condition)
+			}
+			if d.Condition == "service_healthy" && c.Services[dep] != nil && c.Services[dep].Healthcheck == nil {
+				add("service %q: waits for %q to be healthy but it has no healthcheck", name, dep)
+			}
+		}
+		if hc := svc.Healthcheck; hc != nil {
+			if len(hc.Test) == 0 {
+				add("service %q: healthcheck has no test", name)
+			}
+			for _, d := range []string{hc.Interval, hc.Timeout, hc.StartPeriod} {
+				if d != "" && !durationRegex.MatchString(d) {
+					add("service %q: invalid healthcheck duration %q", name, d)
+				}
+			}
+		}
+		if svc.Deploy != nil {
+			for _, spec := range []*ResourceSpec{svc.Deploy.Resources.Limits, svc.Deploy.Resources.Reservations} {
+				if spec == nil {
+					continue
+				}
+				for _, dev := range spec.Devices {
+					if len(dev.Capabilities) == 0 {
+						add("service %q: device request needs capabilities", name)
+					}
+				}
+			}
+		}
+	}
+
+	for name, cfg := range c.Configs {
+		if cfg == nil || (cfg.Content == "") == (cfg.File == "") {
+			add("config %q: exactly one of content or file is required", name)
+		}
+	}
+
+	for name, secret := range c.Secrets {
+		if secret == nil || secret.File == "" {
+			add("secret %q: file is required", name)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid compose file:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// isNamedVolume reports whether a volume source refers to a top-level
+// named volume rather than a host path.
+func isNamedVolume(source string) bool {
+	return source != "" && !strings.HasPrefix(source, "/") && !strings.HasPrefix(source, ".") && !strings.HasPrefix(source, "~")
+}
+
+// render marshals the file and round-trips the result through ParseCompose
+// so generators never hand out YAML that fails validation.
+func render(c *ComposeFile) (string, error) {
+	data, err := c.Marshal()
+	if err != nil {
+		return "", err
+	}
+	if _, err := ParseCompose(data); err != nil {
+		return "", fmt.Errorf("generated stack failed validation: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,86 @@
+package templates
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden from the current output")
+
+// assertGolden compares got with testdata/<name>.golden.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s (run go test -update if the change is intended)\n--- got ---\n%s", path, got)
+	}
+}
+
+func testStackConfig(gpuType string) StackConfig {
+	config := StackConfig{
+		StackName:  "llm-stack",
+		OllamaPort: 11434,
+		WebUIPort:  3000,
+		GPUType:    gpuType,
+	}
+	switch gpuType {
+	case "amd":
+		config.GFXTarget = "gfx1031"
+		config.HSAOverrideGFXVersion = "10.3.0"
+	case "cpu":
+		config.CPUSet = "0-15"
+	}
+	return config
+}
+
+func TestComposeGolden(t *testing.T) {
+	caddy := ProxyConfig{Kind: "caddy", Domain: "home.lan", TLS: "internal", ServePort: 8080, DirectPorts: "localhost"}
+	traefik := ProxyConfig{Kind: "traefik", Domain: "home.lan", TLS: "dns", DNSProvider: "cloudflare",
+		ACMEEmail: "admin@home.lan", DirectPorts: "none"}
+
+	tests := []struct {
+		name     string
+		generate func(StackConfig, ...Feature) (string, error)
+		config   StackConfig
+		features []Feature
+	}{
+		{"portainer-amd", GeneratePortainerStack, testStackConfig("amd"), nil},
+		{"portainer-amd-native", GeneratePortainerStack, StackConfig{StackName: "llm-stack", OllamaPort: 11434, WebUIPort: 3000, GPUType: "amd", GFXTarget: "gfx1030"}, nil},
+		{"portainer-nvidia", GeneratePortainerStack, testStackConfig("nvidia"), nil},
+		{"portainer-intel", GeneratePortainerStack, testStackConfig("intel"), nil},
+		{"portainer-cpu", GeneratePortainerStack, testStackConfig("cpu"), nil},
+		{"portainer-amd-env-secrets", GeneratePortainerStack, testStackConfig("amd"), []Feature{EnvSecrets}},
+		{"portainer-amd-docker-secrets-noauth", GeneratePortainerStack, testStackConfig("amd"), []Feature{DockerSecrets, NoAuth}},
+		{"portainer-amd-caddy", GeneratePortainerStack, testStackConfig("amd"), []Feature{EnvSecrets, ReverseProxy(caddy)}},
+		{"portainer-nvidia-traefik", GeneratePortainerStack, testStackConfig("nvidia"), []Feature{EnvSecrets, ReverseProxy(traefik)}},
+		{"reference-amd", GenerateDockerComposeForReference, testStackConfig("amd"), nil},
+		{"reference-cpu-env-secrets", GenerateDockerComposeForReference, testStackConfig("cpu"), []Feature{EnvSecrets}},
+		{"project-amd", GenerateComposeProject, testStackConfig("amd"), []Feature{EnvSecrets}},
+		{"project-intel-caddy", GenerateComposeProject, testStackConfig("intel"), []Feature{DockerSecrets, ReverseProxy(caddy)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.generate(tt.config, tt.features...)
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			assertGolden(t, tt.name, got)
+		})
+	}
+}
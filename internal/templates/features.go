@@ -0,0 +1,237 @@
+package templates
+
+import (
+	"fmt"
+)
+
+// Feature adjusts a stack built by BuildStack. Features are applied in
+// order, so later ones see (and may override) what earlier ones added.
+type Feature func(stack *ComposeFile, config StackConfig)
+
+const portainerLabel = "io.portainer.accesscontrol.teams=administrators"
+
+// BuildStack returns the base Ollama + Open WebUI stack with the GPU
+// feature for config.GPUType applied, followed by features.
+func BuildStack(config StackConfig, features ...Feature) *ComposeFile {
+	stack := &ComposeFile{
+		Version: "3.8",
+		Services: map[string]*Service{
+			"ollama": {
+				Image:         "ollama/ollama:latest",
+				ContainerName: config.StackName + "-ollama",
+				Restart:       "unless-stopped",
+				Ports:         []Port{Port(fmt.Sprintf("%d:11434", config.OllamaPort))},
+				Volumes:       []string{"ollama_data:/root/.ollama"},
+				Networks:      []string{"llm-network"},
+			},
+			"open-webui": {
+				Image:         "ghcr.io/open-webui/open-webui:main",
+				ContainerName: config.StackName + "-webui",
+				Restart:       "unless-stopped",
+				Ports:         []Port{Port(fmt.Sprintf("%d:8080", config.WebUIPort))},
+				Volumes:       []string{"open_webui_data:/app/backend/data"},
+				Environment: []string{
+					"OLLAMA_BASE_URL=http://ollama:11434",
+					"WEBUI_AUTH=true",
+				},
+				DependsOn: map[string]Dependency{"ollama": {Condition: "service_started"}},
+				Networks:  []string{"llm-network"},
+			},
+		},
+		Volumes: map[string]*Volume{
+			"ollama_data":     {Driver: "local"},
+			"open_webui_data": {Driver: "local"},
+		},
+		Networks: map[string]*Network{
+			"llm-network": {Driver: "bridge"},
+		},
+	}
+
+	GPU(config.GPUType)(stack, config)
+	for _, feature := range features {
+		feature(stack, config)
+	}
+	return stack
+}
+
+// GPU returns the feature that configures Ollama for a GPU type: "amd"
+// (the default), "nvidia", "intel" or "cpu".
+func GPU(gpuType string) Feature {
+	switch gpuType {
+	case "nvidia":
+		return nvidiaGPU
+	case "intel":
+		return intelGPU
+	case "cpu":
+		return cpuOnly
+	default:
+		return amdGPU
+	}
+}
+
+// rocmEnvironment returns the ROCm environment for the Ollama service.
+func rocmEnvironment(config StackConfig) []string {
+	var env []string
+	if config.HSAOverrideGFXVersion != "" {
+		env = append(env, "HSA_OVERRIDE_GFX_VERSION="+config.HSAOverrideGFXVersion)
+	}
+	if config.GFXTarget != "" {
+		env = append(env, "HCC_AMDGPU_TARGET="+config.GFXTarget)
+	}
+	return append(env, "ROCM_PATH=/opt/rocm", "HIP_VISIBLE_DEVICES=0")
+}
+
+// overrideTarget converts an HSA override version back to its gfx name,
+// e.g. "10.3.0" -> "gfx1030".
+func overrideTarget(version string) string {
+	var major, minor, stepping int
+	if _, err := fmt.Sscanf(version, "%d.%d.%d", &major, &minor, &stepping); err != nil {
+		return version
+	}
+	return fmt.Sprintf("gfx%d%x%x", major, minor, stepping)
+}
+
+func amdGPU(stack *ComposeFile, config StackConfig) {
+	ollama := stack.Services["ollama"]
+	ollama.Image = "ollama/ollama:rocm"
+	ollama.Devices = []string{"/dev/kfd", "/dev/dri"}
+	ollama.Environment = append(ollama.Environment, rocmEnvironment(config)...)
+
+	stack.Header = append(stack.Header, "This stack is optimized for AMD GPU acceleration with ROCm.")
+	if config.HSAOverrideGFXVersion != "" {
+		stack.Header = append(stack.Header, fmt.Sprintf("HSA_OVERRIDE_GFX_VERSION=%s: %s runs %s kernels.",
+			config.HSAOverrideGFXVersion, config.GFXTarget, overrideTarget(config.HSAOverrideGFXVersion)))
+	}
+	stack.Header = append(stack.Header,
+		"",
+		"Prerequisites:",
+		"1. ROCm drivers installed on the host (lite-llm setup rocm)",
+		"2. Docker with device access to /dev/kfd and /dev/dri",
+		"3. At least 8GB GPU memory and 16GB system RAM",
+	)
+}
+
+func nvidiaGPU(stack *ComposeFile, config StackConfig) {
+	ollama := stack.Services["ollama"]
+	ollama.Environment = append(ollama.Environment,
+		"NVIDIA_VISIBLE_DEVICES=all",
+		"NVIDIA_DRIVER_CAPABILITIES=compute,utility",
+	)
+	ollama.Deploy = &Deploy{Resources: Resources{
+		Reservations: &ResourceSpec{Devices: []DeviceRequest{
+			{Driver: "nvidia", Count: 1, Capabilities: []string{"gpu"}},
+		}},
+	}}
+
+	stack.Header = append(stack.Header,
+		"This stack is optimized for NVIDIA GPU acceleration with CUDA.",
+		"",
+		"Prerequisites:",
+		"1. NVIDIA driver and NVIDIA Container Toolkit installed on the host",
+		"2. At least 8GB GPU memory and 16GB system RAM",
+	)
+}
+
+// intelGPU runs IPEX-LLM's SYCL build of Ollama on Intel Arc and
+// integrated GPUs.
+func intelGPU(stack *ComposeFile, config StackConfig) {
+	ollama := stack.Services["ollama"]
+	ollama.Image = "intelanalytics/ipex-llm-inference-cpp-xpu:latest"
+	ollama.Devices = []string{"/dev/dri"}
+	ollama.ShmSize = "16g"
+	ollama.Command = []string{"sh", "-c", "mkdir -p /llm/ollama && cd /llm/ollama && init-ollama && exec ./ollama serve"}
+	ollama.Environment = append(ollama.Environment,
+		"OLLAMA_HOST=0.0.0.0:11434",
+		"OLLAMA_MODELS=/root/.ollama/models",
+		"OLLAMA_NUM_GPU=999",
+		"OLLAMA_INTEL_GPU=true",
+		"ZES_ENABLE_SYSMAN=1",
+		"SYCL_CACHE_PERSISTENT=1",
+		"SYCL_PI_LEVEL_ZERO_USE_IMMEDIATE_COMMANDLISTS=1",
+		"ONEAPI_DEVICE_SELECTOR=level_zero:0",
+		"DEVICE=Arc",
+		"no_proxy=localhost,127.0.0.1",
+	)
+
+	stack.Header = append(stack.Header,
+		"This stack runs IPEX-LLM's Ollama on an Intel Arc or integrated GPU.",
+		"",
+		"Prerequisites:",
+		"1. Intel compute runtime installed on the host (lite-llm setup intel)",
+		"2. Docker with device access to /dev/dri",
+	)
+}
+
+// cpuOnly runs one model and one request at a time so the weights and KV
+// cache fit in system RAM and all cores serve a single request.
+func cpuOnly(stack *ComposeFile, config StackConfig) {
+	ollama := stack.Services["ollama"]
+	ollama.CPUSet = config.CPUSet
+	ollama.Environment = append(ollama.Environment,
+		"OLLAMA_NUM_PARALLEL=1",
+		"OLLAMA_MAX_LOADED_MODELS=1",
+		"OLLAMA_KEEP_ALIVE=30m",
+	)
+
+	stack.Header = append(stack.Header,
+		"This stack runs Ollama on the CPU only; use small quantized models.",
+		"",
+		"Prerequisites:",
+		"1. A CPU with AVX (AVX2 or AVX-512 recommended)",
+		"2. At least 8GB system RAM",
+	)
+}
+
+// PortainerLabels restricts every service, volume and network to the
+// Portainer administrators team.
+func PortainerLabels(stack *ComposeFile, config StackConfig) {
+	for _, svc := range stack.Services {
+		svc.Labels = append(svc.Labels, portainerLabel)
+	}
+	for _, vol := range stack.Volumes {
+		vol.Labels = append(vol.Labels, portainerLabel)
+	}
+	for _, network := range stack.Networks {
+		network.Labels = append(network.Labels, portainerLabel)
+	}
+}
+
+// Healthchecks probes the Ollama API and holds Open WebUI back until it
+// responds.
+func Healthchecks(stack *ComposeFile, config StackConfig) {
+	startPeriod := "40s"
+	if config.GPUType == "intel" {
+		startPeriod = "60s" // init-ollama links the SYCL runtime on first start
+	}
+	stack.Services["ollama"].Healthcheck = &Healthcheck{
+		Test:        []string{"CMD", "curl", "-f", "http://localhost:11434/api/version"},
+		Interval:    "30s",
+		Timeout:     "10s",
+		Retries:     3,
+		StartPeriod: startPeriod,
+	}
+	for _, svc := range stack.Services {
+		if dep, ok := svc.DependsOn["ollama"]; ok {
+			dep.Condition = "service_healthy"
+			svc.DependsOn["ollama"] = dep
+		}
+	}
+}
+
+// BindMounts stores data in directories under dir instead of named
+// volumes, for users running the stack with the docker compose CLI.
+func BindMounts(dir string) Feature {
+	return func(stack *ComposeFile, config StackConfig) {
+		stack.Services["ollama"].Volumes = []string{dir + "/ollama:/root/.ollama"}
+		stack.Services["open-webui"].Volumes = []string{dir + "/webui:/app/backend/data"}
+		delete(stack.Volumes, "ollama_data")
+		delete(stack.Volumes, "open_webui_data")
+	}
+}
+
+// Notes appends free-form comment lines after the YAML.
+func Notes(lines ...string) Feature {
+	return func(stack *ComposeFile, config StackConfig) {
+		stack.Notes = append(stack.Notes, lines...)
+	}
+}
@@ -0,0 +1,426 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// chartFS holds the Helm chart templates. `stack generate --format k8s`
+// renders the same templates locally, so the plain manifests and the chart
+// never drift apart.
+//
+//go:embed chart/templates
+var chartFS embed.FS
+
+// KubernetesConfig holds the cluster-specific settings for the k8s and
+// helm formats.
+type KubernetesConfig struct {
+	Namespace string
+
+	// Domain enables an Ingress for chat.<domain> and ollama.<domain>.
+	Domain       string
+	IngressClass string // "" uses the cluster default (traefik on k3s)
+	TLSSecret    string // existing TLS secret for the Ingress, if any
+
+	StorageClass  string
+	OllamaStorage string
+	WebUIStorage  string
+
+	// NodeSelector and Tolerations apply to the Ollama pod and are added
+	// to the GPU type's default toleration.
+	NodeSelector map[string]string
+	Tolerations  []Toleration
+
+	// RuntimeClass overrides the Ollama pod's runtimeClassName. "none"
+	// clears the NVIDIA default.
+	RuntimeClass string
+}
+
+type Toleration struct {
+	Key      string `yaml:"key"`
+	Operator string `yaml:"operator"`
+	Value    string `yaml:"value,omitempty"`
+	Effect   string `yaml:"effect,omitempty"`
+}
+
+// ParseToleration parses key[=value][:effect]. Without a value the
+// operator is Exists.
+func ParseToleration(s string) (Toleration, error) {
+	spec, effect, _ := strings.Cut(s, ":")
+	key, value, hasValue := strings.Cut(spec, "=")
+	if key == "" {
+		return Toleration{}, fmt.Errorf("invalid toleration %q: expected key[=value][:effect]", s)
+	}
+	switch effect {
+	case "", "NoSchedule", "PreferNoSchedule", "NoExecute":
+	default:
+		return Toleration{}, fmt.Errorf("invalid toleration effect %q: must be NoSchedule, PreferNoSchedule or NoExecute", effect)
+	}
+
+	t := Toleration{Key: key, Operator: "Exists", Effect: effect}
+	if hasValue {
+		t.Operator = "Equal"
+		t.Value = value
+	}
+	return t, nil
+}
+
+// SecretName is the Kubernetes Secret holding the stack secrets, created
+// from the .env file with kubectl.
+func (k KubernetesConfig) SecretName(config StackConfig) string {
+	return config.StackName + "-secrets"
+}
+
+// gpuResources maps GPU types to their device plugin resource and the
+// taint GPU nodes commonly carry.
+var gpuResources = map[string]struct {
+	Resource string
+	Taint    string
+}{
+	"amd":    {Resource: "amd.com/gpu", Taint: "amd.com/gpu"},
+	"nvidia": {Resource: "nvidia.com/gpu", Taint: "nvidia.com/gpu"},
+	"intel":  {Resource: "gpu.intel.com/i915"},
+}
+
+// kubeEnvDrop lists compose variables that don't carry over: the device
+// plugin decides which GPU the pod sees, and the chart wires up the Ollama
+// URL and secrets itself.
+var kubeEnvDrop = map[string]bool{
+	"NVIDIA_VISIBLE_DEVICES": true,
+	"OLLAMA_BASE_URL":        true,
+}
+
+type kubeEnv struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type kubeStorage struct {
+	Size         string `yaml:"size"`
+	StorageClass string `yaml:"storageClass"`
+}
+
+// kubeValues is the chart's values.yaml. Fields have no omitempty so the
+// templates can reference every key.
+type kubeValues struct {
+	Ollama struct {
+		Image            string            `yaml:"image"`
+		Command          []string          `yaml:"command"`
+		Env              []kubeEnv         `yaml:"env"`
+		GPU              kubeGPU           `yaml:"gpu"`
+		RuntimeClassName string            `yaml:"runtimeClassName"`
+		ShmSize          string            `yaml:"shmSize"`
+		Storage          kubeStorage       `yaml:"storage"`
+		NodeSelector     map[string]string `yaml:"nodeSelector"`
+		Tolerations      []Toleration      `yaml:"tolerations"`
+	} `yaml:"ollama"`
+	WebUI struct {
+		Image        string            `yaml:"image"`
+		Env          []kubeEnv         `yaml:"env"`
+		SecretName   string            `yaml:"secretName"`
+		SecretKeys   []string          `yaml:"secretKeys"`
+		Storage      kubeStorage       `yaml:"storage"`
+		NodeSelector map[string]string `yaml:"nodeSelector"`
+	} `yaml:"webui"`
+	Ingress struct {
+		Enabled   bool   `yaml:"enabled"`
+		ClassName string `yaml:"className"`
+		TLSSecret string `yaml:"tlsSecret"`
+		Hosts     struct {
+			Chat   string `yaml:"chat"`
+			Ollama string `yaml:"ollama"`
+		} `yaml:"hosts"`
+	} `yaml:"ingress"`
+}
+
+type kubeGPU struct {
+	Resource string `yaml:"resource"`
+	Count    int    `yaml:"count"`
+}
+
+// kubernetesValues builds the chart values from the compose stack, so GPU
+// features and options like NoAuth carry over unchanged.
+func kubernetesValues(config StackConfig, kube KubernetesConfig, features ...Feature) kubeValues {
+	stack := BuildStack(config, features...)
+	ollama := stack.Services["ollama"]
+	webui := stack.Services["open-webui"]
+
+	var v kubeValues
+	v.Ollama.Image = ollama.Image
+	v.Ollama.Command = ollama.Command
+	v.Ollama.Env = kubeEnvFrom(ollama.Environment)
+	v.Ollama.ShmSize = kubeQuantity(ollama.ShmSize)
+	v.Ollama.Storage = kubeStorage{Size: kube.OllamaStorage, StorageClass: kube.StorageClass}
+	v.Ollama.NodeSelector = kube.NodeSelector
+
+	if gpu, ok := gpuResources[config.GPUType]; ok {
+		v.Ollama.GPU = kubeGPU{Resource: gpu.Resource, Count: 1}
+		if gpu.Taint != "" {
+			v.Ollama.Tolerations = append(v.Ollama.Tolerations, Toleration{Key: gpu.Taint, Operator: "Exists", Effect: "NoSchedule"})
+		}
+	}
+	v.Ollama.Tolerations = append(v.Ollama.Tolerations, kube.Tolerations...)
+
+	// k3s and the GPU Operator both register an "nvidia" RuntimeClass.
+	if config.GPUType == "nvidia" {
+		v.Ollama.RuntimeClassName = "nvidia"
+	}
+	if kube.RuntimeClass != "" {
+		v.Ollama.RuntimeClassName = kube.RuntimeClass
+	}
+	if v.Ollama.RuntimeClassName == "none" {
+		v.Ollama.RuntimeClassName = ""
+	}
+
+	v.WebUI.Image = webui.Image
+	v.WebUI.Env = kubeEnvFrom(webui.Environment)
+	v.WebUI.SecretName = kube.SecretName(config)
+	v.WebUI.SecretKeys = StackSecrets
+	v.WebUI.Storage = kubeStorage{Size: kube.WebUIStorage, StorageClass: kube.StorageClass}
+
+	if kube.Domain != "" {
+		v.Ingress.Enabled = true
+		v.Ingress.ClassName = kube.IngressClass
+		v.Ingress.TLSSecret = kube.TLSSecret
+		v.Ingress.Hosts.Chat = "chat." + kube.Domain
+		v.Ingress.Hosts.Ollama = "ollama." + kube.Domain
+		v.WebUI.Env = append(v.WebUI.Env, kubeEnv{Name: "WEBUI_URL", Value: kube.webUIURL()})
+	}
+
+	return v
+}
+
+func (k KubernetesConfig) webUIURL() string {
+	scheme := "http"
+	if k.TLSSecret != "" {
+		scheme = "https"
+	}
+	return scheme + "://chat." + k.Domain
+}
+
+func kubeEnvFrom(environment []string) []kubeEnv {
+	var env []kubeEnv
+	for _, entry := range environment {
+		name, value, _ := strings.Cut(entry, "=")
+		if kubeEnvDrop[name] || isStackSecret(name) {
+			continue
+		}
+		env = append(env, kubeEnv{Name: name, Value: value})
+	}
+	return env
+}
+
+func isStackSecret(name string) bool {
+	for _, secret := range StackSecrets {
+		if name == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// kubeQuantity converts a Docker size such as "16g" to a Kubernetes
+// quantity ("16Gi").
+func kubeQuantity(size string) string {
+	if size == "" {
+		return ""
+	}
+	switch suffix := strings.ToLower(size[len(size)-1:]); suffix {
+	case "k", "m", "g":
+		return size[:len(size)-1] + strings.ToUpper(suffix) + "i"
+	}
+	return size
+}
+
+// GenerateKubernetes renders Ollama and Open WebUI manifests for kubectl
+// apply: a StatefulSet for Ollama, a Deployment and PVC for Open WebUI,
+// their Services and an optional Ingress.
+func GenerateKubernetes(config StackConfig, kube KubernetesConfig, features ...Feature) (string, error) {
+	values := kubernetesValues(config, kube, features...)
+
+	docs, err := renderChart(values, config.StackName, kube.Namespace)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "# Kubernetes manifests for %s\n", config.StackName)
+	out.WriteString("#\n# Create the Open WebUI secret first:\n")
+	if kube.Namespace != "" {
+		fmt.Fprintf(&out, "#   kubectl create namespace %s\n", kube.Namespace)
+	}
+	fmt.Fprintf(&out, "#   kubectl create secret generic %s%s --from-env-file=.env\n",
+		values.WebUI.SecretName, namespaceFlag(kube.Namespace))
+	out.WriteString("# Then apply:\n#   kubectl apply -f <this file>\n")
+	for _, doc := range docs {
+		out.WriteString("---\n")
+		out.WriteString(doc)
+	}
+	return out.String(), nil
+}
+
+func namespaceFlag(namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+	return " -n " + namespace
+}
+
+// GenerateHelmChart returns the files of a Helm chart for the stack,
+// keyed by path relative to the chart directory.
+func GenerateHelmChart(config StackConfig, kube KubernetesConfig, features ...Feature) (map[string]string, error) {
+	values := kubernetesValues(config, kube, features...)
+
+	// Render once so a template error surfaces here rather than in helm.
+	if _, err := renderChart(values, config.StackName, "default"); err != nil {
+		return nil, err
+	}
+
+	chart, err := yaml.Marshal(struct {
+		APIVersion  string `yaml:"apiVersion"`
+		Name        string `yaml:"name"`
+		Description string `yaml:"description"`
+		Type        string `yaml:"type"`
+		Version     string `yaml:"version"`
+		AppVersion  string `yaml:"appVersion"`
+	}{"v2", config.StackName, "Ollama and Open WebUI generated by lite-llm", "application", "0.1.0", "latest"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Chart.yaml: %w", err)
+	}
+
+	valuesYAML, err := marshalValues(values)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{
+		"Chart.yaml":  string(chart),
+		"values.yaml": "# Generated by lite-llm stack generate --format helm\n" + valuesYAML,
+	}
+	err = fs.WalkDir(chartFS, "chart", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := chartFS.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[strings.TrimPrefix(p, "chart/")] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart templates: %w", err)
+	}
+	return files, nil
+}
+
+func marshalValues(values kubeValues) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(values); err != nil {
+		return "", fmt.Errorf("failed to marshal values: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to marshal values: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// chartFuncs implements the subset of Helm's template functions the chart
+// uses.
+var chartFuncs = template.FuncMap{
+	"toYaml": func(v interface{}) (string, error) {
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(string(data), "\n"), nil
+	},
+	"nindent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		return "\n" + pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+	},
+	"quote": func(s interface{}) string {
+		return fmt.Sprintf("%q", fmt.Sprint(s))
+	},
+}
+
+// renderChart executes the chart templates the way `helm template` would,
+// with values round-tripped through YAML so templates see the same keys
+// as in values.yaml, and checks that every document is a named object.
+func renderChart(values kubeValues, release, namespace string) ([]string, error) {
+	valuesYAML, err := marshalValues(values)
+	if err != nil {
+		return nil, err
+	}
+	var valuesMap map[string]interface{}
+	if err := yaml.Unmarshal([]byte(valuesYAML), &valuesMap); err != nil {
+		return nil, fmt.Errorf("failed to decode values: %w", err)
+	}
+	data := map[string]interface{}{
+		"Values":  valuesMap,
+		"Release": map[string]interface{}{"Name": release, "Namespace": namespace},
+	}
+
+	paths, err := fs.Glob(chartFS, "chart/templates/*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var docs []string
+	for _, p := range paths {
+		src, err := chartFS.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(path.Base(p)).Funcs(chartFuncs).Option("missingkey=error").Parse(string(src))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", p, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", p, err)
+		}
+
+		for _, doc := range strings.Split(buf.String(), "\n---\n") {
+			doc = strings.TrimSpace(doc)
+			if doc == "" {
+				continue
+			}
+			if err := validateManifest(doc); err != nil {
+				return nil, fmt.Errorf("%s: %w", path.Base(p), err)
+			}
+			docs = append(docs, doc+"\n")
+		}
+	}
+	return docs, nil
+}
+
+// validateManifest checks a rendered document is well-formed YAML with an
+// apiVersion, kind and metadata.name.
+func validateManifest(doc string) error {
+	var obj struct {
+		APIVersion string `yaml:"apiVersion"`
+		Kind       string `yaml:"kind"`
+		Metadata   struct {
+			Name string `yaml:"name"`
+		} `yaml:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+		return fmt.Errorf("invalid manifest: %w", err)
+	}
+	if obj.APIVersion == "" || obj.Kind == "" || obj.Metadata.Name == "" {
+		return fmt.Errorf("invalid manifest: apiVersion, kind and metadata.name are required")
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProxyConfig configures the optional reverse proxy service.
+type ProxyConfig struct {
+	Kind   string // "caddy" or "traefik"
+	Domain string // e.g. "home.lan"; services get ollama.<domain>, chat.<domain> and llm.<domain>
+
+	// TLS is "internal" (Caddy's local CA, Traefik's self-signed default
+	// certificate) or "dns" (ACME DNS-01 through DNSProvider).
+	TLS         string
+	DNSProvider string // lego/caddy-dns provider name, e.g. "cloudflare"
+	ACMEEmail   string
+
+	// ServePort is the host port of `lite-llm serve`, proxied through the
+	// Docker host gateway. 0 leaves the serve UI out.
+	ServePort int
+
+	// DirectPorts is what happens to the services' own port bindings:
+	// "localhost" keeps them on 127.0.0.1 only, "none" removes them.
+	DirectPorts string
+
+	// Image overrides the proxy image, e.g. a Caddy build with a DNS module.
+	Image string
+}
+
+// proxyRoute is a hostname routed to an upstream.
+type proxyRoute struct {
+	Host     string
+	Upstream string // host:port as seen from the proxy container
+}
+
+func (p ProxyConfig) routes() []proxyRoute {
+	routes := []proxyRoute{
+		{Host: "chat." + p.Domain, Upstream: "open-webui:8080"},
+		{Host: "ollama." + p.Domain, Upstream: "ollama:11434"},
+	}
+	if p.ServePort > 0 {
+		routes = append(routes, proxyRoute{Host: "llm." + p.Domain, Upstream: fmt.Sprintf("host.docker.internal:%d", p.ServePort)})
+	}
+	return routes
+}
+
+// dnsCredentialEnv returns the variable the Caddyfile reads the DNS
+// provider's API token from, e.g. CLOUDFLARE_API_TOKEN.
+func (p ProxyConfig) dnsCredentialEnv() string {
+	return strings.ToUpper(strings.ReplaceAll(p.DNSProvider, "-", "_")) + "_API_TOKEN"
+}
+
+// legoCredentialEnv lists the variables Traefik's ACME library (lego)
+// reads for common DNS providers.
+var legoCredentialEnv = map[string][]string{
+	"cloudflare":   {"CF_DNS_API_TOKEN"},
+	"digitalocean": {"DO_AUTH_TOKEN"},
+	"duckdns":      {"DUCKDNS_TOKEN"},
+	"hetzner":      {"HETZNER_API_KEY"},
+	"route53":      {"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_REGION"},
+	"ovh":          {"OVH_ENDPOINT", "OVH_APPLICATION_KEY", "OVH_APPLICATION_SECRET", "OVH_CONSUMER_KEY"},
+}
+
+// credentialEnv returns the DNS credential variables the proxy container
+// needs, passed through from the stack environment.
+func (p ProxyConfig) credentialEnv() []string {
+	names := []string{p.dnsCredentialEnv()}
+	if p.Kind == "traefik" {
+		if lego, ok := legoCredentialEnv[p.DNSProvider]; ok {
+			names = lego
+		}
+	}
+
+	env := make([]string, len(names))
+	for i, name := range names {
+		env[i] = fmt.Sprintf("%s=${%s}", name, name)
+	}
+	return env
+}
+
+// Validate reports configuration combinations that cannot work.
+func (p ProxyConfig) Validate() error {
+	switch p.Kind {
+	case "caddy", "traefik":
+	default:
+		return fmt.Errorf("invalid proxy: %s. Must be 'caddy' or 'traefik'", p.Kind)
+	}
+	if p.Domain == "" {
+		return fmt.Errorf("a domain is required for hostname routing")
+	}
+	switch p.TLS {
+	case "internal":
+	case "dns":
+		if p.DNSProvider == "" {
+			return fmt.Errorf("--dns-provider is required for DNS-01 certificates")
+		}
+		if p.ACMEEmail == "" {
+			return fmt.Errorf("--acme-email is required for DNS-01 certificates")
+		}
+	default:
+		return fmt.Errorf("invalid TLS mode: %s. Must be 'internal' or 'dns'", p.TLS)
+	}
+	switch p.DirectPorts {
+	case "localhost", "none":
+	default:
+		return fmt.Errorf("invalid direct port mode: %s. Must be 'localhost' or 'none'", p.DirectPorts)
+	}
+	return nil
+}
+
+// ReverseProxy adds a Caddy or Traefik service terminating TLS on 443 and
+// routing by hostname to Open WebUI, Ollama and the lite-llm serve UI.
+// The services' own ports are bound to 127.0.0.1 or removed so plain HTTP
+// is no longer reachable from the LAN.
+func ReverseProxy(proxy ProxyConfig) Feature {
+	return func(stack *ComposeFile, config StackConfig) {
+		for _, name := range []string{"ollama", "open-webui"} {
+			svc := stack.Services[name]
+			if proxy.DirectPorts == "none" {
+				svc.Ports = nil
+				continue
+			}
+			for i, port := range svc.Ports {
+				svc.Ports[i] = "127.0.0.1:" + port
+			}
+		}
+
+		if webui := stack.Services["open-webui"]; webui != nil {
+			webui.Environment = append(webui.Environment, "WEBUI_URL=https://chat."+proxy.Domain)
+		}
+
+		if proxy.Kind == "traefik" {
+			traefikProxy(stack, config, proxy)
+		} else {
+			caddyProxy(stack, config, proxy)
+		}
+
+		stack.Header = append(stack.Header, "", "Reverse proxy ("+proxy.Kind+"):")
+		for _, route := range proxy.routes() {
+			stack.Header = append(stack.Header, fmt.Sprintf("  https://%s -> %s", route.Host, route.Upstream))
+		}
+		stack.Header = append(stack.Header, "Point these names at this host in your LAN DNS (or /etc/hosts).")
+		switch {
+		case proxy.TLS == "dns":
+			stack.Header = append(stack.Header,
+				fmt.Sprintf("Certificates are issued by Let's Encrypt via %s DNS-01. Set these in the stack environment:", proxy.DNSProvider))
+			for _, env := range proxy.credentialEnv() {
+				name, _, _ := strings.Cut(env, "=")
+				stack.Header = append(stack.Header, "  "+name)
+			}
+		case proxy.Kind == "caddy":
+			stack.Header = append(stack.Header,
+				"Certificates come from Caddy's internal CA. Trust it on clients with:",
+				fmt.Sprintf("  docker cp %s-proxy:/data/caddy/pki/authorities/local/root.crt .", config.StackName))
+		default:
+			stack.Header = append(stack.Header, "Traefik serves a self-signed certificate; clients will warn until you trust it.")
+		}
+	}
+}
+
+func caddyProxy(stack *ComposeFile, config StackConfig, proxy ProxyConfig) {
+	var caddyfile strings.Builder
+	if proxy.ACMEEmail != "" {
+		fmt.Fprintf(&caddyfile, "{\n\temail %s\n}\n\n", proxy.ACMEEmail)
+	}
+
+	for i, route := range proxy.routes() {
+		if i > 0 {
+			caddyfile.WriteString("\n")
+		}
+		fmt.Fprintf(&caddyfile, "%s {\n", route.Host)
+		if proxy.TLS == "dns" {
+			fmt.Fprintf(&caddyfile, "\ttls {\n\t\tdns %s {env.%s}\n\t}\n", proxy.DNSProvider, proxy.dnsCredentialEnv())
+		} else {
+			caddyfile.WriteString("\ttls internal\n")
+		}
+		fmt.Fprintf(&caddyfile, "\treverse_proxy %s\n}\n", route.Upstream)
+	}
+
+	image := proxy.Image
+	if image == "" {
+		image = "caddy:2"
+	}
+
+	svc := &Service{
+		Image:         image,
+		ContainerName: config.StackName + "-proxy",
+		Restart:       "unless-stopped",
+		Ports:         []Port{"80:80", "443:443"},
+		Volumes:       []string{"caddy_data:/data", "caddy_config:/config"},
+		Configs:       []ServiceConfig{{Source: "caddyfile", Target: "/etc/caddy/Caddyfile"}},
+		DependsOn:     proxyDependencies(stack),
+		Networks:      []string{"llm-network"},
+	}
+	if proxy.TLS == "dns" {
+		svc.Environment = proxy.credentialEnv()
+		if proxy.Image == "" {
+			stack.Header = append(stack.Header, "",
+				fmt.Sprintf("NOTE: the stock caddy image has no DNS modules. Build one with caddy-dns/%s", proxy.DNSProvider),
+				"(xcaddy) and pass it with --proxy-image.")
+		}
+	}
+	if proxy.ServePort > 0 {
+		svc.ExtraHosts = []string{"host.docker.internal:host-gateway"}
+	}
+
+	stack.Services["proxy"] = svc
+	stack.Volumes["caddy_data"] = &Volume{Driver: "local"}
+	stack.Volumes["caddy_config"] = &Volume{Driver: "local"}
+	if stack.Configs == nil {
+		stack.Configs = make(map[string]*Config)
+	}
+	stack.Configs["caddyfile"] = &Config{Content: caddyfile.String()}
+}
+
+func traefikProxy(stack *ComposeFile, config StackConfig, proxy ProxyConfig) {
+	image := proxy.Image
+	if image == "" {
+		image = "traefik:v3.1"
+	}
+
+	command := []string{
+		"--providers.file.filename=/etc/traefik/dynamic.yml",
+		"--entrypoints.web.address=:80",
+		"--entrypoints.web.http.redirections.entrypoint.to=websecure",
+		"--entrypoints.web.http.redirections.entrypoint.scheme=https",
+		"--entrypoints.websecure.address=:443",
+	}
+	if proxy.TLS == "dns" {
+		command = append(command,
+			"--certificatesresolvers.le.acme.email="+proxy.ACMEEmail,
+			"--certificatesresolvers.le.acme.storage=/certs/acme.json",
+			"--certificatesresolvers.le.acme.dnschallenge.provider="+proxy.DNSProvider,
+		)
+	}
+
+	// Routes live in a file provider rather than container labels so the
+	// proxy doesn't need the Docker socket and can reach the host's serve UI.
+	var dynamic strings.Builder
+	dynamic.WriteString("http:\n  routers:\n")
+	for i, route := range proxy.routes() {
+		fmt.Fprintf(&dynamic, "    route%d:\n      rule: \"Host(`%s`)\"\n      entryPoints: [websecure]\n      service: route%d\n", i, route.Host, i)
+		if proxy.TLS == "dns" {
+			dynamic.WriteString("      tls:\n        certResolver: le\n")
+		} else {
+			dynamic.WriteString("      tls: {}\n")
+		}
+	}
+	dynamic.WriteString("  services:\n")
+	for i, route := range proxy.routes() {
+		fmt.Fprintf(&dynamic, "    route%d:\n      loadBalancer:\n        servers:\n          - url: \"http://%s\"\n", i, route.Upstream)
+	}
+
+	svc := &Service{
+		Image:         image,
+		ContainerName: config.StackName + "-proxy",
+		Restart:       "unless-stopped",
+		Command:       command,
+		Ports:         []Port{"80:80", "443:443"},
+		Volumes:       []string{"traefik_certs:/certs"},
+		Configs:       []ServiceConfig{{Source: "traefik-dynamic", Target: "/etc/traefik/dynamic.yml"}},
+		DependsOn:     proxyDependencies(stack),
+		Networks:      []string{"llm-network"},
+	}
+	if proxy.TLS == "dns" {
+		svc.Environment = proxy.credentialEnv()
+	}
+	if proxy.ServePort > 0 {
+		svc.ExtraHosts = []string{"host.docker.internal:host-gateway"}
+	}
+
+	stack.Services["proxy"] = svc
+	stack.Volumes["traefik_certs"] = &Volume{Driver: "local"}
+	if stack.Configs == nil {
+		stack.Configs = make(map[string]*Config)
+	}
+	stack.Configs["traefik-dynamic"] = &Config{Content: dynamic.String()}
+}
+
+func proxyDependencies(stack *ComposeFile) map[string]Dependency {
+	deps := make(map[string]Dependency)
+	for _, name := range []string{"ollama", "open-webui"} {
+		if _, ok := stack.Services[name]; ok {
+			deps[name] = Dependency{Condition: "service_started"}
+		}
+	}
+	return deps
+}
@@ -0,0 +1,299 @@
+package templates
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// QuadletConfig holds the Podman-specific settings for the quadlet format.
+type QuadletConfig struct {
+	// Rootful units go in /etc/containers/systemd and run as root;
+	// otherwise they go in ~/.config/containers/systemd for the user.
+	Rootful bool
+}
+
+// UnitDir is where Podman's systemd generator looks for the units.
+func (q QuadletConfig) UnitDir() string {
+	if q.Rootful {
+		return "/etc/containers/systemd"
+	}
+	return "~/.config/containers/systemd"
+}
+
+// PodmanSecretName is the Podman secret holding a stack secret, e.g.
+// llm-stack-webui_secret_key.
+func PodmanSecretName(config StackConfig, name string) string {
+	return config.StackName + "-" + SecretFileName(name)
+}
+
+// unitFile is a systemd-style INI file. Sections keep their insertion
+// order; keys may repeat.
+type unitFile struct {
+	comments []string
+	sections []unitSection
+}
+
+type unitSection struct {
+	name    string
+	entries [][2]string
+}
+
+func (u *unitFile) add(section, key, value string) {
+	for i := range u.sections {
+		if u.sections[i].name == section {
+			u.sections[i].entries = append(u.sections[i].entries, [2]string{key, value})
+			return
+		}
+	}
+	u.sections = append(u.sections, unitSection{name: section, entries: [][2]string{{key, value}}})
+}
+
+func (u *unitFile) String() string {
+	var b strings.Builder
+	for _, line := range u.comments {
+		b.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+	}
+	for i, section := range u.sections {
+		if i > 0 || len(u.comments) > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[%s]\n", section.name)
+		for _, entry := range section.entries {
+			fmt.Fprintf(&b, "%s=%s\n", entry[0], entry[1])
+		}
+	}
+	return b.String()
+}
+
+// systemdQuote quotes a word for Exec= and Environment= lines.
+func systemdQuote(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\"'\\$;") {
+		return word
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(word) + `"`
+}
+
+// qualifiedImage prefixes Docker Hub images with docker.io, which Podman
+// auto-update requires.
+func qualifiedImage(image string) string {
+	first, _, hasSlash := strings.Cut(image, "/")
+	if hasSlash && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return image
+	}
+	if !hasSlash {
+		return "docker.io/library/" + image
+	}
+	return "docker.io/" + image
+}
+
+// GenerateQuadlet renders Podman Quadlet units for the stack, keyed by
+// file name: a .network, a .volume per named volume and a .container per
+// service. Images are labelled for `podman auto-update`.
+func GenerateQuadlet(config StackConfig, quadlet QuadletConfig, features ...Feature) (map[string]string, error) {
+	stack := BuildStack(config, append([]Feature{Healthchecks}, features...)...)
+	if len(stack.Secrets) > 0 {
+		return nil, fmt.Errorf("docker secrets are not supported by Quadlet; stack secrets are Podman secrets")
+	}
+
+	files := make(map[string]string)
+	networkUnit := config.StackName + ".network"
+
+	network := &unitFile{comments: []string{"Generated by lite-llm stack generate --format quadlet"}}
+	network.add("Unit", "Description", config.StackName+" network")
+	network.add("Network", "NetworkName", config.StackName)
+	files[networkUnit] = network.String()
+
+	volumeUnits := make(map[string]string)
+	for name := range stack.Volumes {
+		unitName := config.StackName + "-" + strings.ReplaceAll(name, "_", "-")
+		volume := &unitFile{comments: []string{"Generated by lite-llm stack generate --format quadlet"}}
+		volume.add("Unit", "Description", config.StackName+" "+name+" volume")
+		volume.add("Volume", "VolumeName", config.StackName+"_"+name)
+		files[unitName+".volume"] = volume.String()
+		volumeUnits[name] = unitName + ".volume"
+	}
+
+	names := make([]string, 0, len(stack.Services))
+	for name := range stack.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		svc := stack.Services[name]
+		unit := &unitFile{comments: []string{"Generated by lite-llm stack generate --format quadlet"}}
+		unit.comments = append(unit.comments, quadletComments(config, name)...)
+
+		unit.add("Unit", "Description", fmt.Sprintf("%s %s", config.StackName, name))
+		for dep := range svc.DependsOn {
+			depUnit := stack.Services[dep].ContainerName + ".service"
+			unit.add("Unit", "Requires", depUnit)
+			unit.add("Unit", "After", depUnit)
+		}
+
+		unit.add("Container", "Image", qualifiedImage(svc.Image))
+		unit.add("Container", "ContainerName", svc.ContainerName)
+		unit.add("Container", "AutoUpdate", "registry")
+		unit.add("Container", "Network", networkUnit)
+		for _, port := range svc.Ports {
+			unit.add("Container", "PublishPort", string(port))
+		}
+		for _, mount := range svc.Volumes {
+			source, target, _ := strings.Cut(mount, ":")
+			if volumeUnit, ok := volumeUnits[source]; ok {
+				source = volumeUnit
+			}
+			// :Z gives the volume a private SELinux label for this container.
+			unit.add("Container", "Volume", source+":"+target+":Z")
+		}
+
+		gpuDevices := len(svc.Devices) > 0 || svc.Deploy != nil
+		for _, device := range svc.Devices {
+			unit.add("Container", "AddDevice", device)
+		}
+		if svc.Deploy != nil {
+			// NVIDIA GPUs are passed through with CDI (nvidia-ctk cdi generate).
+			unit.add("Container", "AddDevice", "nvidia.com/gpu=all")
+		}
+		if gpuDevices {
+			// container-selinux denies GPU device access to confined
+			// containers; see the header for a narrower alternative.
+			unit.add("Container", "SecurityLabelDisable", "true")
+			if !quadlet.Rootful && len(svc.Devices) > 0 {
+				// Keep the user's video/render groups for /dev/kfd and /dev/dri.
+				unit.add("Container", "GroupAdd", "keep-groups")
+			}
+		}
+		if svc.ShmSize != "" {
+			unit.add("Container", "ShmSize", svc.ShmSize)
+		}
+		if svc.CPUSet != "" {
+			unit.add("Container", "PodmanArgs", "--cpuset-cpus="+svc.CPUSet)
+		}
+
+		for _, env := range svc.Environment {
+			key, value, _ := strings.Cut(env, "=")
+			if isStackSecret(key) {
+				continue
+			}
+			if key == "OLLAMA_BASE_URL" {
+				// Podman resolves containers by name, not compose service.
+				value = fmt.Sprintf("http://%s:11434", stack.Services["ollama"].ContainerName)
+			}
+			unit.add("Container", "Environment", systemdQuote(key+"="+value))
+		}
+		if name == "open-webui" {
+			for _, secret := range StackSecrets {
+				unit.add("Container", "Secret", fmt.Sprintf("%s,type=env,target=%s", PodmanSecretName(config, secret), secret))
+			}
+		}
+		for _, label := range svc.Labels {
+			unit.add("Container", "Label", systemdQuote(label))
+		}
+
+		if hc := svc.Healthcheck; hc != nil && len(hc.Test) > 1 {
+			unit.add("Container", "HealthCmd", quadletHealthCmd(hc.Test))
+			unit.add("Container", "HealthInterval", hc.Interval)
+			unit.add("Container", "HealthTimeout", hc.Timeout)
+			unit.add("Container", "HealthRetries", fmt.Sprint(hc.Retries))
+			unit.add("Container", "HealthStartPeriod", hc.StartPeriod)
+		}
+
+		if len(svc.Command) > 0 {
+			words := make([]string, len(svc.Command))
+			for i, word := range svc.Command {
+				// Compose's $$ escape is also systemd's.
+				words[i] = systemdQuote(word)
+			}
+			unit.add("Container", "Exec", strings.Join(words, " "))
+		}
+
+		unit.add("Service", "Restart", "always")
+		// First start pulls multi-gigabyte images.
+		unit.add("Service", "TimeoutStartSec", "900")
+		unit.add("Install", "WantedBy", quadlet.wantedBy())
+
+		files[svc.ContainerName+".container"] = unit.String()
+	}
+
+	return files, nil
+}
+
+func (q QuadletConfig) wantedBy() string {
+	if q.Rootful {
+		return "multi-user.target"
+	}
+	return "default.target"
+}
+
+// quadletHealthCmd converts a compose healthcheck test to HealthCmd.
+func quadletHealthCmd(test []string) string {
+	switch test[0] {
+	case "CMD-SHELL":
+		return test[1]
+	case "CMD":
+		test = test[1:]
+	}
+	words := make([]string, len(test))
+	for i, word := range test {
+		words[i] = systemdQuote(word)
+	}
+	return strings.Join(words, " ")
+}
+
+func quadletComments(config StackConfig, service string) []string {
+	if service != "ollama" {
+		return nil
+	}
+	switch config.GPUType {
+	case "amd", "intel", "":
+		return []string{"",
+			"GPU access disables SELinux confinement for this container. To keep it",
+			"confined instead, remove SecurityLabelDisable and run:",
+			"  sudo setsebool -P container_use_devices=true"}
+	case "nvidia":
+		return []string{"",
+			"Requires the NVIDIA Container Toolkit CDI spec:",
+			"  sudo nvidia-ctk cdi generate --output=/etc/cdi/nvidia.yaml"}
+	}
+	return nil
+}
+
+// SystemdConfig describes the `lite-llm serve` unit.
+type SystemdConfig struct {
+	Binary    string // absolute path to lite-llm
+	WorkDir   string // absolute path holding web/, which serve loads its pages from
+	ServePort int
+	Rootful   bool
+}
+
+// GenerateServeUnit renders a systemd service running `lite-llm serve`
+// against the stack's Ollama port, ordered after the Quadlet Ollama
+// container when both run in the same systemd instance.
+func GenerateServeUnit(config StackConfig, systemd SystemdConfig) string {
+	ollamaUnit := config.StackName + "-ollama.service"
+
+	unit := &unitFile{comments: []string{"Generated by lite-llm stack generate --format systemd"}}
+	unit.add("Unit", "Description", fmt.Sprintf("Lite LLM web interface (%s)", config.StackName))
+	unit.add("Unit", "Wants", "network-online.target "+ollamaUnit)
+	unit.add("Unit", "After", "network-online.target "+ollamaUnit)
+
+	unit.add("Service", "Type", "simple")
+	// serve reads web/templates relative to its working directory, and
+	// systemd would otherwise start it in /.
+	unit.add("Service", "WorkingDirectory", systemd.WorkDir)
+	unit.add("Service", "ExecStart", strings.Join([]string{
+		systemdQuote(systemd.Binary), "serve",
+		"--port", fmt.Sprint(systemd.ServePort),
+		"--ollama-url", fmt.Sprintf("http://localhost:%d", config.OllamaPort),
+	}, " "))
+	unit.add("Service", "Restart", "on-failure")
+	unit.add("Service", "RestartSec", "5s")
+	unit.add("Service", "NoNewPrivileges", "true")
+	unit.add("Service", "PrivateTmp", "true")
+
+	unit.add("Install", "WantedBy", QuadletConfig{Rootful: systemd.Rootful}.wantedBy())
+	return unit.String()
+}
@@ -0,0 +1,20 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateServeUnit(t *testing.T) {
+	unit := GenerateServeUnit(testStackConfig("amd"), SystemdConfig{
+		Binary:    "/opt/lite-llm/lite-llm",
+		WorkDir:   "/opt/lite-llm",
+		ServePort: 8080,
+	})
+	assertGolden(t, "systemd-serve", unit)
+
+	// serve finds web/templates relative to its working directory.
+	if !strings.Contains(unit, "\nWorkingDirectory=/opt/lite-llm\n") {
+		t.Errorf("unit has no WorkingDirectory for web/:\n%s", unit)
+	}
+}
@@ -0,0 +1,72 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StackSecrets are the variables generated per stack and kept out of the
+// compose file.
+var StackSecrets = []string{"WEBUI_SECRET_KEY"}
+
+// SecretsDir is where DockerSecrets expects the secret files, relative to
+// the compose file.
+const SecretsDir = "secrets"
+
+// SecretFileName returns the Docker secret name and file name for a stack
+// secret, e.g. WEBUI_SECRET_KEY -> webui_secret_key.
+func SecretFileName(name string) string {
+	return strings.ToLower(name)
+}
+
+// EnvSecrets references the stack secrets as ${VAR} so Compose (or
+// Portainer's stack environment) fills them from a .env file. Deployment
+// fails rather than starting with an empty key if one is missing.
+func EnvSecrets(stack *ComposeFile, config StackConfig) {
+	webui := stack.Services["open-webui"]
+	for _, name := range StackSecrets {
+		webui.Environment = append(webui.Environment,
+			fmt.Sprintf("%s=${%s:?run lite-llm stack generate or rotate-secrets to create .env}", name, name))
+	}
+
+	stack.Header = append(stack.Header, "",
+		"Secrets are read from the .env file next to this stack (Portainer: load it",
+		"under Environment variables). Rotate them with: lite-llm stack rotate-secrets")
+}
+
+// DockerSecrets mounts the stack secrets from files under SecretsDir. Open
+// WebUI has no *_FILE variables, so its entrypoint exports the key from the
+// secret before starting.
+func DockerSecrets(stack *ComposeFile, config StackConfig) {
+	if stack.Secrets == nil {
+		stack.Secrets = make(map[string]*Secret)
+	}
+
+	webui := stack.Services["open-webui"]
+	var exports []string
+	for _, name := range StackSecrets {
+		secret := SecretFileName(name)
+		stack.Secrets[secret] = &Secret{File: "./" + SecretsDir + "/" + secret}
+		webui.Secrets = append(webui.Secrets, secret)
+		// $$ escapes Compose interpolation so the shell sees $(...).
+		exports = append(exports, fmt.Sprintf("export %s=\"$$(cat /run/secrets/%s)\"", name, secret))
+	}
+	webui.Command = []string{"bash", "-c", strings.Join(exports, " && ") + " && exec bash start.sh"}
+
+	stack.Header = append(stack.Header, "",
+		"Secrets are Docker secrets read from ./"+SecretsDir+"/ next to this stack, so deploy",
+		"it with docker compose or a Portainer Git stack. Rotate them with: lite-llm stack rotate-secrets")
+}
+
+// NoAuth disables Open WebUI's login. Anyone who can reach the UI can use
+// every model and read every chat.
+func NoAuth(stack *ComposeFile, config StackConfig) {
+	webui := stack.Services["open-webui"]
+	for i, env := range webui.Environment {
+		if strings.HasPrefix(env, "WEBUI_AUTH=") {
+			webui.Environment[i] = "WEBUI_AUTH=false"
+		}
+	}
+
+	stack.Header = append(stack.Header, "", "WARNING: Open WebUI authentication is disabled.")
+}
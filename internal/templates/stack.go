@@ -2,153 +2,52 @@ package templates
 
 import (
 	"fmt"
-	"strings"
 )
 
 type StackConfig struct {
 	StackName  string
 	OllamaPort int
 	WebUIPort  int
-	GPUType    string // "amd" or "nvidia"
-}
-
-func GeneratePortainerStack(config StackConfig) (string, error) {
-	var ollamaService string
-	
-	if config.GPUType == "nvidia" {
-		ollamaService = `  ollama:
-    image: ollama/ollama:latest
-    container_name: %s-ollama
-    restart: unless-stopped
-    ports:
-      - "%d:11434"
-    volumes:
-      - ollama_data:/root/.ollama
-    environment:
-      - NVIDIA_VISIBLE_DEVICES=all
-      - NVIDIA_DRIVER_CAPABILITIES=compute,utility
-    deploy:
-      resources:
-        reservations:
-          devices:
-            - driver: nvidia
-              count: 1
-              capabilities: [gpu]
-    labels:
-      - "io.portainer.accesscontrol.teams=administrators"
-    healthcheck:
-      test: ["CMD", "curl", "-f", "http://localhost:11434/api/version"]
-      interval: 30s
-      timeout: 10s
-      retries: 3
-      start_period: 40s
-    networks:
-      - llm-network`
-	} else {
-		// AMD ROCm configuration
-		ollamaService = `  ollama:
-    image: ollama/ollama:rocm
-    container_name: %s-ollama
-    restart: unless-stopped
-    ports:
-      - "%d:11434"
-    volumes:
-      - ollama_data:/root/.ollama
-    devices:
-      - /dev/kfd
-      - /dev/dri
-    environment:
-      - HSA_OVERRIDE_GFX_VERSION=10.3.0  # For RX 570/580 compatibility
-      - HCC_AMDGPU_TARGET=gfx1030
-      - ROCM_PATH=/opt/rocm
-      - HIP_VISIBLE_DEVICES=0
-    labels:
-      - "io.portainer.accesscontrol.teams=administrators"
-    healthcheck:
-      test: ["CMD", "curl", "-f", "http://localhost:11434/api/version"]
-      interval: 30s
-      timeout: 10s
-      retries: 3
-      start_period: 40s
-    networks:
-      - llm-network`
-	}
-
-	template := `version: '3.8'
+	GPUType    string // "amd", "nvidia", "intel" or "cpu"
 
-services:
-%s
-
-  open-webui:
-    image: ghcr.io/open-webui/open-webui:main
-    container_name: %s-webui
-    restart: unless-stopped
-    ports:
-      - "%d:8080"
-    volumes:
-      - open_webui_data:/app/backend/data
-    environment:
-      - OLLAMA_BASE_URL=http://ollama:11434
-      - WEBUI_SECRET_KEY=%s-secret-key
-      - WEBUI_AUTH=false  # Set to true if you want authentication
-    labels:
-      - "io.portainer.accesscontrol.teams=administrators"
-    depends_on:
-      ollama:
-        condition: service_healthy
-    networks:
-      - llm-network
-
-volumes:
-  ollama_data:
-    driver: local
-    labels:
-      - "io.portainer.accesscontrol.teams=administrators"
-  open_webui_data:
-    driver: local
-    labels:
-      - "io.portainer.accesscontrol.teams=administrators"
-
-networks:
-  llm-network:
-    driver: bridge
-    labels:
-      - "io.portainer.accesscontrol.teams=administrators"
-
-# Portainer Stack Configuration
-# 
-# This stack is optimized for AMD GPU acceleration with ROCm.
-# 
-# Prerequisites:
-# 1. ROCm drivers installed on the host
-# 2. Docker with device access to /dev/kfd and /dev/dri
-# 3. At least 8GB GPU memory and 16GB system RAM
-#
-# Recommended Models for RX 570/580:
-# - llama3.1:8b-instruct-q4_K_M (~4.4GB)
-# - mistral:7b-instruct-q4_K_M (~4.4GB)
-# - gemma2:2b-instruct-q4_K_M (~1.7GB)
-#
-# After deployment, download models using:
-# docker exec %s-ollama ollama pull llama3.1:8b-instruct-q4_K_M
-`
+	// CPU only: cpuset to pin Ollama to on multi-socket/NUMA hosts, e.g.
+	// "0-15". Empty leaves scheduling to the kernel.
+	CPUSet string
 
-	// Format the ollama service first
-	formattedOllamaService := fmt.Sprintf(ollamaService, config.StackName, config.OllamaPort)
-	
-	result := fmt.Sprintf(template, 
-		formattedOllamaService, // formatted ollama service
-		config.StackName,       // webui container name  
-		config.WebUIPort,       // webui port
-		config.StackName,       // secret key
-		config.StackName,       // model download example
-	)
+	// AMD only: the GPU's gfx target (e.g. "gfx1031") and the
+	// HSA_OVERRIDE_GFX_VERSION to apply, if any. See system.ROCmEnvFor.
+	GFXTarget             string
+	HSAOverrideGFXVersion string
+}
 
-	return result, nil
+// GeneratePortainerStack renders the stack for deployment through
+// Portainer: healthchecks, Portainer access-control labels and a header
+// describing the GPU setup, plus any optional features.
+func GeneratePortainerStack(config StackConfig, features ...Feature) (string, error) {
+	features = append([]Feature{Healthchecks}, features...)
+	stack := BuildStack(config, append(features,
+		PortainerLabels,
+		Notes(
+			"Portainer Stack Configuration",
+			"",
+			"After deployment, download models using:",
+			fmt.Sprintf("docker exec %s-ollama ollama pull llama3.1:8b-instruct-q4_K_M", config.StackName),
+			"or: lite-llm models recommended",
+		),
+	)...)
+	return render(stack)
 }
 
-func GenerateROCmSetupScript() string {
-	return `#!/bin/bash
+func GenerateROCmSetupScript(config StackConfig) string {
+	var gpuEnv string
+	if config.GFXTarget != "" {
+		gpuEnv = fmt.Sprintf("# GPU target: %s\n", config.GFXTarget)
+	}
+	if config.HSAOverrideGFXVersion != "" {
+		gpuEnv += fmt.Sprintf("echo 'export HSA_OVERRIDE_GFX_VERSION=%s' >> ~/.bashrc\n", config.HSAOverrideGFXVersion)
+	}
+
+	return fmt.Sprintf(`#!/bin/bash
 # ROCm Setup Script for AMD GPU LLM Deployment
 # Run this script on your Ubuntu 24.04 system before deploying the stack
 
@@ -189,8 +88,7 @@ sudo usermod -aG render,video $USER
 # Set environment variables
 echo "Setting up environment variables..."
 echo 'export PATH=$PATH:/opt/rocm/bin' >> ~/.bashrc
-echo 'export HSA_OVERRIDE_GFX_VERSION=10.3.0' >> ~/.bashrc
-
+%s
 # Create udev rules for device access
 echo "Setting up device permissions..."
 sudo tee /etc/udev/rules.d/70-rocm.rules > /dev/null <<EOF
@@ -210,64 +108,94 @@ echo "  rocminfo"
 echo "  /opt/rocm/bin/rocm-smi"
 echo ""
 echo "Then you can deploy the Portainer stack."
-`
+`, gpuEnv)
 }
 
-func GenerateDockerComposeForReference(config StackConfig) string {
-	// This generates a standalone docker-compose.yml for reference
-	// (not for Portainer, but for users who prefer docker-compose CLI)
-	
-	template := `# Docker Compose reference for %s
-# This file is for reference only - use the Portainer stack template for deployment
-
-version: '3.8'
-
-services:
-  ollama:
-    image: ollama/ollama:rocm
-    container_name: %s-ollama
-    restart: unless-stopped
-    ports:
-      - "%d:11434"
-    volumes:
-      - ./data/ollama:/root/.ollama
-    devices:
-      - /dev/kfd
-      - /dev/dri
-    environment:
-      - HSA_OVERRIDE_GFX_VERSION=10.3.0
-      - HCC_AMDGPU_TARGET=gfx1030
-      - ROCM_PATH=/opt/rocm
-      - HIP_VISIBLE_DEVICES=0
-
-  open-webui:
-    image: ghcr.io/open-webui/open-webui:main
-    container_name: %s-webui
-    restart: unless-stopped
-    ports:
-      - "%d:8080"
-    volumes:
-      - ./data/webui:/app/backend/data
-    environment:
-      - OLLAMA_BASE_URL=http://ollama:11434
-      - WEBUI_SECRET_KEY=%s-secret-key
-      - WEBUI_AUTH=false
-    depends_on:
-      - ollama
-
-# To use this file:
-# 1. Save as docker-compose.yml
-# 2. Run: docker-compose up -d
-# 3. Download models: docker exec %s-ollama ollama pull llama3.1:8b
+// GenerateIntelSetupScript returns a script that installs the Intel GPU
+// compute runtime (Level Zero and OpenCL) used by IPEX-LLM containers.
+func GenerateIntelSetupScript() string {
+	return `#!/bin/bash
+# Intel Compute Runtime Setup Script for Intel Arc / integrated GPU LLM Deployment
+# Run this script on your Ubuntu 24.04 system before deploying the stack
+
+set -e
+
+echo "=== Intel GPU Compute Runtime Setup ==="
+
+# Check if running as root
+if [[ $EUID -eq 0 ]]; then
+   echo "Please don't run this script as root"
+   exit 1
+fi
+
+# Arc cards need the i915 or xe driver from kernel 6.2 or newer
+KERNEL_MAJOR=$(uname -r | cut -d. -f1)
+KERNEL_MINOR=$(uname -r | cut -d. -f2)
+if [[ $KERNEL_MAJOR -lt 6 || ( $KERNEL_MAJOR -eq 6 && $KERNEL_MINOR -lt 2 ) ]]; then
+   echo "Warning: kernel $(uname -r) predates Arc support in i915; upgrade to 6.2 or newer"
+fi
+
+# Update system
+echo "Updating system packages..."
+sudo apt update && sudo apt upgrade -y
+
+# Install prerequisites
+echo "Installing prerequisites..."
+sudo apt install -y wget curl gnupg2 software-properties-common
+
+# Add Intel graphics repository
+echo "Adding Intel graphics repository..."
+sudo add-apt-repository -y ppa:kobuk-team/intel-graphics
+
+# Update package list
+sudo apt update
+
+# Install the compute runtime (Level Zero + OpenCL) and tools
+echo "Installing Intel compute runtime..."
+sudo apt install -y libze-intel-gpu1 libze1 intel-opencl-icd clinfo intel-gpu-tools
+
+# Add user to render and video groups
+echo "Adding user to render and video groups..."
+sudo usermod -aG render,video $USER
+
+echo ""
+echo "=== Intel GPU Setup Complete ==="
+echo ""
+echo "IMPORTANT: Log out and back in (or reboot) for group changes to take effect."
+echo "Then verify the installation by running:"
+echo "  clinfo | grep 'Device Name'"
+echo "  sudo intel_gpu_top"
+echo ""
+echo "Then generate and deploy the stack with: lite-llm stack generate --gpu intel"
 `
+}
 
-	return fmt.Sprintf(template,
-		config.StackName,
-		config.StackName,
-		config.OllamaPort,
-		config.StackName,
-		config.WebUIPort,
-		config.StackName,
-		config.StackName,
+// GenerateDockerComposeForReference renders a standalone compose file for
+// the docker compose CLI, storing data in ./data rather than named volumes.
+func GenerateDockerComposeForReference(config StackConfig, features ...Feature) (string, error) {
+	stack := BuildStack(config, append([]Feature{BindMounts("./data")}, features...)...)
+	stack.Header = append([]string{
+		"Docker Compose reference for " + config.StackName,
+		"This file is for reference only - use the Portainer stack template for deployment",
+		"",
+	}, stack.Header...)
+	stack.Notes = append(stack.Notes,
+		"To use this file:",
+		"1. Save as docker-compose.yml",
+		"2. Run: docker compose up -d",
+		fmt.Sprintf("3. Download models: docker exec %s-ollama ollama pull llama3.1:8b", config.StackName),
 	)
-}
\ No newline at end of file
+	return render(stack)
+}
+
+// GenerateComposeProject renders the default stack run by `lite-llm stack
+// up`, with healthchecks so Open WebUI waits for Ollama.
+func GenerateComposeProject(config StackConfig, features ...Feature) (string, error) {
+	stack := BuildStack(config, append([]Feature{Healthchecks}, features...)...)
+	stack.Header = append([]string{
+		"Managed by lite-llm stack up. Edit this file and run 'lite-llm stack up'",
+		"again to apply changes; 'lite-llm stack down' keeps the volumes.",
+		"",
+	}, stack.Header...)
+	return render(stack)
+}
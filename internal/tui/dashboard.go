@@ -0,0 +1,525 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/monitor"
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/lyleclassen/lite-llm/internal/system"
+	"github.com/sirupsen/logrus"
+)
+
+// Config holds the endpoints the dashboard polls.
+type Config struct {
+	OllamaURL string
+	WebUIURL  string
+	ServeURL  string
+	ServeKey  string // API key for the request log, if serve requires one
+	Interval  time.Duration
+}
+
+// Dashboard is the full-screen status view behind `status --watch`.
+type Dashboard struct {
+	cfg      Config
+	ollama   *ollama.Client
+	detector *system.Detector
+	gpus     *monitor.GPUSampler
+	http     *http.Client
+
+	mu       sync.Mutex
+	snap     snapshot
+	selected int
+	log      []string
+
+	// Input mode for the "pull model" prompt
+	prompting bool
+	input     string
+}
+
+type snapshot struct {
+	updated   time.Time
+	sysInfo   *system.SystemInfo
+	metrics   *monitor.PerformanceMetrics
+	gpus      []monitor.GPUMetrics
+	ollamaUp  bool
+	webUIUp   bool
+	serveUp   bool
+	running   []ollama.RunningModel
+	installed []ollama.Model
+	requests  []requestEntry
+}
+
+type requestEntry struct {
+	Time    time.Time     `json:"time"`
+	Method  string        `json:"method"`
+	Path    string        `json:"path"`
+	Status  int           `json:"status"`
+	Latency time.Duration `json:"latency"`
+	Model   string        `json:"model"`
+}
+
+func NewDashboard(cfg Config, detector *system.Detector) *Dashboard {
+	return &Dashboard{
+		cfg:      cfg,
+		ollama:   ollama.NewClient(cfg.OllamaURL),
+		detector: detector,
+		gpus:     monitor.NewGPUSampler(),
+		http:     &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// Run shows the dashboard until the user quits.
+func (d *Dashboard) Run() error {
+	term, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer term.Close()
+
+	// Log output would scribble over the screen; activity goes to the
+	// dashboard's own log pane instead.
+	prevOut := logrus.StandardLogger().Out
+	logrus.SetOutput(io.Discard)
+	defer logrus.SetOutput(prevOut)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	keys := make(chan int, 16)
+	go readKeys(keys)
+
+	redraw := make(chan struct{}, 1)
+	requestRedraw := func() {
+		select {
+		case redraw <- struct{}{}:
+		default:
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(d.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			d.refresh(ctx)
+			requestRedraw()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	d.addLog("Dashboard started")
+	for {
+		width, height := term.Size()
+		fmt.Print(d.render(width, height))
+
+		select {
+		case key, ok := <-keys:
+			if !ok || !d.handleKey(ctx, key, requestRedraw) {
+				return nil
+			}
+		case <-redraw:
+		}
+	}
+}
+
+// refresh polls every data source. System info comes from the cached
+// detector; only the cheap sysfs and HTTP reads happen each tick.
+func (d *Dashboard) refresh(ctx context.Context) {
+	var snap snapshot
+	snap.updated = time.Now()
+	snap.sysInfo, _ = d.detector.Info()
+	snap.gpus = d.gpus.Sample()
+	snap.metrics = monitor.PerformanceMetricsFor(snap.gpus)
+
+	reqCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	snap.ollamaUp = d.ollama.Health(reqCtx) == nil
+	if snap.ollamaUp {
+		snap.running, _ = d.ollama.ListRunning(reqCtx)
+		snap.installed, _ = d.ollama.ListModels(reqCtx)
+		sort.Slice(snap.installed, func(i, j int) bool {
+			return snap.installed[i].Name < snap.installed[j].Name
+		})
+	}
+
+	snap.webUIUp = d.checkURL(d.cfg.WebUIURL)
+	snap.serveUp = d.checkURL(d.cfg.ServeURL + "/api/health")
+	if snap.serveUp {
+		snap.requests = d.fetchRequests()
+	}
+
+	d.mu.Lock()
+	d.snap = snap
+	if d.selected >= len(snap.running) {
+		d.selected = len(snap.running) - 1
+	}
+	if d.selected < 0 {
+		d.selected = 0
+	}
+	d.mu.Unlock()
+}
+
+func (d *Dashboard) checkURL(url string) bool {
+	resp, err := d.http.Get(url)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode < 500
+}
+
+func (d *Dashboard) fetchRequests() []requestEntry {
+	req, err := http.NewRequest("GET", d.cfg.ServeURL+"/api/requests/recent?limit=100", nil)
+	if err != nil {
+		return nil
+	}
+	if d.cfg.ServeKey != "" {
+		req.Header.Set("Authorization", "Bearer "+d.cfg.ServeKey)
+	}
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	var body struct {
+		Requests []requestEntry `json:"requests"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil
+	}
+	return body.Requests
+}
+
+func (d *Dashboard) addLog(format string, args ...interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	line := time.Now().Format("15:04:05") + " " + fmt.Sprintf(format, args...)
+	d.log = append(d.log, line)
+	if len(d.log) > 100 {
+		d.log = d.log[len(d.log)-100:]
+	}
+}
+
+// handleKey processes a key press and reports whether to keep running.
+func (d *Dashboard) handleKey(ctx context.Context, key int, redraw func()) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if key == keyCtrlC {
+		return false
+	}
+
+	if d.prompting {
+		switch key {
+		case keyEnter:
+			name := strings.TrimSpace(d.input)
+			d.prompting = false
+			d.input = ""
+			if name != "" {
+				go d.pull(ctx, name, redraw)
+			}
+		case keyEscape:
+			d.prompting = false
+			d.input = ""
+		case keyBack, 8:
+			if len(d.input) > 0 {
+				d.input = d.input[:len(d.input)-1]
+			}
+		default:
+			if key >= 32 && key < 127 {
+				d.input += string(rune(key))
+			}
+		}
+		return true
+	}
+
+	switch key {
+	case 'q', keyEscape:
+		return false
+	case 'j', keyDown:
+		if d.selected < len(d.snap.running)-1 {
+			d.selected++
+		}
+	case 'k', keyUp:
+		if d.selected > 0 {
+			d.selected--
+		}
+	case 'u':
+		if d.selected < len(d.snap.running) {
+			go d.unload(ctx, d.snap.running[d.selected].Name, redraw)
+		}
+	case 'p':
+		d.prompting = true
+	case 'r':
+		d.detector.Invalidate()
+		go func() {
+			d.refresh(ctx)
+			redraw()
+		}()
+	}
+	return true
+}
+
+func (d *Dashboard) unload(ctx context.Context, name string, redraw func()) {
+	d.addLog("Unloading %s...", name)
+	redraw()
+
+	if err := d.ollama.UnloadModel(ctx, name); err != nil {
+		d.addLog("Failed to unload %s: %v", name, err)
+	} else {
+		d.addLog("Unloaded %s", name)
+	}
+	d.refresh(ctx)
+	redraw()
+}
+
+func (d *Dashboard) pull(ctx context.Context, name string, redraw func()) {
+	d.addLog("Pulling %s...", name)
+	redraw()
+
+	lastReport := time.Time{}
+	err := d.ollama.PullModel(ctx, name, func(progress ollama.PullProgress) {
+		// Progress arrives many times per second; log it at most every 2s
+		if time.Since(lastReport) < 2*time.Second {
+			return
+		}
+		lastReport = time.Now()
+		if progress.Total > 0 {
+			percent := float64(progress.Completed) / float64(progress.Total) * 100
+			d.addLog("  %s: %.1f%% (%s)", name, percent, progress.Status)
+		} else {
+			d.addLog("  %s: %s", name, progress.Status)
+		}
+		redraw()
+	})
+
+	if err != nil {
+		d.addLog("Failed to pull %s: %v", name, err)
+	} else {
+		d.addLog("Pulled %s", name)
+	}
+	d.refresh(ctx)
+	redraw()
+}
+
+// render draws the whole screen. Lines are written with \r\n because the
+// terminal is in raw mode.
+func (d *Dashboard) render(width, height int) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	snap := d.snap
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	header := " lite-llm  refreshing..."
+	if !snap.updated.IsZero() {
+		header = fmt.Sprintf(" lite-llm  %s", snap.updated.Format("2006-01-02 15:04:05"))
+	}
+	help := "[q]uit [j/k] select [u]nload [p]ull [r]efresh "
+	add("\033[7m%s%s%s\033[0m", header, strings.Repeat(" ", max(1, width-len(header)-len(help))), help)
+
+	add(section("Services", width))
+	add(" Ollama API %s   Open WebUI %s   lite-llm serve %s",
+		indicator(snap.ollamaUp), indicator(snap.webUIUp), indicator(snap.serveUp))
+
+	add(section("System", width))
+	if snap.sysInfo != nil {
+		add(" Kernel %s   Docker %s   ROCm %s", snap.sysInfo.KernelVersion,
+			indicator(snap.sysInfo.HasDocker), indicator(snap.sysInfo.HasROCm))
+	}
+	if m := snap.metrics; m != nil {
+		add(" CPU  %s %5.1f%%", bar(m.CPUUsage, 20), m.CPUUsage)
+		add(" RAM  %s %5.1f%%  %.1f / %.1f GB", bar(m.MemoryUsagePercent, 20), m.MemoryUsagePercent,
+			float64(m.MemoryUsedMB)/1024, float64(m.MemoryTotalMB)/1024)
+	}
+
+	add(section("GPUs", width))
+	if len(snap.gpus) == 0 {
+		model := "none detected"
+		if snap.sysInfo != nil && snap.sysInfo.GPUModel != "" {
+			model = snap.sysInfo.GPUModel + " (no sysfs metrics)"
+		}
+		add(" %s", model)
+	}
+	for _, gpu := range snap.gpus {
+		vramPercent := 0.0
+		if gpu.MemoryTotalMB > 0 {
+			vramPercent = float64(gpu.MemoryUsedMB) / float64(gpu.MemoryTotalMB) * 100
+		}
+		busy := "  n/a"
+		if gpu.Usage >= 0 {
+			busy = fmt.Sprintf("%4.0f%%", gpu.Usage)
+		}
+		temp := "n/a"
+		if gpu.Temperature >= 0 {
+			temp = fmt.Sprintf("%.0f°C", gpu.Temperature)
+		}
+		if gpu.MemoryTotalMB == 0 && gpu.FrequencyMHz >= 0 {
+			// Intel: no VRAM counters, show the GT clock instead.
+			add(" %-6s busy %s %s  freq %4.0f / %4.0f MHz  %s", gpu.Card,
+				bar(gpu.Usage, 10), busy, gpu.FrequencyMHz, gpu.MaxFrequencyMHz, temp)
+			continue
+		}
+		add(" %-6s busy %s %s  VRAM %s %.1f / %.1f GB  %s", gpu.Card,
+			bar(gpu.Usage, 10), busy, bar(vramPercent, 10),
+			float64(gpu.MemoryUsedMB)/1024, float64(gpu.MemoryTotalMB)/1024, temp)
+	}
+
+	add(section("Running models", width))
+	if len(snap.running) == 0 {
+		add(" (none loaded)")
+	}
+	for i, m := range snap.running {
+		cursor := "  "
+		if i == d.selected {
+			cursor = "\033[1m>\033[0m "
+		}
+		placement := "CPU"
+		if m.Size > 0 && m.SizeVRAM > 0 {
+			placement = fmt.Sprintf("%.0f%% GPU", float64(m.SizeVRAM)/float64(m.Size)*100)
+		}
+		until := "-"
+		if !m.ExpiresAt.IsZero() {
+			until = time.Until(m.ExpiresAt).Round(time.Second).String()
+		}
+		add("%s%-40s %6.1f GB  %-8s expires in %s", cursor, m.Name, float64(m.Size)/(1024*1024*1024), placement, until)
+	}
+
+	// The request log and activity log share whatever space is left
+	var logLines []string
+	for _, r := range snap.requests {
+		logLines = append(logLines, fmt.Sprintf("%s %-6s %-22s %3d %8s %s", r.Time.Format("15:04:05"),
+			r.Method, r.Path, r.Status, r.Latency.Round(time.Millisecond), r.Model))
+	}
+
+	installedHeader := section(fmt.Sprintf("Installed models (%d)", len(snap.installed)), width)
+	remaining := height - len(lines) - 5
+	installedRows := min(len(snap.installed), max(1, remaining/3))
+	add(installedHeader)
+	for _, m := range snap.installed[:installedRows] {
+		add("  %-40s %6.1f GB  %s", m.Name, float64(m.Size)/(1024*1024*1024), m.Modified.Format("2006-01-02"))
+	}
+	if installedRows < len(snap.installed) {
+		lines[len(lines)-1] += fmt.Sprintf("  (+%d more)", len(snap.installed)-installedRows)
+	}
+
+	remaining = height - len(lines) - 3
+	activityRows := min(len(d.log), max(1, remaining/3))
+	requestRows := max(0, remaining-activityRows)
+
+	add(section("Request log", width))
+	if !snap.serveUp {
+		add(" (lite-llm serve not running)")
+		requestRows--
+	}
+	for _, line := range tail(logLines, requestRows) {
+		add(" %s", line)
+	}
+	add(section("Activity", width))
+	for _, line := range tail(d.log, activityRows) {
+		add(" %s", line)
+	}
+
+	// Pad to full height, then draw the prompt on the last line
+	for len(lines) < height-1 {
+		add("")
+	}
+	lines = lines[:height-1]
+	if d.prompting {
+		add("\033[7m Pull model: %s_\033[0m", d.input)
+	} else {
+		add("")
+	}
+
+	var out strings.Builder
+	out.WriteString("\033[H")
+	for i, line := range lines {
+		out.WriteString(truncate(line, width))
+		out.WriteString("\033[K")
+		if i < len(lines)-1 {
+			out.WriteString("\r\n")
+		}
+	}
+	return out.String()
+}
+
+func section(title string, width int) string {
+	label := "── " + title + " "
+	return "\033[1m" + label + strings.Repeat("─", max(0, width-len([]rune(label)))) + "\033[0m"
+}
+
+func indicator(ok bool) string {
+	if ok {
+		return "\033[32m● up\033[0m"
+	}
+	return "\033[31m● down\033[0m"
+}
+
+// bar renders percent (0-100) as a fixed-width gauge; negative values
+// mean unavailable and render empty.
+func bar(percent float64, width int) string {
+	if percent < 0 {
+		percent = 0
+	}
+	filled := int(percent / 100 * float64(width))
+	if filled > width {
+		filled = width
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("·", width-filled) + "]"
+}
+
+func tail(lines []string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	if len(lines) > n {
+		return lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// truncate cuts s to width visible runes, skipping ANSI escape sequences
+// when counting.
+func truncate(s string, width int) string {
+	var out strings.Builder
+	visible := 0
+	inEscape := false
+	for _, r := range s {
+		if r == '\033' {
+			inEscape = true
+		}
+		if inEscape {
+			out.WriteRune(r)
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+				inEscape = false
+			}
+			continue
+		}
+		if visible >= width {
+			continue
+		}
+		out.WriteRune(r)
+		visible++
+	}
+	return out.String()
+}
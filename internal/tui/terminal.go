@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// terminal puts the controlling TTY into raw mode on the alternate screen
+// and restores it on close. It shells out to stty rather than issuing
+// termios ioctls so it works without extra dependencies.
+type terminal struct {
+	savedState string
+}
+
+func openTerminal() (*terminal, error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terminal state: %w", err)
+	}
+
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+
+	// Alternate screen, hidden cursor
+	fmt.Print("\033[?1049h\033[?25l")
+
+	return &terminal{savedState: strings.TrimSpace(state)}, nil
+}
+
+func (t *terminal) Close() {
+	fmt.Print("\033[?25h\033[?1049l")
+	stty(t.savedState)
+}
+
+// Size returns the terminal width and height, defaulting to 80x24.
+func (t *terminal) Size() (int, int) {
+	out, err := stty("size")
+	if err != nil {
+		return 80, 24
+	}
+
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 80, 24
+	}
+
+	rows, err1 := strconv.Atoi(fields[0])
+	cols, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil || rows <= 0 || cols <= 0 {
+		return 80, 24
+	}
+
+	return cols, rows
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+// IsTerminal reports whether both stdin and stdout are attached to a TTY.
+func IsTerminal() bool {
+	for _, f := range []*os.File{os.Stdin, os.Stdout} {
+		fi, err := f.Stat()
+		if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Key codes produced by readKeys for non-printable input.
+const (
+	keyUp     = -1
+	keyDown   = -2
+	keyEnter  = '\r'
+	keyEscape = 27
+	keyBack   = 127
+	keyCtrlC  = 3
+)
+
+// readKeys decodes stdin into key codes, translating arrow-key escape
+// sequences into keyUp/keyDown.
+func readKeys(keys chan<- int) {
+	buf := make([]byte, 16)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+
+		in := buf[:n]
+		for len(in) > 0 {
+			if len(in) >= 3 && in[0] == keyEscape && in[1] == '[' {
+				switch in[2] {
+				case 'A':
+					keys <- keyUp
+				case 'B':
+					keys <- keyDown
+				}
+				in = in[3:]
+				continue
+			}
+			keys <- int(in[0])
+			in = in[1:]
+		}
+	}
+}
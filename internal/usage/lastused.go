@@ -0,0 +1,142 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/sirupsen/logrus"
+)
+
+// LastUsed records when each model last served a request, so `models
+// prune` can tell which models sit idle. It is kept in memory and persisted
+// to a JSON file.
+type LastUsed struct {
+	mu    sync.Mutex
+	path  string
+	times map[string]time.Time
+	dirty bool
+}
+
+func NewLastUsed(path string) *LastUsed {
+	return &LastUsed{path: path, times: map[string]time.Time{}}
+}
+
+// NormalizeModel returns the name Ollama lists a model under, so that
+// "mistral" and "mistral:latest" are one model.
+func NormalizeModel(name string) string {
+	parsed, err := ollama.ParseModelName(name)
+	if err != nil {
+		return name
+	}
+	return parsed.String()
+}
+
+// Touch records a use of model at t.
+func (l *LastUsed) Touch(model string, t time.Time) {
+	if model == "" {
+		return
+	}
+	model = NormalizeModel(model)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t.After(l.times[model]) {
+		l.times[model] = t
+		l.dirty = true
+	}
+}
+
+// Get returns when model was last used, or the zero time if never.
+func (l *LastUsed) Get(model string) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.times[NormalizeModel(model)]
+}
+
+// Load reads times previously written by Save. A missing file is not an
+// error.
+func (l *LastUsed) Load() error {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read model usage: %w", err)
+	}
+
+	var times map[string]time.Time
+	if err := json.Unmarshal(data, &times); err != nil {
+		return fmt.Errorf("failed to decode model usage: %w", err)
+	}
+	for model, t := range times {
+		l.Touch(model, t)
+	}
+	l.mu.Lock()
+	l.dirty = false
+	l.mu.Unlock()
+	return nil
+}
+
+// Save writes the times to the file if they changed, replacing it
+// atomically.
+func (l *LastUsed) Save() error {
+	l.mu.Lock()
+	if !l.dirty {
+		l.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(l.times, "", "  ")
+	l.dirty = false
+	l.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode model usage: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write model usage: %w", err)
+	}
+	return os.Rename(tmp, l.path)
+}
+
+// Run persists the times every interval until ctx is cancelled. It also
+// counts models Ollama has loaded as used, which catches clients such as
+// Open WebUI that talk to Ollama directly rather than through lite-llm.
+func (l *LastUsed) Run(ctx context.Context, client *ollama.Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			l.save()
+			return
+		case <-ticker.C:
+			pollCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			running, err := client.ListRunning(pollCtx)
+			cancel()
+			if err == nil {
+				now := time.Now()
+				for _, model := range running {
+					l.Touch(model.Name, now)
+				}
+			}
+			l.save()
+		}
+	}
+}
+
+func (l *LastUsed) save() {
+	if err := l.Save(); err != nil {
+		logrus.Warnf("Failed to persist model usage: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is one model request served by lite-llm.
+type Record struct {
+	Time             time.Time `json:"time"`
+	Client           string    `json:"client"` // key name, or ip:<address> without authentication
+	Model            string    `json:"model"`
+	Path             string    `json:"path"`
+	Status           int       `json:"status"`
+	PromptTokens     int       `json:"prompt_tokens,omitempty"`
+	CompletionTokens int       `json:"completion_tokens,omitempty"`
+	LatencyMS        int64     `json:"latency_ms"`
+}
+
+// Tokens is the prompt and completion tokens together.
+func (r *Record) Tokens() int {
+	return r.PromptTokens + r.CompletionTokens
+}
+
+const ledgerDateFormat = "2006-01-02"
+
+// Ledger appends records to one JSON-lines file per day,
+// requests-<date>.jsonl, and keeps today's token totals per client in
+// memory for quotas.
+type Ledger struct {
+	mu     sync.Mutex
+	dir    string
+	day    string
+	tokens map[string]int
+}
+
+// NewLedger opens the ledger in dir, loading today's totals.
+func NewLedger(dir string) (*Ledger, error) {
+	l := &Ledger{dir: dir}
+	day := time.Now().Format(ledgerDateFormat)
+	records, err := readLedgerFile(l.path(day))
+	if err != nil {
+		return nil, err
+	}
+	l.day, l.tokens = day, map[string]int{}
+	for _, r := range records {
+		l.tokens[r.Client] += r.Tokens()
+	}
+	return l, nil
+}
+
+func (l *Ledger) path(day string) string {
+	return filepath.Join(l.dir, "requests-"+day+".jsonl")
+}
+
+// Add appends a record to its day's file.
+func (l *Ledger) Add(r Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	day := r.Time.Local().Format(ledgerDateFormat)
+	l.rollover(day)
+	if day == l.day {
+		l.tokens[r.Client] += r.Tokens()
+	}
+
+	if err := os.MkdirAll(l.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create usage directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path(day), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	return nil
+}
+
+// TokensToday returns the tokens client has used since local midnight.
+func (l *Ledger) TokensToday(client string) int {
+	return l.tokensOn(client, time.Now())
+}
+
+// tokensOn returns the tokens client has used on now's day.
+func (l *Ledger) tokensOn(client string, now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover(now.Local().Format(ledgerDateFormat))
+	return l.tokens[client]
+}
+
+// rollover starts a new day's totals once day is past the current one.
+func (l *Ledger) rollover(day string) {
+	if day > l.day {
+		l.day, l.tokens = day, map[string]int{}
+	}
+}
+
+// ReadRecords returns the records in dir from since onwards, oldest first.
+func ReadRecords(dir string, since time.Time) ([]Record, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read usage: %w", err)
+	}
+
+	first := since.Local().Format(ledgerDateFormat)
+	var records []Record
+	for _, entry := range entries {
+		day, ok := strings.CutPrefix(entry.Name(), "requests-")
+		if !ok || !strings.HasSuffix(day, ".jsonl") {
+			continue
+		}
+		if strings.TrimSuffix(day, ".jsonl") < first {
+			continue
+		}
+		dayRecords, err := readLedgerFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range dayRecords {
+			if !r.Time.Before(since) {
+				records = append(records, r)
+			}
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
+
+func readLedgerFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read usage: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for scanner.Scan() {
+		var r Record
+		// A line cut short by a crash is skipped rather than failing the
+		// whole day.
+		if err := json.Unmarshal(scanner.Bytes(), &r); err == nil {
+			records = append(records, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// Summary totals the records of one client or model.
+type Summary struct {
+	Group            string  `json:"group" yaml:"group"`
+	Requests         int     `json:"requests" yaml:"requests"`
+	Errors           int     `json:"errors" yaml:"errors"`
+	PromptTokens     int     `json:"prompt_tokens" yaml:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens" yaml:"completion_tokens"`
+	AvgLatencyMS     float64 `json:"avg_latency_ms" yaml:"avg_latency_ms"`
+}
+
+// Summarize groups records by "key" or "model", most tokens first.
+func Summarize(records []Record, by string) ([]Summary, error) {
+	var group func(*Record) string
+	switch by {
+	case "key":
+		group = func(r *Record) string { return r.Client }
+	case "model":
+		group = func(r *Record) string { return r.Model }
+	default:
+		return nil, fmt.Errorf("cannot group usage by %q: expected key or model", by)
+	}
+
+	index := map[string]int{}
+	var summaries []Summary
+	latency := map[string]int64{}
+	for i := range records {
+		r := &records[i]
+		name := group(r)
+		idx, ok := index[name]
+		if !ok {
+			idx = len(summaries)
+			index[name] = idx
+			summaries = append(summaries, Summary{Group: name})
+		}
+		s := &summaries[idx]
+		s.Requests++
+		if r.Status >= 400 {
+			s.Errors++
+		}
+		s.PromptTokens += r.PromptTokens
+		s.CompletionTokens += r.CompletionTokens
+		latency[name] += r.LatencyMS
+	}
+	for i := range summaries {
+		s := &summaries[i]
+		s.AvgLatencyMS = float64(latency[s.Group]) / float64(s.Requests)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].PromptTokens+summaries[i].CompletionTokens > summaries[j].PromptTokens+summaries[j].CompletionTokens
+	})
+	return summaries, nil
+}
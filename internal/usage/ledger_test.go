@@ -0,0 +1,131 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLedgerRollover(t *testing.T) {
+	dir := t.TempDir()
+	today := time.Now()
+	year, month, day := today.Date()
+	// Tomorrow, so the records land after the ledger's opening day.
+	late := time.Date(year, month, day+1, 23, 59, 0, 0, time.Local)
+	early := late.Add(2 * time.Minute)
+
+	ledger, err := NewLedger(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(r Record) {
+		t.Helper()
+		if err := ledger.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add(Record{Time: late, Client: "alice", Model: "mistral:7b", PromptTokens: 100, CompletionTokens: 50})
+	if got := ledger.tokensOn("alice", late); got != 150 {
+		t.Errorf("tokens before midnight = %d, want 150", got)
+	}
+
+	add(Record{Time: early, Client: "alice", Model: "mistral:7b", PromptTokens: 10, CompletionTokens: 5})
+	if got := ledger.tokensOn("alice", early); got != 15 {
+		t.Errorf("tokens after midnight = %d, want 15", got)
+	}
+	// A request that started before midnight is filed under its own day
+	// without counting towards the new one.
+	add(Record{Time: late, Client: "alice", Model: "mistral:7b", PromptTokens: 1000})
+	if got := ledger.tokensOn("alice", early); got != 15 {
+		t.Errorf("tokens after a late record = %d, want 15", got)
+	}
+
+	for day, want := range map[time.Time]int{late: 2, early: 1} {
+		records, err := readLedgerFile(filepath.Join(dir, "requests-"+day.Format(ledgerDateFormat)+".jsonl"))
+		if err != nil || len(records) != want {
+			t.Errorf("%s: %d records, %v; want %d", day.Format(ledgerDateFormat), len(records), err, want)
+		}
+	}
+
+	// A restarted server picks up today's totals from the file.
+	add(Record{Time: today, Client: "bob", PromptTokens: 7})
+	reopened, err := NewLedger(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.TokensToday("bob"); got != 7 {
+		t.Errorf("tokens after reopening = %d, want 7", got)
+	}
+}
+
+func TestReadRecords(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("requests-2026-10-16.jsonl", `{"time":"2026-10-16T09:00:00Z","client":"alice","model":"mistral:7b"}`+"\n")
+	write("requests-2026-10-17.jsonl", `{"time":"2026-10-17T18:00:00Z","client":"bob","model":"llama3.1:8b"}`+"\n"+
+		`{"time":"2026-10-17T08:00:00Z","client":"alice","model":"mistral:7b"}`+"\n"+
+		`{"time":"2026-10-17T19:00`) // cut short by a crash
+	write("last-used.json", `{}`)
+
+	records, err := ReadRecords(dir, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var clients []string
+	for _, r := range records {
+		clients = append(clients, r.Client)
+	}
+	if want := []string{"alice", "bob"}; !reflect.DeepEqual(clients, want) {
+		t.Errorf("clients = %v, want %v (oldest first)", clients, want)
+	}
+
+	if records, err := ReadRecords(filepath.Join(dir, "missing"), time.Time{}); err != nil || records != nil {
+		t.Errorf("ReadRecords of a missing directory = %v, %v", records, err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	records := []Record{
+		{Client: "alice", Model: "mistral:7b", Status: 200, PromptTokens: 100, CompletionTokens: 200, LatencyMS: 1000},
+		{Client: "bob", Model: "llama3.1:8b", Status: 200, PromptTokens: 1000, CompletionTokens: 500, LatencyMS: 4000},
+		{Client: "alice", Model: "llama3.1:8b", Status: 500, LatencyMS: 50},
+		{Client: "alice", Model: "mistral:7b", Status: 200, PromptTokens: 10, CompletionTokens: 20, LatencyMS: 250},
+	}
+
+	tests := []struct {
+		by   string
+		want []Summary
+	}{
+		{"key", []Summary{
+			{Group: "bob", Requests: 1, PromptTokens: 1000, CompletionTokens: 500, AvgLatencyMS: 4000},
+			{Group: "alice", Requests: 3, Errors: 1, PromptTokens: 110, CompletionTokens: 220, AvgLatencyMS: 1300.0 / 3},
+		}},
+		{"model", []Summary{
+			{Group: "llama3.1:8b", Requests: 2, Errors: 1, PromptTokens: 1000, CompletionTokens: 500, AvgLatencyMS: 2025},
+			{Group: "mistral:7b", Requests: 2, PromptTokens: 110, CompletionTokens: 220, AvgLatencyMS: 625},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := Summarize(records, tt.by)
+		if err != nil {
+			t.Fatalf("Summarize by %s: %v", tt.by, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Summarize by %s:\n got %+v\nwant %+v", tt.by, got, tt.want)
+		}
+	}
+
+	if got, err := Summarize(nil, "key"); err != nil || len(got) != 0 {
+		t.Errorf("Summarize of no records = %v, %v", got, err)
+	}
+	if _, err := Summarize(records, "path"); err == nil {
+		t.Error("Summarize by path succeeded")
+	}
+}
@@ -0,0 +1,140 @@
+package usage
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limits caps what one client may use. Zero or negative means unlimited.
+type Limits struct {
+	RequestsPerMinute int `json:"requests_per_minute" mapstructure:"requests_per_minute"`
+	Concurrent        int `json:"concurrent" mapstructure:"concurrent"`
+	DailyTokens       int `json:"daily_tokens" mapstructure:"daily_tokens"`
+}
+
+// merge returns l with the fields set in override replacing its own; an
+// override below zero lifts that limit.
+func (l Limits) merge(override Limits) Limits {
+	if override.RequestsPerMinute != 0 {
+		l.RequestsPerMinute = override.RequestsPerMinute
+	}
+	if override.Concurrent != 0 {
+		l.Concurrent = override.Concurrent
+	}
+	if override.DailyTokens != 0 {
+		l.DailyTokens = override.DailyTokens
+	}
+	return l
+}
+
+// Limiter enforces Limits per client: a token bucket for the request rate,
+// a count of requests in flight, and the ledger's daily token totals.
+type Limiter struct {
+	defaults  Limits
+	overrides map[string]Limits
+	ledger    *Ledger
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+	active  map[string]int
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewLimiter applies defaults to every client, with per-client overrides
+// keyed by client name. ledger may be nil if there are no token quotas.
+func NewLimiter(defaults Limits, overrides map[string]Limits, ledger *Ledger) *Limiter {
+	return &Limiter{
+		defaults:  defaults,
+		overrides: overrides,
+		ledger:    ledger,
+		buckets:   map[string]*bucket{},
+		active:    map[string]int{},
+	}
+}
+
+// For returns the limits that apply to client.
+func (l *Limiter) For(client string) Limits {
+	return l.defaults.merge(l.overrides[client])
+}
+
+// Allow takes one request from client's rate. When the rate is used up it
+// returns false and how long until the next request is allowed. The bucket
+// holds a minute's worth of requests, so short bursts pass.
+func (l *Limiter) Allow(client string, now time.Time) (bool, time.Duration) {
+	rpm := l.For(client).RequestsPerMinute
+	if rpm <= 0 {
+		return true, 0
+	}
+	perSecond := float64(rpm) / 60
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: float64(rpm), updated: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(float64(rpm), b.tokens+now.Sub(b.updated).Seconds()*perSecond)
+	b.updated = now
+	l.sweep(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	return false, wait
+}
+
+// sweep drops buckets left alone for a minute. Any bucket refills within a
+// minute, and a client without one starts full, so nothing changes for
+// the client but the map stays the size of the recent clients.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+	l.swept = now
+	for client, b := range l.buckets {
+		if now.Sub(b.updated) >= time.Minute {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// Acquire counts a request in flight for client, returning false if it
+// already has as many as it may. release must be called when an acquired
+// request finishes.
+func (l *Limiter) Acquire(client string) (release func(), ok bool) {
+	max := l.For(client).Concurrent
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if max > 0 && l.active[client] >= max {
+		return nil, false
+	}
+	l.active[client]++
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.active[client]--; l.active[client] <= 0 {
+			delete(l.active, client)
+		}
+	}, true
+}
+
+// QuotaExceeded reports whether client has used its daily tokens, and how
+// long until the quota resets at local midnight.
+func (l *Limiter) QuotaExceeded(client string, now time.Time) (bool, time.Duration) {
+	quota := l.For(client).DailyTokens
+	if quota <= 0 || l.ledger == nil || l.ledger.tokensOn(client, now) < quota {
+		return false, 0
+	}
+	year, month, day := now.Date()
+	midnight := time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())
+	return true, midnight.Sub(now)
+}
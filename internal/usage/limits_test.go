@@ -0,0 +1,176 @@
+package usage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimitsMerge(t *testing.T) {
+	defaults := Limits{RequestsPerMinute: 60, Concurrent: 2, DailyTokens: 1000}
+	tests := []struct {
+		name     string
+		override Limits
+		want     Limits
+	}{
+		{"no override", Limits{}, defaults},
+		{"raised", Limits{DailyTokens: 5000}, Limits{RequestsPerMinute: 60, Concurrent: 2, DailyTokens: 5000}},
+		{"lifted", Limits{RequestsPerMinute: -1, Concurrent: -1}, Limits{RequestsPerMinute: -1, Concurrent: -1, DailyTokens: 1000}},
+	}
+	for _, tt := range tests {
+		l := NewLimiter(defaults, map[string]Limits{"batch": tt.override}, nil)
+		if got := l.For("batch"); got != tt.want {
+			t.Errorf("%s: For = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAllow(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	// Each step is a request at an offset from start.
+	tests := []struct {
+		name  string
+		rpm   int
+		steps []time.Duration
+		want  []bool
+		// wait is the Retry-After of the last step when refused.
+		wait time.Duration
+	}{
+		{"burst up to the minute's worth", 3, []time.Duration{0, 0, 0, 0}, []bool{true, true, true, false}, 20 * time.Second},
+		{"refills at the rate", 3, []time.Duration{0, 0, 0, 20 * time.Second, 20 * time.Second}, []bool{true, true, true, true, false}, 20 * time.Second},
+		{"partial refill shortens the wait", 60, append(times(60, 0), 500*time.Millisecond), append(allowed(60), false), 500 * time.Millisecond},
+		{"refill is capped", 2, []time.Duration{0, 0, time.Hour, time.Hour, time.Hour}, []bool{true, true, true, true, false}, 30 * time.Second},
+		{"unlimited", 0, times(1000, 0), allowed(1000), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(Limits{RequestsPerMinute: tt.rpm}, nil, nil)
+			var wait time.Duration
+			for i, step := range tt.steps {
+				var ok bool
+				ok, wait = l.Allow("ip:10.0.0.5", start.Add(step))
+				if ok != tt.want[i] {
+					t.Fatalf("request %d at +%v: allowed = %v, want %v", i, step, ok, tt.want[i])
+				}
+			}
+			if wait != tt.wait {
+				t.Errorf("Retry-After = %v, want %v", wait, tt.wait)
+			}
+		})
+	}
+}
+
+// times is n requests at the same offset.
+func times(n int, offset time.Duration) []time.Duration {
+	steps := make([]time.Duration, n)
+	for i := range steps {
+		steps[i] = offset
+	}
+	return steps
+}
+
+// allowed expects n requests to pass.
+func allowed(n int) []bool {
+	want := make([]bool, n)
+	for i := range want {
+		want[i] = true
+	}
+	return want
+}
+
+func TestAllowPerClientAndSweep(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	l := NewLimiter(Limits{RequestsPerMinute: 1}, map[string]Limits{"batch": {RequestsPerMinute: -1}}, nil)
+
+	if ok, _ := l.Allow("alice", start); !ok {
+		t.Fatal("first request refused")
+	}
+	if ok, _ := l.Allow("bob", start); !ok {
+		t.Error("one client's requests counted against another")
+	}
+	for i := 0; i < 10; i++ {
+		if ok, _ := l.Allow("batch", start); !ok {
+			t.Fatal("client with the rate lifted was refused")
+		}
+	}
+
+	// Idle buckets are dropped once a minute; a dropped client starts over
+	// with a full bucket, as it would have anyway.
+	later := start.Add(2 * time.Minute)
+	if ok, _ := l.Allow("carol", later); !ok {
+		t.Fatal("carol refused")
+	}
+	if _, kept := l.buckets["alice"]; kept || len(l.buckets) != 1 {
+		t.Errorf("buckets after a sweep: %v, want only carol", l.buckets)
+	}
+	if ok, _ := l.Allow("alice", later); !ok {
+		t.Error("alice refused after her bucket was dropped")
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	l := NewLimiter(Limits{Concurrent: 2}, map[string]Limits{"batch": {Concurrent: -1}}, nil)
+
+	release1, ok1 := l.Acquire("alice")
+	release2, ok2 := l.Acquire("alice")
+	if !ok1 || !ok2 {
+		t.Fatal("requests within the limit refused")
+	}
+	if _, ok := l.Acquire("alice"); ok {
+		t.Error("third concurrent request allowed")
+	}
+	if release, ok := l.Acquire("bob"); !ok {
+		t.Error("bob refused for alice's requests")
+	} else {
+		release()
+	}
+
+	release1()
+	release3, ok := l.Acquire("alice")
+	if !ok {
+		t.Error("request refused after one was released")
+	}
+	release2()
+	release3()
+	if len(l.active) != 0 {
+		t.Errorf("active after every release = %v", l.active)
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, ok := l.Acquire("batch"); !ok {
+			t.Fatal("client with concurrency lifted was refused")
+		}
+	}
+}
+
+func TestQuotaExceeded(t *testing.T) {
+	ledger, err := NewLedger(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLimiter(Limits{DailyTokens: 1000}, map[string]Limits{"batch": {DailyTokens: -1}}, ledger)
+	evening := time.Now().Add(24 * time.Hour)
+	year, month, day := evening.Date()
+	evening = time.Date(year, month, day, 22, 30, 0, 0, time.Local)
+
+	for _, client := range []string{"alice", "batch"} {
+		if err := ledger.Add(Record{Time: evening, Client: client, PromptTokens: 400, CompletionTokens: 600}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exceeded, retry := l.QuotaExceeded("alice", evening)
+	if !exceeded || retry != 90*time.Minute {
+		t.Errorf("QuotaExceeded at 22:30 = %v, %v; want true, 1h30m until midnight", exceeded, retry)
+	}
+	if exceeded, _ := l.QuotaExceeded("batch", evening); exceeded {
+		t.Error("client with the quota lifted was refused")
+	}
+	if exceeded, _ := l.QuotaExceeded("bob", evening); exceeded {
+		t.Error("bob refused for alice's tokens")
+	}
+
+	// The quota resets at midnight.
+	if exceeded, _ := l.QuotaExceeded("alice", evening.Add(90*time.Minute)); exceeded {
+		t.Error("quota still exceeded after midnight")
+	}
+}
@@ -0,0 +1,157 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lyleclassen/lite-llm/internal/auth"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	sessionCookie = "lite_llm_session"
+
+	// contextKeyKey holds the *auth.Key a request was authenticated with.
+	contextKeyKey = "api_key"
+)
+
+// SetAuth requires an API key for the API, or a session opened with one
+// for the web pages. Without it the server is open to anyone who can
+// reach it.
+func (s *Server) SetAuth(keys *auth.KeyStore) {
+	s.keys = keys
+	s.sessions = auth.NewSessions(12 * time.Hour)
+}
+
+// authenticate returns the key behind a request's bearer token or session
+// cookie.
+func (s *Server) authenticate(c *gin.Context) (*auth.Key, error) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, _ := strings.Cut(header, " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			return nil, auth.ErrInvalidKey
+		}
+		return s.keys.Authenticate(strings.TrimSpace(token))
+	}
+
+	sid, err := c.Cookie(sessionCookie)
+	if err != nil || sid == "" {
+		return nil, errMissingCredentials
+	}
+	id := s.sessions.KeyID(sid)
+	if id == "" {
+		return nil, errMissingCredentials
+	}
+	// The key may have been revoked or expired since the login.
+	key := s.keys.Lookup(id)
+	if key == nil || key.Status(time.Now()) != "active" {
+		s.sessions.End(sid)
+		return nil, errMissingCredentials
+	}
+	return key, nil
+}
+
+var errMissingCredentials = errors.New("an API key is required")
+
+// requireScope rejects API requests without a key granting scope.
+func (s *Server) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.keys == nil {
+			return
+		}
+		key, err := s.authenticate(c)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="lite-llm"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.Set(contextKeyKey, key)
+		if !key.Allows(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + scope + " scope"})
+			return
+		}
+	}
+}
+
+// requireLogin sends browsers without a session to the login page.
+func (s *Server) requireLogin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.keys == nil {
+			return
+		}
+		if _, err := s.authenticate(c); err != nil {
+			c.Redirect(http.StatusFound, "/login?next="+url.QueryEscape(c.Request.URL.RequestURI()))
+			c.Abort()
+		}
+	}
+}
+
+func (s *Server) handleLoginPage(c *gin.Context) {
+	c.HTML(http.StatusOK, "login.html", gin.H{
+		"title": "Sign in - Lite LLM",
+		"next":  c.Query("next"),
+	})
+}
+
+// handleLogin opens a session for a valid API key entered in the login
+// form.
+func (s *Server) handleLogin(c *gin.Context) {
+	if s.keys == nil {
+		c.Redirect(http.StatusFound, "/")
+		return
+	}
+
+	next := c.PostForm("next")
+	key, err := s.keys.Authenticate(strings.TrimSpace(c.PostForm("key")))
+	if err != nil {
+		logrus.Warnf("Failed login from %s: %v", c.ClientIP(), err)
+		c.HTML(http.StatusUnauthorized, "login.html", gin.H{
+			"title": "Sign in - Lite LLM",
+			"next":  next,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	sid, err := s.sessions.Start(key.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start session"})
+		return
+	}
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(sessionCookie, sid, int(s.sessions.TTL().Seconds()), "/", "", secure, true)
+
+	c.Redirect(http.StatusFound, localRedirect(next))
+}
+
+// localRedirect returns next if it is a path on this server, or "/". A
+// login form must not send anyone off-site.
+func localRedirect(next string) string {
+	// Browsers drop tabs and newlines from URLs, so "/\t/evil.example"
+	// would become "//evil.example".
+	if strings.ContainsFunc(next, unicode.IsControl) {
+		return "/"
+	}
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	if u, err := url.Parse(next); err != nil || u.Scheme != "" || u.Host != "" {
+		return "/"
+	}
+	return next
+}
+
+func (s *Server) handleLogout(c *gin.Context) {
+	if sid, err := c.Cookie(sessionCookie); err == nil && s.sessions != nil {
+		s.sessions.End(sid)
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(sessionCookie, "", -1, "/", "", false, true)
+	c.Redirect(http.StatusFound, "/login")
+}
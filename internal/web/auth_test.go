@@ -0,0 +1,141 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lyleclassen/lite-llm/internal/auth"
+)
+
+// authServer serves a chat and an admin route behind requireScope, and
+// the login form's redirect.
+func authServer(t *testing.T) (*gin.Engine, *auth.KeyStore) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	keys, err := auth.OpenKeyStore(filepath.Join(t.TempDir(), "keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer("http://ollama.invalid")
+	s.SetAuth(keys)
+
+	r := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/api/models", s.requireScope(auth.ScopeChat), ok)
+	r.GET("/api/requests/recent", s.requireScope(auth.ScopeAdmin), ok)
+	r.POST("/login", s.handleLogin)
+	return r, keys
+}
+
+func createKey(t *testing.T, keys *auth.KeyStore, name, scope string) string {
+	t.Helper()
+	_, token, err := keys.Create(name, []string{scope}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRequireScope(t *testing.T) {
+	r, keys := authServer(t)
+	chat := createKey(t, keys, "alice", auth.ScopeChat)
+	admin := createKey(t, keys, "admin", auth.ScopeAdmin)
+	revoked := createKey(t, keys, "bob", auth.ScopeAdmin)
+	if _, err := keys.Revoke("bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		want          int
+	}{
+		{"no key", "/api/models", "", http.StatusUnauthorized},
+		{"not bearer", "/api/models", "Basic " + chat, http.StatusUnauthorized},
+		{"unknown key", "/api/models", "Bearer llk_000000000000_nope", http.StatusUnauthorized},
+		{"revoked key", "/api/requests/recent", "Bearer " + revoked, http.StatusUnauthorized},
+		{"chat key on chat", "/api/models", "Bearer " + chat, http.StatusOK},
+		{"chat key on admin", "/api/requests/recent", "Bearer " + chat, http.StatusForbidden},
+		{"admin key on chat", "/api/models", "bearer " + admin, http.StatusOK},
+		{"admin key on admin", "/api/requests/recent", "Bearer " + admin, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d (%s)", tt.name, w.Code, tt.want, w.Body)
+		}
+		if tt.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: 401 without WWW-Authenticate", tt.name)
+		}
+	}
+}
+
+func TestLoginSession(t *testing.T) {
+	r, keys := authServer(t)
+	token := createKey(t, keys, "alice", auth.ScopeChat)
+
+	form := url.Values{"key": {token}, "next": {"/chat"}}
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/chat" {
+		t.Fatalf("login: %d to %q", w.Code, w.Header().Get("Location"))
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly {
+		t.Fatalf("login cookies = %v", cookies)
+	}
+
+	get := func() int {
+		req := httptest.NewRequest("GET", "/api/models", nil)
+		req.AddCookie(cookies[0])
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := get(); code != http.StatusOK {
+		t.Errorf("with session: %d", code)
+	}
+	// Revoking the key ends its sessions.
+	if _, err := keys.Revoke("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if code := get(); code != http.StatusUnauthorized {
+		t.Errorf("session of a revoked key: %d, want 401", code)
+	}
+}
+
+func TestLocalRedirect(t *testing.T) {
+	tests := map[string]string{
+		"/chat":                       "/chat",
+		"/chat?model=mistral%3A7b":    "/chat?model=mistral%3A7b",
+		"/":                           "/",
+		"":                            "/",
+		"chat":                        "/",
+		"https://evil.example/":       "/",
+		"//evil.example/":             "/",
+		"/\\evil.example/":            "/",
+		"/\t/evil.example/":           "/",
+		"/\n/evil.example/":           "/",
+		"javascript:alert(1)":         "/",
+		" /chat":                      "/",
+		"/login?next=//evil.example/": "/login?next=//evil.example/",
+	}
+	for next, want := range tests {
+		if got := localRedirect(next); got != want {
+			t.Errorf("localRedirect(%q) = %q, want %q", next, got, want)
+		}
+	}
+}
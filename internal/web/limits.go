@@ -0,0 +1,100 @@
+package web
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lyleclassen/lite-llm/internal/auth"
+	"github.com/lyleclassen/lite-llm/internal/usage"
+	"github.com/sirupsen/logrus"
+)
+
+// Token counts reported by Ollama, set by handlers that generate.
+const (
+	contextPromptTokensKey     = "prompt_tokens"
+	contextCompletionTokensKey = "completion_tokens"
+)
+
+// SetLimits enforces per-client request rate, concurrency and daily token
+// limits on generation requests.
+func (s *Server) SetLimits(limiter *usage.Limiter) {
+	s.limiter = limiter
+}
+
+// SetUsageLedger records every model request, with its client, tokens and
+// latency, for `lite-llm usage report` and the daily token quotas.
+func (s *Server) SetUsageLedger(ledger *usage.Ledger) {
+	s.ledger = ledger
+}
+
+// clientName identifies who a request is accounted to: its API key, or its
+// address when authentication is off.
+func clientName(c *gin.Context) string {
+	if key, ok := c.Get(contextKeyKey); ok {
+		return key.(*auth.Key).Name
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// enforceLimits answers 429 with Retry-After when a client is over its
+// daily tokens, its request rate or its concurrent generations.
+func (s *Server) enforceLimits() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.limiter == nil {
+			return
+		}
+		client := clientName(c)
+		now := time.Now()
+
+		if exceeded, retry := s.limiter.QuotaExceeded(client, now); exceeded {
+			tooManyRequests(c, retry, fmt.Sprintf("Daily token quota of %d used up", s.limiter.For(client).DailyTokens))
+			return
+		}
+		// The slot comes first so a request turned away for concurrency
+		// doesn't use up the client's rate as well.
+		release, ok := s.limiter.Acquire(client)
+		if !ok {
+			tooManyRequests(c, time.Second, fmt.Sprintf("Limit of %d concurrent requests reached", s.limiter.For(client).Concurrent))
+			return
+		}
+		defer release()
+		if ok, retry := s.limiter.Allow(client, now); !ok {
+			tooManyRequests(c, retry, fmt.Sprintf("Rate limit of %d requests per minute exceeded", s.limiter.For(client).RequestsPerMinute))
+			return
+		}
+		c.Next()
+	}
+}
+
+func tooManyRequests(c *gin.Context, retry time.Duration, message string) {
+	seconds := int(math.Ceil(retry.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": message, "retry_after": seconds})
+}
+
+// recordUsage adds a finished model request to the usage ledger.
+func (s *Server) recordUsage(c *gin.Context, entry RequestLogEntry) {
+	if s.ledger == nil || entry.Model == "" {
+		return
+	}
+	err := s.ledger.Add(usage.Record{
+		Time:             entry.Time,
+		Client:           clientName(c),
+		Model:            entry.Model,
+		Path:             entry.Path,
+		Status:           entry.Status,
+		PromptTokens:     c.GetInt(contextPromptTokensKey),
+		CompletionTokens: c.GetInt(contextCompletionTokensKey),
+		LatencyMS:        entry.Latency.Milliseconds(),
+	})
+	if err != nil {
+		logrus.Warnf("Failed to record usage: %v", err)
+	}
+}
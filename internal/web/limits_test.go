@@ -0,0 +1,59 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lyleclassen/lite-llm/internal/usage"
+)
+
+func TestEnforceLimitsChecksSlotBeforeRate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := NewServer("http://ollama.invalid")
+	s.SetLimits(usage.NewLimiter(usage.Limits{RequestsPerMinute: 2, Concurrent: 1}, nil, nil))
+
+	release := make(chan struct{})
+	running := make(chan struct{}, 1)
+	r := gin.New()
+	r.POST("/api/chat", s.enforceLimits(), func(c *gin.Context) {
+		if c.Query("hold") != "" {
+			running <- struct{}{}
+			<-release
+		}
+		c.Status(http.StatusOK)
+	})
+	post := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("POST", "/api/chat"+query, nil))
+		return w
+	}
+
+	held := make(chan *httptest.ResponseRecorder)
+	go func() { held <- post("?hold=1") }()
+	<-running
+
+	// Turned away for concurrency without using up the rate.
+	for i := 0; i < 3; i++ {
+		if w := post(""); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+			t.Fatalf("request %d during a generation: %d, Retry-After %q", i, w.Code, w.Header().Get("Retry-After"))
+		}
+	}
+	close(release)
+	if w := <-held; w.Code != http.StatusOK {
+		t.Fatalf("held request: %d", w.Code)
+	}
+
+	if w := post(""); w.Code != http.StatusOK {
+		t.Errorf("second request of the minute: %d, want 200", w.Code)
+	}
+	w := post("")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request of the minute: %d, want 429", w.Code)
+	}
+	if retry, _ := strconv.Atoi(w.Header().Get("Retry-After")); retry < 29 || retry > 30 {
+		t.Errorf("Retry-After = %q, want about 30s", w.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,90 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lyleclassen/lite-llm/internal/auth"
+)
+
+// RequestLogEntry records a single API request handled by the server.
+type RequestLogEntry struct {
+	Time     time.Time     `json:"time"`
+	Method   string        `json:"method"`
+	Path     string        `json:"path"`
+	Status   int           `json:"status"`
+	Latency  time.Duration `json:"latency"`
+	Model    string        `json:"model,omitempty"`
+	ClientIP string        `json:"client_ip"`
+	Key      string        `json:"key,omitempty"`
+}
+
+// RequestLog keeps the most recent API requests in memory.
+type RequestLog struct {
+	mu      sync.Mutex
+	entries []RequestLogEntry
+	size    int
+}
+
+func NewRequestLog(size int) *RequestLog {
+	return &RequestLog{size: size}
+}
+
+func (l *RequestLog) Add(entry RequestLogEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, entry)
+	if len(l.entries) > l.size {
+		l.entries = l.entries[len(l.entries)-l.size:]
+	}
+}
+
+// Recent returns up to n of the latest entries, oldest first.
+func (l *RequestLog) Recent(n int) []RequestLogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if n <= 0 || n > len(l.entries) {
+		n = len(l.entries)
+	}
+	return append([]RequestLogEntry(nil), l.entries[len(l.entries)-n:]...)
+}
+
+// contextModelKey is set by handlers that act on a model so the request
+// log can attribute the request to it.
+const contextModelKey = "model"
+
+func (s *Server) requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		entry := RequestLogEntry{
+			Time:     start,
+			Method:   c.Request.Method,
+			Path:     c.Request.URL.Path,
+			Status:   c.Writer.Status(),
+			Latency:  time.Since(start),
+			Model:    c.GetString(contextModelKey),
+			ClientIP: c.ClientIP(),
+		}
+		if key, ok := c.Get(contextKeyKey); ok {
+			entry.Key = key.(*auth.Key).Name
+		}
+		s.requests.Add(entry)
+
+		if s.lastUsed != nil && entry.Model != "" && entry.Status < http.StatusBadRequest {
+			s.lastUsed.Touch(entry.Model, start)
+		}
+		s.recordUsage(c, entry)
+	}
+}
+
+func (s *Server) handleRecentRequests(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	c.JSON(http.StatusOK, gin.H{"requests": s.requests.Recent(limit)})
+}
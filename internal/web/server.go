@@ -2,17 +2,34 @@ package web
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
+	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lyleclassen/lite-llm/internal/auth"
+	"github.com/lyleclassen/lite-llm/internal/docker"
+	"github.com/lyleclassen/lite-llm/internal/monitor"
 	"github.com/lyleclassen/lite-llm/internal/ollama"
+	"github.com/lyleclassen/lite-llm/internal/usage"
 	"github.com/sirupsen/logrus"
 )
 
 type Server struct {
-	ollama *ollama.Client
+	ollama   *ollama.Client
+	history  *monitor.History
+	requests *RequestLog
+	docker   *docker.Client
+	stack    string
+	lastUsed *usage.LastUsed
+	keys     *auth.KeyStore
+	sessions *auth.Sessions
+	limiter  *usage.Limiter
+	ledger   *usage.Ledger
+	proxies  []string
 }
 
 type ChatMessage struct {
@@ -32,30 +49,86 @@ type ChatResponse struct {
 
 func NewServer(ollamaURL string) *Server {
 	return &Server{
-		ollama: ollama.NewClient(ollamaURL),
+		ollama:   ollama.NewClient(ollamaURL),
+		requests: NewRequestLog(500),
 	}
 }
 
+// SetMetricsHistory attaches the sampled metrics history served by
+// /api/metrics/history.
+func (s *Server) SetMetricsHistory(history *monitor.History) {
+	s.history = history
+}
+
+// SetDocker enables /api/containers, reporting the containers of stack
+// through the Docker daemon.
+func (s *Server) SetDocker(client *docker.Client, stack string) {
+	s.docker = client
+	s.stack = stack
+}
+
+// SetModelUsage records when each model last served a successful request.
+func (s *Server) SetModelUsage(lastUsed *usage.LastUsed) {
+	s.lastUsed = lastUsed
+}
+
+// SetTrustedProxies lists the reverse proxies, as addresses or CIDR
+// ranges, whose X-Forwarded-For gives a client's address. No proxy is
+// trusted by default, so clients can't choose the address their limits
+// are counted against.
+func (s *Server) SetTrustedProxies(proxies []string) error {
+	for _, proxy := range proxies {
+		if net.ParseIP(proxy) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: expected an address or CIDR range", proxy)
+		}
+	}
+	s.proxies = proxies
+	return nil
+}
+
 func (s *Server) SetupRoutes() *gin.Engine {
 	// Set gin to release mode for production
 	gin.SetMode(gin.ReleaseMode)
 	
 	r := gin.Default()
+	// Checked by SetTrustedProxies; gin trusts every proxy unless told.
+	r.SetTrustedProxies(s.proxies)
 
 	// Serve static files
 	r.Static("/static", "./web/static")
 	r.LoadHTMLGlob("web/templates/*")
 
+	// Sign-in for the web pages when API keys are required
+	r.GET("/login", s.handleLoginPage)
+	r.POST("/login", s.handleLogin)
+	r.POST("/logout", s.handleLogout)
+
 	// Web interface routes
-	r.GET("/", s.handleIndex)
-	r.GET("/chat", s.handleChat)
+	pages := r.Group("/", s.requireLogin())
+	{
+		pages.GET("/", s.handleIndex)
+		pages.GET("/chat", s.handleChat)
+	}
 
 	// API routes
 	api := r.Group("/api")
+	api.Use(s.requestLogger())
 	{
-		api.GET("/models", s.handleListModels)
-		api.POST("/chat", s.handleChatAPI)
+		// Left open for container and load balancer health checks
 		api.GET("/health", s.handleHealth)
+
+		chat := api.Group("", s.requireScope(auth.ScopeChat))
+		chat.GET("/models", s.handleListModels)
+		chat.POST("/chat", s.enforceLimits(), s.handleChatAPI)
+		chat.GET("/metrics/history", s.handleMetricsHistory)
+		chat.GET("/containers", s.handleContainers)
+
+		admin := api.Group("", s.requireScope(auth.ScopeAdmin))
+		admin.GET("/requests/recent", s.handleRecentRequests)
+		admin.GET("/logs/:service", s.handleLogs)
 	}
 
 	return r
@@ -64,12 +137,14 @@ func (s *Server) SetupRoutes() *gin.Engine {
 func (s *Server) handleIndex(c *gin.Context) {
 	c.HTML(http.StatusOK, "index.html", gin.H{
 		"title": "Lite LLM",
+		"auth":  s.keys != nil,
 	})
 }
 
 func (s *Server) handleChat(c *gin.Context) {
 	c.HTML(http.StatusOK, "chat.html", gin.H{
 		"title": "Chat - Lite LLM",
+		"auth":  s.keys != nil,
 	})
 }
 
@@ -95,10 +170,8 @@ func (s *Server) handleChatAPI(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No messages provided"})
 		return
 	}
+	c.Set(contextModelKey, req.Model)
 
-	// Get the last message as the prompt
-	lastMessage := req.Messages[len(req.Messages)-1]
-	
 	// Build context from previous messages
 	prompt := ""
 	for _, msg := range req.Messages {
@@ -117,6 +190,9 @@ func (s *Server) handleChatAPI(c *gin.Context) {
 		return
 	}
 
+	c.Set(contextPromptTokensKey, resp.PromptEvalCount)
+	c.Set(contextCompletionTokensKey, resp.EvalCount)
+
 	chatResp := ChatResponse{
 		Message: ChatMessage{
 			Role:    "assistant",
@@ -141,4 +217,138 @@ func (s *Server) handleHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "healthy",
 	})
-}
\ No newline at end of file
+}
+// handleMetricsHistory returns sampled metrics. "since" accepts a duration
+// ("1h") or an RFC 3339 timestamp and defaults to the last hour; "step"
+// is a duration used to downsample by averaging.
+func (s *Server) handleMetricsHistory(c *gin.Context) {
+	if s.history == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Metrics sampling is not enabled"})
+		return
+	}
+
+	since := time.Now().Add(-time.Hour)
+	if v := c.Query("since"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			since = time.Now().Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, v); err == nil {
+			since = t
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since: expected a duration or RFC 3339 timestamp"})
+			return
+		}
+	}
+
+	var step time.Duration
+	if v := c.Query("step"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid step: expected a duration"})
+			return
+		}
+		step = d
+	}
+
+	samples := monitor.Downsample(s.history.Since(since), step)
+	if samples == nil {
+		samples = []monitor.PerformanceMetrics{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":   since,
+		"step":    step.String(),
+		"samples": samples,
+	})
+}
+
+// handleContainers reports the state, health and resource usage of the
+// stack's containers.
+func (s *Server) handleContainers(c *gin.Context) {
+	if s.docker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Container status is not enabled"})
+		return
+	}
+
+	containers, err := s.docker.StackStatus(c.Request.Context(), s.stack)
+	if err != nil {
+		logrus.Errorf("Failed to get container status: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"stack":      s.stack,
+		"containers": containers,
+	})
+}
+
+// handleLogs streams a container's logs as server-sent "log" events,
+// starting with the last "tail" lines (default 100) or those after "since".
+// "grep" filters lines by regular expression. An "end" event is sent when
+// the container exits and "failed" if reading the logs fails.
+func (s *Server) handleLogs(c *gin.Context) {
+	if s.docker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Container logs are not enabled"})
+		return
+	}
+
+	opts := docker.LogsOptions{Follow: true, Tail: 100}
+	if v := c.Query("tail"); v != "" {
+		tail, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tail: expected a number"})
+			return
+		}
+		opts.Tail = tail
+	}
+	if v := c.Query("since"); v != "" {
+		since, err := docker.ParseSince(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		opts.Since = since
+		if c.Query("tail") == "" {
+			opts.Tail = -1
+		}
+	}
+	var grep *regexp.Regexp
+	if v := c.Query("grep"); v != "" {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid grep: " + err.Error()})
+			return
+		}
+		grep = re
+	}
+
+	ctx := c.Request.Context()
+	container, err := s.docker.FindService(ctx, s.stack, c.Param("service"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // keep reverse proxies from buffering
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	err = s.docker.ContainerLogs(ctx, container.ID, opts, func(line docker.LogLine) error {
+		if grep != nil && !grep.MatchString(line.Text) {
+			return nil
+		}
+		c.SSEvent("log", line)
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("Failed to stream logs for %s: %v", container.Name(), err)
+		c.SSEvent("failed", err.Error()) // "error" would clash with EventSource.onerror
+	} else if ctx.Err() == nil {
+		// The container stopped; tell the browser not to reconnect.
+		c.SSEvent("end", container.Name())
+	}
+	c.Writer.Flush()
+}
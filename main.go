@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"os"
 
 	"github.com/lyleclassen/lite-llm/cmd"
@@ -16,6 +17,10 @@ func main() {
 
 	// Execute root command
 	if err := cmd.Execute(); err != nil {
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		logrus.Fatal(err)
 		os.Exit(1)
 	}